@@ -0,0 +1,12 @@
+//go:build !single || applet_cat
+
+package main
+
+import (
+	"github.com/ilnarildarovuch/CoreUtils-On-GO/core/cat"
+	"github.com/ilnarildarovuch/CoreUtils-On-GO/internal/applet"
+)
+
+func init() {
+	applet.Register("cat", cat.Main)
+}
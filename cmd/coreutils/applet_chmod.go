@@ -0,0 +1,12 @@
+//go:build !single || applet_chmod
+
+package main
+
+import (
+	"github.com/ilnarildarovuch/CoreUtils-On-GO/core/chmod"
+	"github.com/ilnarildarovuch/CoreUtils-On-GO/internal/applet"
+)
+
+func init() {
+	applet.Register("chmod", chmod.Main)
+}
@@ -0,0 +1,13 @@
+//go:build !single || applet_chown
+
+package main
+
+import (
+	"github.com/ilnarildarovuch/CoreUtils-On-GO/core/chown"
+	"github.com/ilnarildarovuch/CoreUtils-On-GO/internal/applet"
+)
+
+func init() {
+	applet.Register("chown", chown.Main)
+	applet.Register("chgrp", chown.Main)
+}
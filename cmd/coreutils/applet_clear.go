@@ -0,0 +1,12 @@
+//go:build !single || applet_clear
+
+package main
+
+import (
+	"github.com/ilnarildarovuch/CoreUtils-On-GO/core/clear"
+	"github.com/ilnarildarovuch/CoreUtils-On-GO/internal/applet"
+)
+
+func init() {
+	applet.Register("clear", clear.Main)
+}
@@ -0,0 +1,12 @@
+//go:build !single || applet_cowsay
+
+package main
+
+import (
+	"github.com/ilnarildarovuch/CoreUtils-On-GO/internal/applet"
+	"github.com/ilnarildarovuch/CoreUtils-On-GO/non_core/cowsay"
+)
+
+func init() {
+	applet.Register("cowsay", cowsay.Main)
+}
@@ -0,0 +1,12 @@
+//go:build !single || applet_cp
+
+package main
+
+import (
+	"github.com/ilnarildarovuch/CoreUtils-On-GO/core/cp"
+	"github.com/ilnarildarovuch/CoreUtils-On-GO/internal/applet"
+)
+
+func init() {
+	applet.Register("cp", cp.Main)
+}
@@ -0,0 +1,12 @@
+//go:build !single || applet_crond
+
+package main
+
+import (
+	"github.com/ilnarildarovuch/CoreUtils-On-GO/internal/applet"
+	"github.com/ilnarildarovuch/CoreUtils-On-GO/non_core/crond"
+)
+
+func init() {
+	applet.Register("crond", crond.Main)
+}
@@ -0,0 +1,12 @@
+//go:build !single || applet_dircolors
+
+package main
+
+import (
+	"github.com/ilnarildarovuch/CoreUtils-On-GO/core/dircolors"
+	"github.com/ilnarildarovuch/CoreUtils-On-GO/internal/applet"
+)
+
+func init() {
+	applet.Register("dircolors", dircolors.Main)
+}
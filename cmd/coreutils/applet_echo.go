@@ -0,0 +1,12 @@
+//go:build !single || applet_echo
+
+package main
+
+import (
+	"github.com/ilnarildarovuch/CoreUtils-On-GO/core/echo"
+	"github.com/ilnarildarovuch/CoreUtils-On-GO/internal/applet"
+)
+
+func init() {
+	applet.Register("echo", echo.Main)
+}
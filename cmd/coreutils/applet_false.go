@@ -0,0 +1,12 @@
+//go:build !single || applet_false
+
+package main
+
+import (
+	falsecmd "github.com/ilnarildarovuch/CoreUtils-On-GO/core/false"
+	"github.com/ilnarildarovuch/CoreUtils-On-GO/internal/applet"
+)
+
+func init() {
+	applet.Register("false", falsecmd.Main)
+}
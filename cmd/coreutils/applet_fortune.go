@@ -0,0 +1,12 @@
+//go:build !single || applet_fortune
+
+package main
+
+import (
+	"github.com/ilnarildarovuch/CoreUtils-On-GO/internal/applet"
+	"github.com/ilnarildarovuch/CoreUtils-On-GO/non_core/fortune"
+)
+
+func init() {
+	applet.Register("fortune", fortune.Main)
+}
@@ -0,0 +1,12 @@
+//go:build !single || applet_getty
+
+package main
+
+import (
+	"github.com/ilnarildarovuch/CoreUtils-On-GO/internal/applet"
+	"github.com/ilnarildarovuch/CoreUtils-On-GO/non_core/getty"
+)
+
+func init() {
+	applet.Register("getty", getty.Main)
+}
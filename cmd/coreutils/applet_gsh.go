@@ -0,0 +1,12 @@
+//go:build !single || applet_gsh
+
+package main
+
+import (
+	"github.com/ilnarildarovuch/CoreUtils-On-GO/internal/applet"
+	"github.com/ilnarildarovuch/CoreUtils-On-GO/non_core/gsh"
+)
+
+func init() {
+	applet.Register("gsh", gsh.Main)
+}
@@ -0,0 +1,12 @@
+//go:build !single || applet_init
+
+package main
+
+import (
+	"github.com/ilnarildarovuch/CoreUtils-On-GO/internal/applet"
+	initcmd "github.com/ilnarildarovuch/CoreUtils-On-GO/non_core/init"
+)
+
+func init() {
+	applet.Register("init", initcmd.Main)
+}
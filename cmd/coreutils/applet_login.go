@@ -0,0 +1,12 @@
+//go:build !single || applet_login
+
+package main
+
+import (
+	"github.com/ilnarildarovuch/CoreUtils-On-GO/internal/applet"
+	"github.com/ilnarildarovuch/CoreUtils-On-GO/non_core/login"
+)
+
+func init() {
+	applet.Register("login", login.Main)
+}
@@ -0,0 +1,12 @@
+//go:build !single || applet_ls
+
+package main
+
+import (
+	"github.com/ilnarildarovuch/CoreUtils-On-GO/core/ls"
+	"github.com/ilnarildarovuch/CoreUtils-On-GO/internal/applet"
+)
+
+func init() {
+	applet.Register("ls", ls.Main)
+}
@@ -0,0 +1,12 @@
+//go:build !single || applet_mkdir
+
+package main
+
+import (
+	"github.com/ilnarildarovuch/CoreUtils-On-GO/core/mkdir"
+	"github.com/ilnarildarovuch/CoreUtils-On-GO/internal/applet"
+)
+
+func init() {
+	applet.Register("mkdir", mkdir.Main)
+}
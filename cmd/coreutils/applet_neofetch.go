@@ -0,0 +1,12 @@
+//go:build !single || applet_neofetch
+
+package main
+
+import (
+	"github.com/ilnarildarovuch/CoreUtils-On-GO/internal/applet"
+	"github.com/ilnarildarovuch/CoreUtils-On-GO/non_core/neofetch"
+)
+
+func init() {
+	applet.Register("neofetch", neofetch.Main)
+}
@@ -0,0 +1,12 @@
+//go:build !single || applet_rm
+
+package main
+
+import (
+	"github.com/ilnarildarovuch/CoreUtils-On-GO/core/rm"
+	"github.com/ilnarildarovuch/CoreUtils-On-GO/internal/applet"
+)
+
+func init() {
+	applet.Register("rm", rm.Main)
+}
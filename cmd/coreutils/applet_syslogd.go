@@ -0,0 +1,12 @@
+//go:build !single || applet_syslogd
+
+package main
+
+import (
+	"github.com/ilnarildarovuch/CoreUtils-On-GO/internal/applet"
+	"github.com/ilnarildarovuch/CoreUtils-On-GO/non_core/syslogd"
+)
+
+func init() {
+	applet.Register("syslogd", syslogd.Main)
+}
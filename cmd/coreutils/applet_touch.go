@@ -0,0 +1,12 @@
+//go:build !single || applet_touch
+
+package main
+
+import (
+	"github.com/ilnarildarovuch/CoreUtils-On-GO/core/touch"
+	"github.com/ilnarildarovuch/CoreUtils-On-GO/internal/applet"
+)
+
+func init() {
+	applet.Register("touch", touch.Main)
+}
@@ -0,0 +1,12 @@
+//go:build !single || applet_true
+
+package main
+
+import (
+	truecmd "github.com/ilnarildarovuch/CoreUtils-On-GO/core/true"
+	"github.com/ilnarildarovuch/CoreUtils-On-GO/internal/applet"
+)
+
+func init() {
+	applet.Register("true", truecmd.Main)
+}
@@ -0,0 +1,12 @@
+//go:build !single || applet_uname
+
+package main
+
+import (
+	"github.com/ilnarildarovuch/CoreUtils-On-GO/core/uname"
+	"github.com/ilnarildarovuch/CoreUtils-On-GO/internal/applet"
+)
+
+func init() {
+	applet.Register("uname", uname.Main)
+}
@@ -0,0 +1,12 @@
+//go:build !single || applet_whoami
+
+package main
+
+import (
+	"github.com/ilnarildarovuch/CoreUtils-On-GO/core/whoami"
+	"github.com/ilnarildarovuch/CoreUtils-On-GO/internal/applet"
+)
+
+func init() {
+	applet.Register("whoami", whoami.Main)
+}
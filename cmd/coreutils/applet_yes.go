@@ -0,0 +1,12 @@
+//go:build !single || applet_yes
+
+package main
+
+import (
+	"github.com/ilnarildarovuch/CoreUtils-On-GO/core/yes"
+	"github.com/ilnarildarovuch/CoreUtils-On-GO/internal/applet"
+)
+
+func init() {
+	applet.Register("yes", yes.Main)
+}
@@ -0,0 +1,128 @@
+// Command coreutils is the multi-call binary: one executable holding every
+// utility as an applet, chosen by the name it is run as, busybox style.
+//
+// Each applet is linked in by its applet_*.go file. Building with the tags
+// "single applet_NAME" leaves only that one, for separate binaries.
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ilnarildarovuch/CoreUtils-On-GO/internal/applet"
+	"github.com/ilnarildarovuch/CoreUtils-On-GO/internal/cli"
+)
+
+const version = "1.0.0"
+
+func main() {
+	name := filepath.Base(os.Args[0])
+	if applet.Lookup(name) {
+		applet.Run(name, os.Args[1:])
+	}
+
+	// A binary built with a single applet runs it whatever it is called.
+	if names := applet.Names(); len(names) == 1 {
+		applet.Run(names[0], os.Args[1:])
+	}
+
+	if len(os.Args) < 2 {
+		usage(os.Stderr)
+		os.Exit(cli.ExitFailure)
+	}
+
+	switch arg := os.Args[1]; arg {
+	case "--help":
+		usage(os.Stdout)
+	case "--version":
+		cli.PrintVersion(version)
+	case "--list":
+		for _, name := range applet.Names() {
+			fmt.Println(name)
+		}
+	case "--install":
+		install(os.Args[2:])
+	default:
+		if strings.HasPrefix(arg, "-") {
+			cli.Warnf("unrecognized option '%s'", arg)
+			fmt.Fprintf(os.Stderr, "Try '%s --help' for more information.\n", cli.Name)
+			os.Exit(cli.ExitFailure)
+		}
+		if !applet.Lookup(arg) {
+			cli.Fatalf("%s: applet not found", arg)
+		}
+		applet.Run(arg, os.Args[2:])
+	}
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintf(w, "Usage: %s [APPLET [ARG]...]\n", cli.Name)
+	fmt.Fprintf(w, "  or:  %s --list\n", cli.Name)
+	fmt.Fprintf(w, "  or:  %s --install [-s] [DIR]\n", cli.Name)
+	fmt.Fprintln(w, "\nRun APPLET, or the applet this binary is called as through a link.")
+	fmt.Fprintln(w, "\nOptions:")
+	fmt.Fprintln(w, "      --list                  list the applets")
+	fmt.Fprintln(w, "      --install [-s] [DIR]    link every applet in DIR (/bin by default);")
+	fmt.Fprintln(w, "                              hard links, or symbolic links with -s")
+	fmt.Fprintln(w, "      --help                  display this help and exit")
+	fmt.Fprintln(w, "      --version               output version information and exit")
+	fmt.Fprintln(w, "\nApplets:")
+
+	line := " "
+	for _, name := range applet.Names() {
+		if len(line)+len(name)+2 > 78 {
+			fmt.Fprintln(w, line)
+			line = " "
+		}
+		line += " " + name
+	}
+	fmt.Fprintln(w, line)
+}
+
+// install links every applet name in a directory to this executable.
+// Symbolic links into the executable's own directory are relative, so the
+// tree can be packed into an initramfs as is.
+func install(args []string) {
+	symbolic := false
+	if len(args) > 0 && args[0] == "-s" {
+		symbolic = true
+		args = args[1:]
+	}
+	dir := "/bin"
+	if len(args) > 0 {
+		dir = args[0]
+	}
+	if len(args) > 1 {
+		cli.Fatalf("extra operand '%s'", args[1])
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		cli.Fatalf("cannot find own executable: %v", err)
+	}
+	target := exe
+	if abs, err := filepath.Abs(dir); err == nil && abs == filepath.Dir(exe) {
+		target = filepath.Base(exe)
+	}
+
+	for _, name := range applet.Names() {
+		link := filepath.Join(dir, name)
+		if link == exe {
+			continue
+		}
+		os.Remove(link)
+
+		if symbolic {
+			err = os.Symlink(target, link)
+		} else {
+			err = os.Link(exe, link)
+		}
+		if err != nil {
+			cli.Failf("%s: %v", link, err)
+		}
+	}
+	cli.Exit()
+}
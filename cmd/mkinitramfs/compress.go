@@ -0,0 +1,118 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+)
+
+// Compression formats the kernel can unpack an initramfs from. gzip is
+// done here; xz and zstd run the host's xz and zstd tools.
+var compressors = map[string][]string{
+	"none": nil,
+	"gzip": nil,
+	// The kernel's xz decoder only knows the CRC32 check.
+	"xz":   {"xz", "--check=crc32", "--lzma2=dict=1MiB", "--threads=1", "-9", "-c"},
+	"zstd": {"zstd", "-q", "-19", "-c"},
+}
+
+var decompressors = []struct {
+	magic []byte
+	argv  []string
+}{
+	{[]byte{0x1f, 0x8b}, nil},
+	{[]byte{0xfd, '7', 'z', 'X', 'Z', 0}, []string{"xz", "-dc"}},
+	{[]byte{0x28, 0xb5, 0x2f, 0xfd}, []string{"zstd", "-dc"}},
+}
+
+// compress returns a writer compressing to w in format. Closing it
+// finishes the stream but does not close w.
+func compress(w io.Writer, format string) (io.WriteCloser, error) {
+	argv, ok := compressors[format]
+	switch {
+	case !ok:
+		return nil, fmt.Errorf("unknown compression format '%s'", format)
+	case format == "none":
+		return nopCloser{w}, nil
+	case format == "gzip":
+		// No name or time in the gzip header keeps the output reproducible.
+		return gzip.NewWriterLevel(w, gzip.BestCompression)
+	}
+	return filter(w, argv)
+}
+
+// decompress returns a reader of the archive in r, whatever compression
+// it was written with.
+func decompress(r io.Reader) (io.ReadCloser, error) {
+	br := bufio.NewReader(r)
+	head, _ := br.Peek(6)
+	for _, d := range decompressors {
+		if !bytes.HasPrefix(head, d.magic) {
+			continue
+		}
+		if d.argv == nil {
+			return gzip.NewReader(br)
+		}
+		cmd := exec.Command(d.argv[0], d.argv[1:]...)
+		cmd.Stdin = br
+		cmd.Stderr = os.Stderr
+		out, err := cmd.StdoutPipe()
+		if err != nil {
+			return nil, err
+		}
+		if err := cmd.Start(); err != nil {
+			return nil, err
+		}
+		return &cmdReader{out, cmd}, nil
+	}
+	return io.NopCloser(br), nil
+}
+
+// filter starts argv with its output going to w and returns its input.
+func filter(w io.Writer, argv []string) (io.WriteCloser, error) {
+	cmd := exec.Command(argv[0], argv[1:]...)
+	cmd.Stdout = w
+	cmd.Stderr = os.Stderr
+	in, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	return &cmdWriter{in, cmd}, nil
+}
+
+type cmdWriter struct {
+	io.WriteCloser
+	cmd *exec.Cmd
+}
+
+func (c *cmdWriter) Close() error {
+	err := c.WriteCloser.Close()
+	if werr := c.cmd.Wait(); werr != nil {
+		return fmt.Errorf("%s: %v", c.cmd.Args[0], werr)
+	}
+	return err
+}
+
+type cmdReader struct {
+	io.ReadCloser
+	cmd *exec.Cmd
+}
+
+func (c *cmdReader) Close() error {
+	c.ReadCloser.Close()
+	if err := c.cmd.Wait(); err != nil {
+		return fmt.Errorf("%s: %v", c.cmd.Args[0], err)
+	}
+	return nil
+}
+
+type nopCloser struct{ io.Writer }
+
+func (nopCloser) Close() error { return nil }
@@ -0,0 +1,345 @@
+// Command mkinitramfs builds the initramfs image from the linux/ tree: a
+// newc cpio archive with normalised ownership and timestamps, so the same
+// tree always gives the same image, plus the device nodes and other
+// entries of a manifest. It can also list and verify an image.
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ilnarildarovuch/CoreUtils-On-GO/internal/cli"
+	"github.com/ilnarildarovuch/CoreUtils-On-GO/internal/cpio"
+)
+
+const version = "1.0.0"
+
+var flags = cli.NewFlagSet(version)
+
+var (
+	output    string
+	manifests []string
+	format    string
+	mtime     string
+	owner     int
+	group     int
+	listing   bool
+	verbose   bool
+	verifying bool
+)
+
+func init() {
+	flags.StringVar(&output, 'o', "output", "", "write the archive to `FILE` instead of standard output")
+	flags.Func('m', "manifest", "add the entries listed in `FILE`; may be given more than once", func(v string) error {
+		manifests = append(manifests, v)
+		return nil
+	})
+	flags.StringVar(&format, 'c', "compress", "none", "compress with `FORMAT`: none, gzip, xz or zstd")
+	flags.StringVar(&mtime, 0, "mtime", "", "timestamp every entry with `SECONDS` since the epoch (default $SOURCE_DATE_EPOCH, or 0)")
+	flags.IntVar(&owner, 0, "owner", 0, "owner `UID` of the entries from DIR")
+	flags.IntVar(&group, 0, "group", 0, "group `GID` of the entries from DIR")
+	flags.BoolVar(&listing, 't', "list", "list the members of ARCHIVE")
+	flags.BoolVar(&verbose, 'v', "verbose", "with --list, show mode, owner, size and time too")
+	flags.BoolVar(&verifying, 0, "verify", "check that ARCHIVE is well formed and, given DIR, matches it")
+}
+
+func main() {
+	flags.Usage = usage
+	flags.Parse(os.Args[1:])
+	args := flags.Args()
+
+	opt := options{uid: owner, gid: group}
+	epoch := mtime
+	if epoch == "" {
+		epoch = os.Getenv("SOURCE_DATE_EPOCH")
+	}
+	if epoch != "" {
+		t, err := strconv.ParseInt(epoch, 10, 64)
+		if err != nil || t < 0 {
+			flags.UsageError("invalid timestamp '%s'", epoch)
+		}
+		opt.mtime = t
+	}
+
+	switch {
+	case listing && verifying:
+		flags.UsageError("--list and --verify are mutually exclusive")
+	case listing:
+		if len(args) > 1 {
+			flags.UsageError("extra operand '%s'", args[1])
+		}
+		archive := "-"
+		if len(args) == 1 {
+			archive = args[0]
+		}
+		listArchive(archive)
+	case verifying:
+		if len(args) == 0 {
+			flags.UsageError("missing archive operand")
+		}
+		if len(args) > 2 {
+			flags.UsageError("extra operand '%s'", args[2])
+		}
+		dir := ""
+		if len(args) == 2 {
+			dir = args[1]
+		}
+		verify(args[0], dir, opt)
+	default:
+		if len(args) == 0 && len(manifests) == 0 {
+			flags.UsageError("missing directory operand")
+		}
+		if len(args) > 1 {
+			flags.UsageError("extra operand '%s'", args[1])
+		}
+		dir := ""
+		if len(args) == 1 {
+			dir = args[0]
+		}
+		build(dir, opt)
+	}
+	cli.Exit()
+}
+
+func usage() {
+	w := flags.Output()
+	fmt.Fprintf(w, "Usage: %s [OPTION]... DIR\n", cli.Name)
+	fmt.Fprintf(w, "  or:  %s --list [-v] [ARCHIVE]\n", cli.Name)
+	fmt.Fprintf(w, "  or:  %s --verify [OPTION]... ARCHIVE [DIR]\n", cli.Name)
+	fmt.Fprintln(w, "\nBuild a newc cpio initramfs of DIR and the --manifest entries, list one,")
+	fmt.Fprintln(w, "or verify one. Every entry from DIR is owned by --owner and --group and")
+	fmt.Fprintln(w, "stamped with --mtime; directories and executables get mode 0755, other")
+	fmt.Fprintln(w, "files 0644. Manifest entries override those of DIR and of earlier")
+	fmt.Fprintln(w, "manifests with the same name.")
+	fmt.Fprintln(w, "\nOptions:")
+	flags.PrintDefaults()
+	fmt.Fprintln(w, "\nThe manifest has one entry per line, as for the kernel's gen_init_cpio:")
+	fmt.Fprintln(w, "  dir NAME MODE UID GID")
+	fmt.Fprintln(w, "  file NAME LOCATION MODE UID GID")
+	fmt.Fprintln(w, "  slink NAME TARGET MODE UID GID")
+	fmt.Fprintln(w, "  nod NAME MODE UID GID c|b MAJOR MINOR")
+	fmt.Fprintln(w, "  pipe NAME MODE UID GID")
+	fmt.Fprintln(w, "  sock NAME MODE UID GID")
+}
+
+func build(dir string, opt options) {
+	entries, err := collect(dir, manifests, opt)
+	if err != nil {
+		cli.Fatalf("%v", err)
+	}
+
+	out := os.Stdout
+	if output != "" && output != "-" {
+		if out, err = os.Create(output); err != nil {
+			cli.Fatalf("%v", err)
+		}
+	}
+	fail := func(err error) {
+		if out != os.Stdout {
+			os.Remove(out.Name())
+		}
+		cli.Fatalf("%v", err)
+	}
+
+	zw, err := compress(out, format)
+	if err != nil {
+		fail(err)
+	}
+	if err := write(zw, entries); err != nil {
+		fail(err)
+	}
+	if err := zw.Close(); err != nil {
+		fail(err)
+	}
+	if err := out.Close(); err != nil {
+		fail(err)
+	}
+}
+
+// openArchive opens an archive, "-" meaning standard input, and undoes
+// its compression.
+func openArchive(name string) io.ReadCloser {
+	var f io.Reader = os.Stdin
+	if name != "-" {
+		file, err := os.Open(name)
+		if err != nil {
+			cli.Fatalf("%v", err)
+		}
+		f = file
+	}
+	r, err := decompress(f)
+	if err != nil {
+		cli.Fatalf("%s: %v", name, err)
+	}
+	return r
+}
+
+func listArchive(name string) {
+	r := openArchive(name)
+	defer r.Close()
+
+	cr := cpio.NewReader(r)
+	for {
+		h, err := cr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			cli.Fatalf("%s: %v", name, err)
+		}
+		if !verbose {
+			fmt.Println(h.Name)
+			continue
+		}
+
+		size := strconv.FormatInt(h.Size, 10)
+		if t := h.Type(); t == cpio.TypeChar || t == cpio.TypeBlock {
+			size = fmt.Sprintf("%d, %d", h.RDevMajor, h.RDevMinor)
+		}
+		stamp := time.Unix(h.Mtime, 0).UTC().Format("2006-01-02 15:04")
+		line := fmt.Sprintf("%s %3d %-5d %-5d %9s %s %s", modeString(h.Mode), h.Nlink, h.Uid, h.Gid, size, stamp, h.Name)
+		if h.Type() == cpio.TypeSymlink {
+			line += " -> " + h.Linkname
+		}
+		fmt.Println(line)
+	}
+}
+
+// verify checks that every member of an archive is readable, uniquely and
+// sensibly named and inside a directory created before it. Given a tree or
+// a manifest, it also checks that the archive holds exactly what would be
+// built from them, data included.
+func verify(name, dir string, opt options) {
+	var entries []*entry
+	var expect map[string]*entry
+	if dir != "" || len(manifests) > 0 {
+		var err error
+		if entries, err = collect(dir, manifests, opt); err != nil {
+			cli.Fatalf("%v", err)
+		}
+		expect = make(map[string]*entry, len(entries))
+		for _, e := range entries {
+			expect[e.hdr.Name] = e
+		}
+	}
+
+	r := openArchive(name)
+	defer r.Close()
+
+	cr := cpio.NewReader(r)
+	dirs := map[string]bool{".": true}
+	seen := make(map[string]bool)
+	for {
+		h, err := cr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			cli.Failf("%s: %v", name, err)
+			break
+		}
+		data, err := io.ReadAll(cr)
+		if err != nil {
+			cli.Failf("%s: %s: %v", name, h.Name, err)
+			break
+		}
+
+		member := path.Clean(h.Name)
+		switch {
+		case path.IsAbs(h.Name) || member == ".." || strings.HasPrefix(member, "../"):
+			cli.Failf("%s: bad member name", h.Name)
+			continue
+		case seen[member]:
+			cli.Failf("%s: duplicate member", h.Name)
+			continue
+		case !dirs[path.Dir(member)]:
+			cli.Failf("%s: directory %s not created before it", h.Name, path.Dir(member))
+		}
+		seen[member] = true
+		if h.Type() == cpio.TypeDir {
+			dirs[member] = true
+		}
+
+		if expect == nil || member == "." {
+			continue
+		}
+		e, ok := expect[member]
+		if !ok {
+			cli.Failf("%s: not in the tree", h.Name)
+			continue
+		}
+		if diff := compare(h, &e.hdr); diff != "" {
+			cli.Failf("%s: %s differs", h.Name, diff)
+			continue
+		}
+		if e.src != "" {
+			want, err := os.ReadFile(e.src)
+			if err != nil {
+				cli.Failf("%v", err)
+			} else if !bytes.Equal(data, want) {
+				cli.Failf("%s: contents differ from %s", h.Name, e.src)
+			}
+		}
+	}
+
+	for _, e := range entries {
+		if !seen[e.hdr.Name] {
+			cli.Failf("%s: missing from the archive", e.hdr.Name)
+		}
+	}
+}
+
+// compare names the first field of got that differs from want, or returns
+// "" if they agree.
+func compare(got, want *cpio.Header) string {
+	switch {
+	case got.Mode != want.Mode:
+		return "mode"
+	case got.Uid != want.Uid || got.Gid != want.Gid:
+		return "ownership"
+	case got.Mtime != want.Mtime:
+		return "timestamp"
+	case got.RDevMajor != want.RDevMajor || got.RDevMinor != want.RDevMinor:
+		return "device number"
+	case got.Linkname != want.Linkname:
+		return "link target"
+	}
+	return ""
+}
+
+func modeString(mode uint32) string {
+	types := map[uint32]byte{
+		cpio.TypeSocket: 's', cpio.TypeSymlink: 'l', cpio.TypeReg: '-', cpio.TypeBlock: 'b',
+		cpio.TypeDir: 'd', cpio.TypeChar: 'c', cpio.TypeFIFO: 'p',
+	}
+	b := []byte("?rwxrwxrwx")
+	if t, ok := types[mode&cpio.TypeMask]; ok {
+		b[0] = t
+	}
+	for i := 0; i < 9; i++ {
+		if mode&(1<<(8-i)) == 0 {
+			b[i+1] = '-'
+		}
+	}
+	special := []struct {
+		bit uint32
+		pos int
+		set byte
+	}{{04000, 3, 's'}, {02000, 6, 's'}, {01000, 9, 't'}}
+	for _, s := range special {
+		if mode&s.bit != 0 {
+			if b[s.pos] == '-' {
+				b[s.pos] = s.set - 'a' + 'A'
+			} else {
+				b[s.pos] = s.set
+			}
+		}
+	}
+	return string(b)
+}
@@ -0,0 +1,277 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/ilnarildarovuch/CoreUtils-On-GO/internal/cpio"
+)
+
+// entry is one member of the archive to build: its header and, for a
+// regular file, where the data comes from.
+type entry struct {
+	hdr cpio.Header
+	src string
+}
+
+// options are the metadata every entry from the tree is normalised to.
+type options struct {
+	uid, gid int
+	mtime    int64
+}
+
+// collect gathers the entries for dir plus those of the manifests, each
+// taking precedence over the tree and earlier manifests for its names.
+// Missing parent directories are added, and the result is sorted by name
+// so every directory precedes its contents.
+func collect(dir string, manifests []string, opt options) ([]*entry, error) {
+	entries := make(map[string]*entry)
+	if dir != "" {
+		if err := walk(dir, opt, entries); err != nil {
+			return nil, err
+		}
+	}
+	for _, manifest := range manifests {
+		if err := readManifest(manifest, opt, entries); err != nil {
+			return nil, err
+		}
+	}
+
+	for name := range entries {
+		for p := path.Dir(name); p != "."; p = path.Dir(p) {
+			if _, ok := entries[p]; !ok {
+				entries[p] = &entry{hdr: cpio.Header{Name: p, Mode: cpio.TypeDir | 0755, Uid: opt.uid, Gid: opt.gid, Mtime: opt.mtime}}
+			}
+		}
+	}
+
+	list := make([]*entry, 0, len(entries))
+	for _, e := range entries {
+		list = append(list, e)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].hdr.Name < list[j].hdr.Name })
+	return list, nil
+}
+
+// walk adds the contents of dir. Permissions are reduced to what survives
+// a git checkout: 0755 for directories and executables, 0644 otherwise.
+func walk(dir string, opt options, entries map[string]*entry) error {
+	return filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil || rel == "." {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		e := &entry{hdr: cpio.Header{Name: filepath.ToSlash(rel), Uid: opt.uid, Gid: opt.gid, Mtime: opt.mtime}}
+		switch mode := info.Mode(); {
+		case mode.IsDir():
+			e.hdr.Mode = cpio.TypeDir | 0755
+		case mode.IsRegular():
+			e.hdr.Mode = cpio.TypeReg | 0644
+			if mode&0111 != 0 {
+				e.hdr.Mode = cpio.TypeReg | 0755
+			}
+			e.hdr.Size = info.Size()
+			e.src = p
+		case mode&fs.ModeSymlink != 0:
+			target, err := os.Readlink(p)
+			if err != nil {
+				return err
+			}
+			e.hdr.Mode = cpio.TypeSymlink | 0777
+			e.hdr.Linkname = target
+		case mode&fs.ModeDevice != 0:
+			st, ok := info.Sys().(*syscall.Stat_t)
+			if !ok {
+				return fmt.Errorf("%s: cannot read device number", p)
+			}
+			e.hdr.Mode = cpio.TypeBlock | uint32(mode.Perm())
+			if mode&fs.ModeCharDevice != 0 {
+				e.hdr.Mode = cpio.TypeChar | uint32(mode.Perm())
+			}
+			e.hdr.RDevMajor, e.hdr.RDevMinor = major(st.Rdev), minor(st.Rdev)
+		case mode&fs.ModeNamedPipe != 0:
+			e.hdr.Mode = cpio.TypeFIFO | uint32(mode.Perm())
+		default:
+			return fmt.Errorf("%s: unsupported file type", p)
+		}
+		entries[e.hdr.Name] = e
+		return nil
+	})
+}
+
+// readManifest adds the entries of a gen_init_cpio style list:
+//
+//	dir   NAME MODE UID GID
+//	file  NAME LOCATION MODE UID GID
+//	slink NAME TARGET MODE UID GID
+//	nod   NAME MODE UID GID c|b MAJOR MINOR
+//	pipe  NAME MODE UID GID
+//	sock  NAME MODE UID GID
+//
+// NAME is the path inside the archive; a relative LOCATION is taken from
+// the manifest's directory. Blank lines and lines starting with # are
+// ignored.
+func readManifest(name string, opt options, entries map[string]*entry) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return parseManifest(f, name, filepath.Dir(name), opt, entries)
+}
+
+var manifestFields = map[string]int{"dir": 5, "file": 6, "slink": 6, "nod": 8, "pipe": 5, "sock": 5}
+
+func parseManifest(r io.Reader, name, base string, opt options, entries map[string]*entry) error {
+	sc := bufio.NewScanner(r)
+	for lineno := 1; sc.Scan(); lineno++ {
+		fields := strings.Fields(sc.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		e, err := parseManifestLine(fields, base, opt)
+		if err != nil {
+			return fmt.Errorf("%s:%d: %v", name, lineno, err)
+		}
+		entries[e.hdr.Name] = e
+	}
+	return sc.Err()
+}
+
+func parseManifestLine(fields []string, base string, opt options) (*entry, error) {
+	n, ok := manifestFields[fields[0]]
+	if !ok {
+		return nil, fmt.Errorf("unknown type '%s'", fields[0])
+	}
+	if len(fields) != n {
+		return nil, fmt.Errorf("%s needs %d fields, got %d", fields[0], n-1, len(fields)-1)
+	}
+
+	name := strings.Trim(path.Clean("/"+fields[1]), "/")
+	if name == "" {
+		return nil, fmt.Errorf("bad name '%s'", fields[1])
+	}
+	e := &entry{hdr: cpio.Header{Name: name, Mtime: opt.mtime}}
+
+	// The mode, uid and gid follow the name, or the name and one more
+	// field for file and slink.
+	attrs := fields[2:5]
+	if fields[0] == "file" || fields[0] == "slink" {
+		attrs = fields[3:6]
+	}
+	perm, err := strconv.ParseUint(attrs[0], 8, 32)
+	if err != nil || perm > 07777 {
+		return nil, fmt.Errorf("bad mode '%s'", attrs[0])
+	}
+	if e.hdr.Uid, err = strconv.Atoi(attrs[1]); err != nil {
+		return nil, fmt.Errorf("bad uid '%s'", attrs[1])
+	}
+	if e.hdr.Gid, err = strconv.Atoi(attrs[2]); err != nil {
+		return nil, fmt.Errorf("bad gid '%s'", attrs[2])
+	}
+	e.hdr.Mode = uint32(perm)
+
+	switch fields[0] {
+	case "dir":
+		e.hdr.Mode |= cpio.TypeDir
+	case "file":
+		e.src = fields[2]
+		if !filepath.IsAbs(e.src) {
+			e.src = filepath.Join(base, e.src)
+		}
+		info, err := os.Stat(e.src)
+		if err != nil {
+			return nil, err
+		}
+		if !info.Mode().IsRegular() {
+			return nil, fmt.Errorf("%s: not a regular file", e.src)
+		}
+		e.hdr.Mode |= cpio.TypeReg
+		e.hdr.Size = info.Size()
+	case "slink":
+		e.hdr.Mode |= cpio.TypeSymlink
+		e.hdr.Linkname = fields[2]
+	case "nod":
+		switch fields[5] {
+		case "c":
+			e.hdr.Mode |= cpio.TypeChar
+		case "b":
+			e.hdr.Mode |= cpio.TypeBlock
+		default:
+			return nil, fmt.Errorf("bad device type '%s'", fields[5])
+		}
+		maj, err1 := strconv.ParseUint(fields[6], 10, 32)
+		min, err2 := strconv.ParseUint(fields[7], 10, 32)
+		if err1 != nil || err2 != nil {
+			return nil, fmt.Errorf("bad device number '%s %s'", fields[6], fields[7])
+		}
+		e.hdr.RDevMajor, e.hdr.RDevMinor = uint32(maj), uint32(min)
+	case "pipe":
+		e.hdr.Mode |= cpio.TypeFIFO
+	case "sock":
+		e.hdr.Mode |= cpio.TypeSocket
+	}
+	return e, nil
+}
+
+// write writes the archive of entries to w.
+func write(w io.Writer, entries []*entry) error {
+	cw := cpio.NewWriter(w)
+	for _, e := range entries {
+		if err := writeEntry(cw, e); err != nil {
+			return err
+		}
+	}
+	return cw.Close()
+}
+
+func writeEntry(cw *cpio.Writer, e *entry) error {
+	if e.src == "" {
+		return cw.WriteHeader(&e.hdr)
+	}
+
+	f, err := os.Open(e.src)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	// The size is taken now, not when the tree was walked.
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	hdr := e.hdr
+	hdr.Size = info.Size()
+	if err := cw.WriteHeader(&hdr); err != nil {
+		return err
+	}
+	if _, err := io.CopyN(cw, f, hdr.Size); err != nil {
+		return fmt.Errorf("%s: %v", e.src, err)
+	}
+	return nil
+}
+
+func major(dev uint64) uint32 {
+	return uint32((dev>>8)&0xfff | (dev>>32)&^0xfff)
+}
+
+func minor(dev uint64) uint32 {
+	return uint32(dev&0xff | (dev>>12)&^0xff)
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ilnarildarovuch/CoreUtils-On-GO/internal/cpio"
+)
+
+func TestParseManifest(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "motd"), []byte("hi\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	manifest := `# comment
+dir /dev 0755 0 0
+nod /dev/console 0600 0 5 c 5 1
+nod dev/sda 0660 0 6 b 8 0
+slink /bin/sh gsh 0777 0 0
+file /etc/motd motd 0644 0 0
+pipe /run/fifo 0600 0 0
+`
+	entries := make(map[string]*entry)
+	if err := parseManifest(strings.NewReader(manifest), "list", dir, options{mtime: 7}, entries); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]cpio.Header{
+		"dev":         {Mode: cpio.TypeDir | 0755},
+		"dev/console": {Mode: cpio.TypeChar | 0600, Gid: 5, RDevMajor: 5, RDevMinor: 1},
+		"dev/sda":     {Mode: cpio.TypeBlock | 0660, Gid: 6, RDevMajor: 8},
+		"bin/sh":      {Mode: cpio.TypeSymlink | 0777, Linkname: "gsh"},
+		"etc/motd":    {Mode: cpio.TypeReg | 0644, Size: 3},
+		"run/fifo":    {Mode: cpio.TypeFIFO | 0600},
+	}
+	if len(entries) != len(want) {
+		t.Errorf("got %d entries, want %d", len(entries), len(want))
+	}
+	for name, w := range want {
+		e, ok := entries[name]
+		if !ok {
+			t.Errorf("%s: missing", name)
+			continue
+		}
+		h := e.hdr
+		if h.Mode != w.Mode || h.Gid != w.Gid || h.Size != w.Size || h.Linkname != w.Linkname ||
+			h.RDevMajor != w.RDevMajor || h.RDevMinor != w.RDevMinor || h.Mtime != 7 {
+			t.Errorf("%s: got %+v", name, h)
+		}
+	}
+	if src := entries["etc/motd"].src; src != filepath.Join(dir, "motd") {
+		t.Errorf("etc/motd: source %q", src)
+	}
+
+	for _, bad := range []string{"dir /x 0755 0", "nod /x 0600 0 0 x 1 1", "dir /x 999 0 0", "link /x y 0 0 0", "dir / 0755 0 0"} {
+		if err := parseManifest(strings.NewReader(bad), "list", dir, options{}, make(map[string]*entry)); err == nil {
+			t.Errorf("%q accepted", bad)
+		}
+	}
+}
+
+func TestCollectManifests(t *testing.T) {
+	tree, dir := t.TempDir(), t.TempDir()
+	os.Mkdir(filepath.Join(tree, "bin"), 0700)
+	if err := os.WriteFile(filepath.Join(tree, "bin/sh"), []byte("tree\n"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	put := func(name, data string) string {
+		p := filepath.Join(dir, name)
+		if err := os.WriteFile(p, []byte(data), 0600); err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+	put("sh", "#!/bin/gsh\n")
+	first := put("first.list", "dir /etc 0700 0 0\nslink /bin/sh gsh 0777 0 0\n")
+	second := put("second.list", "file /bin/sh sh 0755 0 0\n")
+
+	// Both manifests replace the tree's bin/sh, and the later one wins.
+	entries, err := collect(tree, []string{first, second}, options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[string]*entry)
+	for _, e := range entries {
+		got[e.hdr.Name] = e
+	}
+	if e := got["bin/sh"]; e == nil || e.hdr.Mode != cpio.TypeReg|0755 || e.src != filepath.Join(dir, "sh") {
+		t.Errorf("bin/sh: got %+v", e)
+	}
+	if e := got["etc"]; e == nil || e.hdr.Mode != cpio.TypeDir|0700 {
+		t.Errorf("etc: got %+v", e)
+	}
+}
+
+func TestReproducible(t *testing.T) {
+	dir := t.TempDir()
+	for name, data := range map[string]string{"bin/tool": "#!/bin/sh\n", "etc/conf": "x=1\n"} {
+		p := filepath.Join(dir, name)
+		os.MkdirAll(filepath.Dir(p), 0700)
+		if err := os.WriteFile(p, []byte(data), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	os.Chmod(filepath.Join(dir, "bin/tool"), 0700)
+
+	archive := func() []byte {
+		entries, err := collect(dir, nil, options{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		if err := write(&buf, entries); err != nil {
+			t.Fatal(err)
+		}
+		return buf.Bytes()
+	}
+	first := archive()
+
+	// Other timestamps and permissions in the tree give the same archive.
+	os.Chtimes(filepath.Join(dir, "etc/conf"), time.Unix(12345, 0), time.Unix(12345, 0))
+	os.Chmod(filepath.Join(dir, "bin/tool"), 0750)
+	os.Chmod(filepath.Join(dir, "etc"), 0711)
+	if !bytes.Equal(first, archive()) {
+		t.Error("archive changed with tree metadata")
+	}
+
+	r := cpio.NewReader(bytes.NewReader(first))
+	var got []string
+	for {
+		h, err := r.Next()
+		if err != nil {
+			break
+		}
+		got = append(got, h.Name+" "+modeString(h.Mode))
+	}
+	want := "bin drwxr-xr-x,bin/tool -rwxr-xr-x,etc drwxr-xr-x,etc/conf -rw-r--r--"
+	if strings.Join(got, ",") != want {
+		t.Errorf("members %q, want %q", strings.Join(got, ","), want)
+	}
+}
@@ -0,0 +1,147 @@
+// Command webterm serves a terminal to the browser: it boots the system in
+// QEMU with its serial console on a pseudo-terminal, or runs any other
+// command there, and streams the raw bytes over a WebSocket to an
+// embedded xterm-compatible page. Every browser that opens the page sees
+// and types into the same session, and the session so far can be
+// downloaded as an asciicast recording.
+package main
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/ilnarildarovuch/CoreUtils-On-GO/internal/cli"
+	"github.com/ilnarildarovuch/CoreUtils-On-GO/internal/websocket"
+)
+
+const version = "1.0.0"
+
+//go:embed static
+var static embed.FS
+
+var flags = cli.NewFlagSet(version)
+
+var (
+	listen  string
+	qemu    string
+	kernel  string
+	initrd  string
+	memory  string
+	cmdline string
+	record  string
+	cols    int
+	rows    int
+)
+
+func init() {
+	flags.StringVar(&listen, 'l', "listen", "127.0.0.1:8080", "serve on `ADDRESS`")
+	flags.StringVar(&qemu, 0, "qemu", "qemu-system-x86_64", "run the QEMU binary `PATH`")
+	flags.StringVar(&kernel, 0, "kernel", "bootable/boot/vmlinuz-1", "boot the kernel `FILE`")
+	flags.StringVar(&initrd, 0, "initrd", "initrd-1.0.img", "load the initramfs `FILE`")
+	flags.StringVar(&memory, 'm', "memory", "2048", "give the guest `SIZE` of memory, in MiB unless suffixed")
+	flags.StringVar(&cmdline, 0, "append", "console=ttyS0", "pass `CMDLINE` to the kernel")
+	flags.StringVar(&record, 'r', "record", "", "keep the session recording in `FILE` (default a temporary file, removed on exit)")
+	flags.IntVar(&cols, 0, "cols", 80, "start the terminal `N` columns wide")
+	flags.IntVar(&rows, 0, "rows", 24, "start the terminal `N` rows high")
+}
+
+func main() {
+	flags.Usage = usage
+	flags.Parse(os.Args[1:])
+	if cols < 1 || rows < 1 {
+		flags.UsageError("invalid terminal size %dx%d", cols, rows)
+	}
+
+	argv := flags.Args()
+	if len(argv) == 0 {
+		// The serial console on standard I/O without the QEMU monitor, so
+		// Ctrl-A and Ctrl-C reach the guest.
+		argv = []string{qemu,
+			"-kernel", kernel, "-initrd", initrd, "-m", memory, "-append", cmdline,
+			"-display", "none", "-monitor", "none",
+			"-chardev", "stdio,id=console,signal=off", "-serial", "chardev:console"}
+	}
+
+	var f *os.File
+	var err error
+	if record != "" {
+		f, err = os.Create(record)
+	} else {
+		f, err = os.CreateTemp("", "webterm-*.cast")
+	}
+	if err != nil {
+		cli.Fatalf("%v", err)
+	}
+	cleanup := func() {
+		f.Close()
+		if record == "" {
+			os.Remove(f.Name())
+		}
+	}
+	rec, err := newRecorder(f, cols, rows)
+	if err != nil {
+		cleanup()
+		cli.Fatalf("%s: %v", f.Name(), err)
+	}
+
+	s, err := startSession(argv, cols, rows, rec)
+	if err != nil {
+		cleanup()
+		cli.Fatalf("%s: %v", argv[0], err)
+	}
+
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-sig
+		s.stop()
+		cleanup()
+		os.Exit(cli.ExitFailure)
+	}()
+
+	page, _ := fs.Sub(static, "static")
+	mux := http.NewServeMux()
+	mux.Handle("/", http.FileServer(http.FS(page)))
+	mux.HandleFunc("/ws", func(w http.ResponseWriter, r *http.Request) {
+		conn, err := websocket.Upgrade(w, r)
+		if err != nil {
+			log.Printf("%s: %v", r.RemoteAddr, err)
+			return
+		}
+		s.serve(conn)
+	})
+	mux.HandleFunc("/recording.cast", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-asciicast")
+		w.Header().Set("Content-Disposition", `attachment; filename="session.cast"`)
+		rec.WriteTo(w)
+	})
+
+	log.SetPrefix(cli.Name + ": ")
+	log.SetFlags(0)
+	log.Printf("serving %s on http://%s/", argv[0], listen)
+	err = http.ListenAndServe(listen, mux)
+	s.stop()
+	cleanup()
+	cli.Fatalf("%v", err)
+}
+
+func usage() {
+	w := flags.Output()
+	fmt.Fprintf(w, "Usage: %s [OPTION]... [-- COMMAND [ARG]...]\n", cli.Name)
+	fmt.Fprintln(w, "\nServe a terminal running QEMU's serial console, or COMMAND, to web browsers.")
+	fmt.Fprintln(w, "Open http://ADDRESS/ to see it; every browser shares the one session, and")
+	fmt.Fprintln(w, "http://ADDRESS/recording.cast downloads it as an asciicast v2 recording.")
+	fmt.Fprintln(w, "\nOptions:")
+	flags.PrintDefaults()
+	fmt.Fprintln(w, "\nThe default --listen address only accepts local browsers; anyone who can")
+	fmt.Fprintln(w, "reach the address can type into the session.")
+	fmt.Fprintf(w, "\nDefault QEMU command line: %s -kernel FILE -initrd FILE -m SIZE -append\n", qemu)
+	fmt.Fprintln(w, "  CMDLINE -display none -monitor none -chardev stdio,id=console,signal=off")
+	fmt.Fprintln(w, "  -serial chardev:console")
+}
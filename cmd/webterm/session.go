@@ -0,0 +1,294 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"sync"
+	"syscall"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ilnarildarovuch/CoreUtils-On-GO/internal/websocket"
+)
+
+// backlogSize is how much recent output a viewer joining late is sent, so
+// it starts from a filled screen.
+const backlogSize = 256 << 10
+
+// viewerQueue is how many output chunks may wait for a slow viewer before
+// it is dropped.
+const viewerQueue = 256
+
+// session is one command running on a pseudo-terminal, shared by every
+// viewer connected to the server.
+type session struct {
+	pty *os.File
+	cmd *exec.Cmd
+	rec *recorder
+
+	mu      sync.Mutex
+	viewers map[*viewer]bool
+	backlog []byte
+	cols    int
+	rows    int
+	exited  string // how the command ended, once it has
+}
+
+type viewer struct {
+	conn *websocket.Conn
+	out  chan message
+}
+
+type message struct {
+	typ  int
+	data []byte
+}
+
+// control messages travel as JSON in text frames: "resize" from a viewer,
+// "viewers" and "exit" from the server.
+type control struct {
+	Type   string `json:"type"`
+	Cols   int    `json:"cols,omitempty"`
+	Rows   int    `json:"rows,omitempty"`
+	Count  int    `json:"count,omitempty"`
+	Status string `json:"status,omitempty"`
+}
+
+// startSession starts argv on a new pseudo-terminal of cols by rows.
+func startSession(argv []string, cols, rows int, rec *recorder) (*session, error) {
+	master, slave, err := openPTY()
+	if err != nil {
+		return nil, err
+	}
+	defer slave.Close()
+	if err := setWinsize(master, cols, rows); err != nil {
+		master.Close()
+		return nil, err
+	}
+
+	cmd := exec.Command(argv[0], argv[1:]...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = slave, slave, slave
+	cmd.Env = append(os.Environ(), "TERM=xterm-256color")
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true, Setctty: true}
+	if err := cmd.Start(); err != nil {
+		master.Close()
+		return nil, err
+	}
+
+	s := &session{pty: master, cmd: cmd, rec: rec, viewers: make(map[*viewer]bool), cols: cols, rows: rows}
+	go s.pump()
+	return s, nil
+}
+
+// pump copies the terminal's output to the viewers until the command
+// exits.
+func (s *session) pump() {
+	buf := make([]byte, 32<<10)
+	for {
+		n, err := s.pty.Read(buf)
+		if n > 0 {
+			s.output(append([]byte(nil), buf[:n]...))
+		}
+		if err != nil {
+			// EIO once the last process on the slave end has gone.
+			break
+		}
+	}
+
+	status := "exited"
+	if err := s.cmd.Wait(); err != nil {
+		status = err.Error()
+	}
+	log.Printf("%s: %s", s.cmd.Args[0], status)
+	s.output([]byte(fmt.Sprintf("\r\n[%s %s]\r\n", s.cmd.Args[0], status)))
+
+	s.mu.Lock()
+	s.exited = status
+	s.mu.Unlock()
+	s.broadcast(s.controlMessage(control{Type: "exit", Status: status}))
+}
+
+func (s *session) output(data []byte) {
+	s.mu.Lock()
+	s.backlog = append(s.backlog, data...)
+	if len(s.backlog) > backlogSize {
+		s.backlog = append([]byte(nil), s.backlog[len(s.backlog)-backlogSize:]...)
+	}
+	s.mu.Unlock()
+
+	s.rec.output(data)
+	s.broadcast(message{websocket.BinaryMessage, data})
+}
+
+// broadcast queues m for every viewer, dropping those that fall too far
+// behind.
+func (s *session) broadcast(m message) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for v := range s.viewers {
+		select {
+		case v.out <- m:
+		default:
+			log.Printf("dropping a viewer that does not keep up")
+			s.drop(v)
+		}
+	}
+}
+
+func (s *session) controlMessage(c control) message {
+	data, _ := json.Marshal(c)
+	return message{websocket.TextMessage, data}
+}
+
+// serve runs a viewer until its connection ends: output goes out, and
+// keystrokes and resizes come in.
+func (s *session) serve(conn *websocket.Conn) {
+	v := &viewer{conn: conn, out: make(chan message, viewerQueue)}
+
+	s.mu.Lock()
+	v.out <- message{websocket.BinaryMessage, append([]byte(nil), s.backlog...)}
+	if s.exited != "" {
+		v.out <- s.controlMessage(control{Type: "exit", Status: s.exited})
+	}
+	s.viewers[v] = true
+	count := len(s.viewers)
+	s.mu.Unlock()
+	s.broadcast(s.controlMessage(control{Type: "viewers", Count: count}))
+
+	go func() {
+		for m := range v.out {
+			if err := conn.WriteMessage(m.typ, m.data); err != nil {
+				break
+			}
+		}
+		conn.Close()
+	}()
+
+	for {
+		typ, data, err := conn.ReadMessage()
+		if err != nil {
+			break
+		}
+		if typ == websocket.BinaryMessage {
+			s.pty.Write(data)
+			continue
+		}
+
+		var c control
+		if json.Unmarshal(data, &c) == nil && c.Type == "resize" {
+			s.resize(c.Cols, c.Rows)
+		}
+	}
+
+	s.mu.Lock()
+	s.drop(v)
+	count = len(s.viewers)
+	s.mu.Unlock()
+	s.broadcast(s.controlMessage(control{Type: "viewers", Count: count}))
+}
+
+// drop forgets a viewer; s.mu is held.
+func (s *session) drop(v *viewer) {
+	if s.viewers[v] {
+		delete(s.viewers, v)
+		close(v.out)
+	}
+}
+
+// resize follows the viewer that resized last. Over QEMU's serial console
+// the guest does not see the new size, but anything run directly on the
+// terminal does.
+func (s *session) resize(cols, rows int) {
+	if cols < 1 || rows < 1 || cols > 1000 || rows > 1000 {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if cols == s.cols && rows == s.rows {
+		return
+	}
+	s.cols, s.rows = cols, rows
+	setWinsize(s.pty, cols, rows)
+	s.rec.resize(cols, rows)
+}
+
+// stop ends the command.
+func (s *session) stop() {
+	s.cmd.Process.Signal(syscall.SIGTERM)
+}
+
+// recorder writes the session as an asciicast v2 file, the format of
+// asciinema: a JSON header line, then one [time, type, data] line per
+// event.
+type recorder struct {
+	mu    sync.Mutex
+	f     *os.File
+	start time.Time
+	// partial holds the start of a UTF-8 sequence split between reads.
+	partial []byte
+}
+
+func newRecorder(f *os.File, cols, rows int) (*recorder, error) {
+	r := &recorder{f: f, start: time.Now()}
+	header, _ := json.Marshal(map[string]interface{}{
+		"version":   2,
+		"width":     cols,
+		"height":    rows,
+		"timestamp": r.start.Unix(),
+		"env":       map[string]string{"TERM": "xterm-256color"},
+	})
+	if _, err := fmt.Fprintf(f, "%s\n", header); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *recorder) output(data []byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	data = append(r.partial, data...)
+	end := len(data)
+	for i := len(data) - 1; i >= 0 && i >= len(data)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(data[i]) {
+			if !utf8.FullRune(data[i:]) {
+				end = i
+			}
+			break
+		}
+	}
+	r.partial = append([]byte(nil), data[end:]...)
+	if end > 0 {
+		r.event("o", string(data[:end]))
+	}
+}
+
+func (r *recorder) resize(cols, rows int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.event("r", fmt.Sprintf("%dx%d", cols, rows))
+}
+
+// event writes one event; r.mu is held.
+func (r *recorder) event(typ, data string) {
+	line, _ := json.Marshal([]interface{}{time.Since(r.start).Seconds(), typ, data})
+	if _, err := fmt.Fprintf(r.f, "%s\n", line); err != nil {
+		log.Printf("recording: %v", err)
+	}
+}
+
+// WriteTo copies the recording so far to w.
+func (r *recorder) WriteTo(w io.Writer) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	f, err := os.Open(r.f.Name())
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	return io.Copy(w, f)
+}
@@ -0,0 +1,1011 @@
+// term.js is the browser end of webterm: a VT100/xterm terminal emulator
+// drawn with one <div> per row, fed the raw bytes of the session over a
+// WebSocket and sending keystrokes back unchanged.
+'use strict';
+
+(function () {
+
+// Attribute flags of a cell.
+const BOLD = 1, DIM = 2, ITALIC = 4, UNDERLINE = 8, INVERSE = 16, HIDDEN = 32, STRIKE = 64;
+
+const SCROLLBACK = 5000;
+
+// The xterm palette: 16 colours, the 6x6x6 cube, then 24 greys.
+const PALETTE = [
+	'#000000', '#cd0000', '#00cd00', '#cdcd00', '#0000ee', '#cd00cd', '#00cdcd', '#e5e5e5',
+	'#7f7f7f', '#ff0000', '#00ff00', '#ffff00', '#5c5cff', '#ff00ff', '#00ffff', '#ffffff',
+];
+(function () {
+	const level = [0, 95, 135, 175, 215, 255];
+	const hex = (n) => n.toString(16).padStart(2, '0');
+	for (let i = 0; i < 216; i++) {
+		PALETTE.push('#' + hex(level[Math.floor(i / 36)]) + hex(level[Math.floor(i / 6) % 6]) + hex(level[i % 6]));
+	}
+	for (let i = 0; i < 24; i++) {
+		PALETTE.push('#' + hex(8 + 10 * i).repeat(3));
+	}
+})();
+
+const DEFAULT_FG = '#cccccc', DEFAULT_BG = '#000000';
+
+// Attributes are shared, immutable objects; fg and bg are -1 for the
+// default colour, a palette index, or a '#rrggbb' string.
+function attr(fg, bg, flags) {
+	return { fg: fg, bg: bg, flags: flags };
+}
+const PLAIN = attr(-1, -1, 0);
+
+// Code points drawn two cells wide, and combining ones drawn on the
+// previous cell.
+const WIDE = [
+	[0x1100, 0x115f], [0x2e80, 0x303e], [0x3041, 0x33ff], [0x3400, 0x4dbf], [0x4e00, 0x9fff],
+	[0xa000, 0xa4cf], [0xac00, 0xd7a3], [0xf900, 0xfaff], [0xfe30, 0xfe4f], [0xff00, 0xff60],
+	[0xffe0, 0xffe6], [0x1f300, 0x1f64f], [0x1f900, 0x1f9ff], [0x20000, 0x3fffd],
+];
+const COMBINING = [[0x0300, 0x036f], [0x200b, 0x200f], [0x20d0, 0x20ff], [0xfe00, 0xfe0f], [0xfe20, 0xfe2f]];
+
+function inRanges(cp, ranges) {
+	for (const [lo, hi] of ranges) {
+		if (cp < lo) {
+			return false;
+		}
+		if (cp <= hi) {
+			return true;
+		}
+	}
+	return false;
+}
+
+// The DEC special graphics set, for line drawing after ESC ( 0.
+const GRAPHICS = {
+	'`': '◆', a: '▒', f: '°', g: '±', j: '┘', k: '┐', l: '┌', m: '└', n: '┼', o: '⎺', p: '⎻',
+	q: '─', r: '⎼', s: '⎽', t: '├', u: '┤', v: '┴', w: '┬', x: '│', y: '≤', z: '≥', '{': 'π',
+	'|': '≠', '}': '£', '~': '·',
+};
+
+class Terminal {
+	constructor(el, send) {
+		this.el = el;
+		this.scrollbackEl = el.querySelector('#scrollback');
+		this.screenEl = el.querySelector('#screen');
+		this.send = send;
+		this.cols = 80;
+		this.rows = 24;
+		this.reset();
+	}
+
+	reset() {
+		this.attr = PLAIN;
+		this.x = 0;
+		this.y = 0;
+		this.wrapPending = false;
+		this.top = 0;
+		this.bottom = this.rows - 1;
+		this.saved = null;
+		this.appCursor = false;
+		this.autowrap = true;
+		this.insert = false;
+		this.origin = false;
+		this.cursorVisible = true;
+		this.bracketedPaste = false;
+		// Whether G0 and G1 are the line drawing set, and which is shifted in.
+		this.charsets = [false, false];
+		this.shift = 0;
+		this.tabs = new Set();
+		for (let i = 8; i < this.cols; i += 8) {
+			this.tabs.add(i);
+		}
+		this.state = this.ground;
+		this.lines = [];
+		for (let i = 0; i < this.rows; i++) {
+			this.lines.push(this.blankLine());
+		}
+		this.main = null;
+		this.scrollbackEl.textContent = '';
+		this.build();
+	}
+
+	// build makes one row element per screen line.
+	build() {
+		this.screenEl.textContent = '';
+		this.rowEls = [];
+		for (let i = 0; i < this.rows; i++) {
+			const div = document.createElement('div');
+			this.screenEl.appendChild(div);
+			this.rowEls.push(div);
+		}
+		this.dirty = new Set(this.rowEls.keys());
+		this.cursorRow = -1;
+		this.schedule();
+	}
+
+	blankLine() {
+		const bg = attr(-1, this.attr.bg, 0);
+		const line = [];
+		for (let i = 0; i < this.cols; i++) {
+			line.push([' ', bg]);
+		}
+		return line;
+	}
+
+	resize(cols, rows) {
+		if (cols === this.cols && rows === this.rows) {
+			return;
+		}
+		for (const line of this.lines.concat(this.main || [])) {
+			line.length = Math.min(line.length, cols);
+			while (line.length < cols) {
+				line.push([' ', PLAIN]);
+			}
+		}
+		this.cols = cols;
+		// Lines that no longer fit above the cursor go to the scrollback.
+		while (this.lines.length > rows && this.y > 0) {
+			this.toScrollback(this.lines.shift());
+			this.y--;
+		}
+		this.lines.length = Math.min(this.lines.length, rows);
+		while (this.lines.length < rows) {
+			this.lines.push(this.blankLine());
+		}
+		if (this.main) {
+			this.main.length = Math.min(this.main.length, rows);
+			while (this.main.length < rows) {
+				this.main.push(this.blankLine());
+			}
+		}
+		this.rows = rows;
+		this.top = 0;
+		this.bottom = rows - 1;
+		this.x = Math.min(this.x, cols - 1);
+		this.y = Math.min(this.y, rows - 1);
+		this.wrapPending = false;
+		this.build();
+	}
+
+	write(s) {
+		for (const ch of s) {
+			this.state(ch);
+		}
+		this.schedule();
+	}
+
+	// Parser states; each takes one character.
+
+	ground(ch) {
+		const cp = ch.codePointAt(0);
+		if (cp < 0x20 || cp === 0x7f) {
+			this.control(ch);
+		} else {
+			this.print(ch, cp);
+		}
+	}
+
+	control(ch) {
+		switch (ch) {
+		case '\x07':
+			break;
+		case '\b':
+			if (this.x > 0) {
+				this.x--;
+			}
+			this.wrapPending = false;
+			break;
+		case '\t':
+			this.tab(1);
+			break;
+		case '\n': case '\x0b': case '\x0c':
+			this.lineFeed();
+			break;
+		case '\r':
+			this.x = 0;
+			this.wrapPending = false;
+			break;
+		case '\x0e':
+			this.shift = 1;
+			break;
+		case '\x0f':
+			this.shift = 0;
+			break;
+		case '\x1b':
+			this.state = this.escape;
+			break;
+		}
+	}
+
+	print(ch, cp) {
+		if (inRanges(cp, COMBINING)) {
+			const x = this.wrapPending ? this.x : this.x - 1;
+			if (x >= 0) {
+				this.lines[this.y][x][0] += ch;
+				this.dirty.add(this.y);
+			}
+			return;
+		}
+		if (this.charsets[this.shift] && GRAPHICS[ch]) {
+			ch = GRAPHICS[ch];
+		}
+		const w = inRanges(cp, WIDE) ? 2 : 1;
+		if (this.wrapPending || this.x + w > this.cols) {
+			if (this.autowrap) {
+				this.x = 0;
+				this.lineFeed();
+			} else {
+				this.x = this.cols - w;
+			}
+		}
+		this.wrapPending = false;
+
+		const line = this.lines[this.y];
+		if (this.insert) {
+			line.splice(this.x, 0, ...Array.from({ length: w }, () => [' ', this.attr]));
+			line.length = this.cols;
+		}
+		line[this.x] = [ch, this.attr];
+		if (w === 2) {
+			line[this.x + 1] = ['', this.attr];
+		}
+		this.dirty.add(this.y);
+
+		this.x += w;
+		if (this.x >= this.cols) {
+			this.x = this.cols - 1;
+			this.wrapPending = true;
+		}
+	}
+
+	escape(ch) {
+		this.state = this.ground;
+		switch (ch) {
+		case '[':
+			this.params = '';
+			this.state = this.csi;
+			break;
+		case ']':
+			this.osc = '';
+			this.state = this.oscString;
+			break;
+		case 'P': case 'X': case '^': case '_':
+			this.state = this.ignoreString;
+			break;
+		case '(': case ')': {
+			const g = ch === '(' ? 0 : 1;
+			this.state = (c) => {
+				this.charsets[g] = c === '0';
+				this.state = this.ground;
+			};
+			break;
+		}
+		case '*': case '+': case '#': case '%': case ' ':
+			this.state = () => {
+				this.state = this.ground;
+			};
+			break;
+		case '7':
+			this.saveCursor();
+			break;
+		case '8':
+			this.restoreCursor();
+			break;
+		case 'D':
+			this.lineFeed();
+			break;
+		case 'E':
+			this.x = 0;
+			this.lineFeed();
+			break;
+		case 'H':
+			this.tabs.add(this.x);
+			break;
+		case 'M':
+			this.reverseIndex();
+			break;
+		case 'c':
+			this.reset();
+			break;
+		case '\x1b':
+			this.state = this.escape;
+			break;
+		}
+	}
+
+	csi(ch) {
+		if (ch >= '0' && ch <= '?' || ch >= ' ' && ch <= '/') {
+			this.params += ch;
+			if (this.params.length > 64) {
+				this.state = this.ground;
+			}
+			return;
+		}
+		if (ch < ' ') {
+			// Controls inside a sequence take effect and parsing goes on.
+			this.control(ch);
+			return;
+		}
+		this.state = this.ground;
+		let priv = '';
+		let p = this.params;
+		if (/^[<=>?]/.test(p)) {
+			priv = p[0];
+			p = p.slice(1);
+		}
+		const inter = p.replace(/[0-9;:]/g, '');
+		const args = p.replace(/[^0-9;:]/g, '').split(';').map((s) => parseInt(s, 10));
+		this.dispatch(ch, priv, inter, args);
+	}
+
+	oscString(ch) {
+		if (ch === '\x07' || ch === '\x1b') {
+			this.state = ch === '\x1b' ? this.stringEnd : this.ground;
+			const m = /^[02];(.*)$/s.exec(this.osc);
+			if (m) {
+				document.title = m[1];
+			}
+			return;
+		}
+		if (this.osc.length < 1024) {
+			this.osc += ch;
+		}
+	}
+
+	ignoreString(ch) {
+		if (ch === '\x1b') {
+			this.state = this.stringEnd;
+		} else if (ch === '\x07') {
+			this.state = this.ground;
+		}
+	}
+
+	// stringEnd eats the backslash of ST.
+	stringEnd(ch) {
+		this.state = this.ground;
+		if (ch !== '\\') {
+			this.escape(ch);
+		}
+	}
+
+	dispatch(ch, priv, inter, args) {
+		const n = (i, def = 1) => (args[i] > 0 ? args[i] : def);
+		if (inter !== '' && !(inter === '!' && ch === 'p')) {
+			return;
+		}
+		if (priv !== '' && priv !== '?') {
+			return;
+		}
+		switch (ch) {
+		case '@': {
+			const line = this.lines[this.y];
+			line.splice(this.x, 0, ...Array.from({ length: n(0) }, () => [' ', this.attr]));
+			line.length = this.cols;
+			this.dirty.add(this.y);
+			break;
+		}
+		case 'A':
+			this.moveTo(this.x, Math.max(this.y - n(0), this.y >= this.top ? this.top : 0));
+			break;
+		case 'B': case 'e':
+			this.moveTo(this.x, Math.min(this.y + n(0), this.y <= this.bottom ? this.bottom : this.rows - 1));
+			break;
+		case 'C': case 'a':
+			this.moveTo(this.x + n(0), this.y);
+			break;
+		case 'D':
+			this.moveTo(this.x - n(0), this.y);
+			break;
+		case 'E':
+			this.moveTo(0, this.y + n(0));
+			break;
+		case 'F':
+			this.moveTo(0, this.y - n(0));
+			break;
+		case 'G': case '`':
+			this.moveTo(n(0) - 1, this.y);
+			break;
+		case 'H': case 'f':
+			this.moveTo(n(1) - 1, n(0) - 1 + (this.origin ? this.top : 0));
+			break;
+		case 'I':
+			this.tab(n(0));
+			break;
+		case 'J':
+			this.eraseDisplay(args[0] || 0);
+			break;
+		case 'K':
+			this.eraseLine(args[0] || 0);
+			break;
+		case 'L':
+			if (this.y >= this.top && this.y <= this.bottom) {
+				this.scrollDown(n(0), this.y);
+			}
+			break;
+		case 'M':
+			if (this.y >= this.top && this.y <= this.bottom) {
+				this.scrollUp(n(0), this.y);
+			}
+			break;
+		case 'P': {
+			const line = this.lines[this.y];
+			line.splice(this.x, n(0));
+			while (line.length < this.cols) {
+				line.push([' ', attr(-1, this.attr.bg, 0)]);
+			}
+			this.dirty.add(this.y);
+			break;
+		}
+		case 'S':
+			this.scrollUp(n(0), this.top);
+			break;
+		case 'T':
+			this.scrollDown(n(0), this.top);
+			break;
+		case 'X':
+			this.erase(this.y, this.x, this.x + n(0));
+			break;
+		case 'Z':
+			for (let i = 0; i < n(0); i++) {
+				let x = this.x - 1;
+				while (x > 0 && !this.tabs.has(x)) {
+					x--;
+				}
+				this.x = Math.max(x, 0);
+			}
+			break;
+		case 'd':
+			this.moveTo(this.x, n(0) - 1 + (this.origin ? this.top : 0));
+			break;
+		case 'g':
+			if ((args[0] || 0) === 0) {
+				this.tabs.delete(this.x);
+			} else if (args[0] === 3) {
+				this.tabs.clear();
+			}
+			break;
+		case 'h': case 'l':
+			for (const mode of args) {
+				this.setMode(priv, mode, ch === 'h');
+			}
+			break;
+		case 'm':
+			this.sgr(args);
+			break;
+		case 'p':
+			if (inter !== '!') {
+				break;
+			}
+			// DECSTR, a soft reset.
+			this.attr = PLAIN;
+			this.insert = this.origin = this.appCursor = false;
+			this.autowrap = this.cursorVisible = true;
+			this.top = 0;
+			this.bottom = this.rows - 1;
+			break;
+		case 'r':
+			if (priv === '') {
+				const top = n(0) - 1, bottom = n(1, this.rows) - 1;
+				if (top < bottom && bottom < this.rows) {
+					this.top = top;
+					this.bottom = bottom;
+					this.moveTo(0, this.origin ? top : 0);
+				}
+			}
+			break;
+		case 's':
+			this.saveCursor();
+			break;
+		case 'u':
+			this.restoreCursor();
+			break;
+		// Status and attribute queries (n, c) are not answered: with several
+		// viewers the program would get one answer per browser.
+		}
+	}
+
+	setMode(priv, mode, on) {
+		if (priv === '') {
+			if (mode === 4) {
+				this.insert = on;
+			}
+			return;
+		}
+		switch (mode) {
+		case 1:
+			this.appCursor = on;
+			break;
+		case 6:
+			this.origin = on;
+			this.moveTo(0, on ? this.top : 0);
+			break;
+		case 7:
+			this.autowrap = on;
+			break;
+		case 25:
+			this.cursorVisible = on;
+			this.dirty.add(this.y);
+			break;
+		case 47: case 1047: case 1049:
+			if (on && !this.main) {
+				if (mode === 1049) {
+					this.saveCursor();
+				}
+				this.main = this.lines;
+				this.lines = [];
+				for (let i = 0; i < this.rows; i++) {
+					this.lines.push(this.blankLine());
+				}
+			} else if (!on && this.main) {
+				this.lines = this.main;
+				this.main = null;
+				if (mode === 1049) {
+					this.restoreCursor();
+				}
+			}
+			this.dirty = new Set(this.rowEls.keys());
+			break;
+		case 2004:
+			this.bracketedPaste = on;
+			break;
+		}
+	}
+
+	sgr(args) {
+		let { fg, bg, flags } = this.attr;
+		for (let i = 0; i < args.length; i++) {
+			const a = isNaN(args[i]) ? 0 : args[i];
+			if (a === 0) {
+				fg = bg = -1;
+				flags = 0;
+			} else if (a === 1) {
+				flags |= BOLD;
+			} else if (a === 2) {
+				flags |= DIM;
+			} else if (a === 3) {
+				flags |= ITALIC;
+			} else if (a === 4) {
+				flags |= UNDERLINE;
+			} else if (a === 7) {
+				flags |= INVERSE;
+			} else if (a === 8) {
+				flags |= HIDDEN;
+			} else if (a === 9) {
+				flags |= STRIKE;
+			} else if (a === 21 || a === 22) {
+				flags &= ~(BOLD | DIM);
+			} else if (a === 23) {
+				flags &= ~ITALIC;
+			} else if (a === 24) {
+				flags &= ~UNDERLINE;
+			} else if (a === 27) {
+				flags &= ~INVERSE;
+			} else if (a === 28) {
+				flags &= ~HIDDEN;
+			} else if (a === 29) {
+				flags &= ~STRIKE;
+			} else if (a >= 30 && a <= 37) {
+				fg = a - 30;
+			} else if (a === 39) {
+				fg = -1;
+			} else if (a >= 40 && a <= 47) {
+				bg = a - 40;
+			} else if (a === 49) {
+				bg = -1;
+			} else if (a >= 90 && a <= 97) {
+				fg = a - 90 + 8;
+			} else if (a >= 100 && a <= 107) {
+				bg = a - 100 + 8;
+			} else if (a === 38 || a === 48) {
+				let c;
+				if (args[i + 1] === 5) {
+					c = args[i + 2] & 255;
+					i += 2;
+				} else if (args[i + 1] === 2) {
+					const hex = (v) => ((v | 0) & 255).toString(16).padStart(2, '0');
+					c = '#' + hex(args[i + 2]) + hex(args[i + 3]) + hex(args[i + 4]);
+					i += 4;
+				} else {
+					break;
+				}
+				if (a === 38) {
+					fg = c;
+				} else {
+					bg = c;
+				}
+			}
+		}
+		this.attr = attr(fg, bg, flags);
+	}
+
+	moveTo(x, y) {
+		this.x = Math.max(0, Math.min(x, this.cols - 1));
+		const top = this.origin ? this.top : 0, bottom = this.origin ? this.bottom : this.rows - 1;
+		this.y = Math.max(top, Math.min(y, bottom));
+		this.wrapPending = false;
+	}
+
+	tab(n) {
+		for (let i = 0; i < n && this.x < this.cols - 1; i++) {
+			do {
+				this.x++;
+			} while (this.x < this.cols - 1 && !this.tabs.has(this.x));
+		}
+		this.wrapPending = false;
+	}
+
+	lineFeed() {
+		if (this.y === this.bottom) {
+			this.scrollUp(1, this.top);
+		} else if (this.y < this.rows - 1) {
+			this.y++;
+		}
+		this.wrapPending = false;
+	}
+
+	reverseIndex() {
+		if (this.y === this.top) {
+			this.scrollDown(1, this.top);
+		} else if (this.y > 0) {
+			this.y--;
+		}
+		this.wrapPending = false;
+	}
+
+	// scrollUp moves lines from..bottom up by n; lines leaving the top of
+	// the main screen go to the scrollback.
+	scrollUp(n, from) {
+		n = Math.min(n, this.bottom - from + 1);
+		for (let i = 0; i < n; i++) {
+			const [line] = this.lines.splice(from, 1);
+			if (from === 0 && !this.main) {
+				this.toScrollback(line);
+			}
+			this.lines.splice(this.bottom, 0, this.blankLine());
+		}
+		for (let y = from; y <= this.bottom; y++) {
+			this.dirty.add(y);
+		}
+	}
+
+	scrollDown(n, from) {
+		n = Math.min(n, this.bottom - from + 1);
+		for (let i = 0; i < n; i++) {
+			this.lines.splice(this.bottom, 1);
+			this.lines.splice(from, 0, this.blankLine());
+		}
+		for (let y = from; y <= this.bottom; y++) {
+			this.dirty.add(y);
+		}
+	}
+
+	erase(y, from, to) {
+		const blank = attr(-1, this.attr.bg, 0);
+		const line = this.lines[y];
+		for (let x = from; x < Math.min(to, this.cols); x++) {
+			line[x] = [' ', blank];
+		}
+		this.dirty.add(y);
+	}
+
+	eraseLine(mode) {
+		if (mode === 0) {
+			this.erase(this.y, this.x, this.cols);
+		} else if (mode === 1) {
+			this.erase(this.y, 0, this.x + 1);
+		} else if (mode === 2) {
+			this.erase(this.y, 0, this.cols);
+		}
+	}
+
+	eraseDisplay(mode) {
+		if (mode === 0) {
+			this.eraseLine(0);
+			for (let y = this.y + 1; y < this.rows; y++) {
+				this.erase(y, 0, this.cols);
+			}
+		} else if (mode === 1) {
+			this.eraseLine(1);
+			for (let y = 0; y < this.y; y++) {
+				this.erase(y, 0, this.cols);
+			}
+		} else if (mode === 2) {
+			for (let y = 0; y < this.rows; y++) {
+				this.erase(y, 0, this.cols);
+			}
+		} else if (mode === 3) {
+			this.scrollbackEl.textContent = '';
+		}
+	}
+
+	saveCursor() {
+		this.saved = { x: this.x, y: this.y, attr: this.attr, charsets: this.charsets.slice(), origin: this.origin };
+	}
+
+	restoreCursor() {
+		const s = this.saved || { x: 0, y: 0, attr: PLAIN, charsets: [false, false], origin: false };
+		this.x = s.x;
+		this.y = s.y;
+		this.attr = s.attr;
+		this.charsets = s.charsets;
+		this.origin = s.origin;
+		this.moveTo(this.x, this.y);
+	}
+
+	toScrollback(line) {
+		const div = document.createElement('div');
+		div.innerHTML = this.html(line, -1);
+		this.scrollbackEl.appendChild(div);
+		while (this.scrollbackEl.childElementCount > SCROLLBACK) {
+			this.scrollbackEl.firstElementChild.remove();
+		}
+	}
+
+	// Rendering.
+
+	schedule() {
+		if (!this.frame) {
+			this.frame = requestAnimationFrame(() => this.render());
+		}
+	}
+
+	render() {
+		this.frame = 0;
+		const atBottom = this.el.scrollTop + this.el.clientHeight >= this.el.scrollHeight - 4;
+		const cursor = this.cursorVisible ? this.y : -1;
+		this.dirty.add(this.cursorRow);
+		this.dirty.add(cursor);
+		for (const y of this.dirty) {
+			if (y >= 0 && y < this.rows) {
+				this.rowEls[y].innerHTML = this.html(this.lines[y], y === cursor ? this.x : -1);
+			}
+		}
+		this.dirty.clear();
+		this.cursorRow = cursor;
+		if (atBottom) {
+			this.el.scrollTop = this.el.scrollHeight;
+		}
+	}
+
+	html(line, cursorX) {
+		let out = '';
+		let run = '', style = null;
+		const flush = () => {
+			if (run !== '') {
+				out += style === '' ? run : '<span style="' + style + '">' + run + '</span>';
+			}
+			run = '';
+		};
+		for (let x = 0; x < line.length; x++) {
+			const [ch, a] = line[x];
+			if (ch === '') {
+				continue;
+			}
+			const s = cellStyle(a);
+			if (x === cursorX) {
+				flush();
+				out += '<span class="cursor"' + (s ? ' style="' + s + '"' : '') + '>' + escapeHTML(ch) + '</span>';
+				style = null;
+				continue;
+			}
+			if (s !== style) {
+				flush();
+				style = s;
+			}
+			run += escapeHTML(ch);
+		}
+		flush();
+		return out;
+	}
+
+	// Input.
+
+	paste(text) {
+		text = text.replace(/\r?\n/g, '\r');
+		if (this.bracketedPaste) {
+			text = '\x1b[200~' + text.replace(/\x1b\[201~/g, '') + '\x1b[201~';
+		}
+		this.send(text);
+	}
+
+	key(e) {
+		const app = this.appCursor;
+		const arrows = { ArrowUp: 'A', ArrowDown: 'B', ArrowRight: 'C', ArrowLeft: 'D', Home: 'H', End: 'F' };
+		const special = {
+			Enter: '\r', Backspace: '\x7f', Escape: '\x1b', Insert: '\x1b[2~', Delete: '\x1b[3~',
+			PageUp: '\x1b[5~', PageDown: '\x1b[6~', F1: '\x1bOP', F2: '\x1bOQ', F3: '\x1bOR', F4: '\x1bOS',
+			F5: '\x1b[15~', F6: '\x1b[17~', F7: '\x1b[18~', F8: '\x1b[19~', F9: '\x1b[20~',
+			F10: '\x1b[21~', F11: '\x1b[23~', F12: '\x1b[24~',
+		};
+		if (e.metaKey || e.isComposing) {
+			return null;
+		}
+		if (e.key in arrows) {
+			const mod = 1 + (e.shiftKey ? 1 : 0) + (e.altKey ? 2 : 0) + (e.ctrlKey ? 4 : 0);
+			if (mod > 1) {
+				return '\x1b[1;' + mod + arrows[e.key];
+			}
+			return (app ? '\x1bO' : '\x1b[') + arrows[e.key];
+		}
+		if (e.key === 'Tab') {
+			return e.shiftKey ? '\x1b[Z' : '\t';
+		}
+		if (e.key in special) {
+			return (e.altKey ? '\x1b' : '') + special[e.key];
+		}
+		if (e.key.length !== 1 && [...e.key].length !== 1) {
+			return null;
+		}
+		if (e.ctrlKey && !e.altKey) {
+			// Ctrl+Shift+C and Ctrl+Shift+V stay the browser's copy and paste.
+			if (e.shiftKey && (e.key === 'C' || e.key === 'V')) {
+				return null;
+			}
+			const k = e.key.toUpperCase();
+			if (k >= '@' && k <= '_') {
+				return String.fromCharCode(k.charCodeAt(0) - 64);
+			}
+			if (k === ' ' || k === '2') {
+				return '\x00';
+			}
+			if (k === '/' || k === '-') {
+				return '\x1f';
+			}
+			if (k === '?' || k === '8') {
+				return '\x7f';
+			}
+			return null;
+		}
+		if (e.altKey) {
+			return '\x1b' + e.key;
+		}
+		return e.key;
+	}
+}
+
+function cellStyle(a) {
+	let fg = a.fg, bg = a.bg;
+	if (a.flags & BOLD && typeof fg === 'number' && fg >= 0 && fg < 8) {
+		fg += 8;
+	}
+	fg = fg === -1 ? DEFAULT_FG : typeof fg === 'number' ? PALETTE[fg] : fg;
+	bg = bg === -1 ? DEFAULT_BG : typeof bg === 'number' ? PALETTE[bg] : bg;
+	if (a.flags & INVERSE) {
+		[fg, bg] = [bg, fg];
+	}
+	if (a.flags & HIDDEN) {
+		fg = bg;
+	}
+	let s = '';
+	if (fg !== DEFAULT_FG) {
+		s += 'color:' + fg + ';';
+	}
+	if (bg !== DEFAULT_BG) {
+		s += 'background:' + bg + ';';
+	}
+	if (a.flags & BOLD) {
+		s += 'font-weight:bold;';
+	}
+	if (a.flags & DIM) {
+		s += 'opacity:.6;';
+	}
+	if (a.flags & ITALIC) {
+		s += 'font-style:italic;';
+	}
+	if (a.flags & (UNDERLINE | STRIKE)) {
+		s += 'text-decoration:' + (a.flags & UNDERLINE ? 'underline ' : '') + (a.flags & STRIKE ? 'line-through' : '') + ';';
+	}
+	return s;
+}
+
+function escapeHTML(s) {
+	return s.replace(/&/g, '&amp;').replace(/</g, '&lt;').replace(/>/g, '&gt;');
+}
+
+// The page: connect, feed the terminal, send keys, follow the window size.
+
+const termEl = document.getElementById('term');
+const input = document.getElementById('input');
+const stateEl = document.getElementById('state');
+const viewersEl = document.getElementById('viewers');
+
+let ws = null;
+const encoder = new TextEncoder();
+const term = new Terminal(termEl, (text) => {
+	if (ws && ws.readyState === WebSocket.OPEN) {
+		ws.send(encoder.encode(text));
+	}
+});
+
+function cellSize() {
+	const probe = document.createElement('span');
+	probe.textContent = 'W'.repeat(10);
+	termEl.appendChild(probe);
+	const r = probe.getBoundingClientRect();
+	probe.remove();
+	return { w: r.width / 10, h: r.height };
+}
+
+function fit() {
+	const cell = cellSize();
+	const style = getComputedStyle(termEl);
+	const w = termEl.clientWidth - parseFloat(style.paddingLeft) - parseFloat(style.paddingRight) - 16;
+	const h = termEl.clientHeight - parseFloat(style.paddingTop) - parseFloat(style.paddingBottom);
+	const cols = Math.max(10, Math.floor(w / cell.w));
+	const rows = Math.max(4, Math.floor(h / (cell.h || 18)));
+	term.resize(cols, rows);
+	if (ws && ws.readyState === WebSocket.OPEN) {
+		ws.send(JSON.stringify({ type: 'resize', cols: cols, rows: rows }));
+	}
+}
+
+function connect() {
+	const url = (location.protocol === 'https:' ? 'wss://' : 'ws://') + location.host +
+		location.pathname.replace(/[^/]*$/, '') + 'ws';
+	ws = new WebSocket(url);
+	ws.binaryType = 'arraybuffer';
+	const decoder = new TextDecoder('utf-8');
+	let exited = false;
+
+	ws.onopen = () => {
+		stateEl.textContent = 'connected';
+		// The server replays recent output; start from a clean screen.
+		term.reset();
+		fit();
+	};
+	ws.onmessage = (ev) => {
+		if (typeof ev.data !== 'string') {
+			term.write(decoder.decode(ev.data, { stream: true }));
+			return;
+		}
+		const msg = JSON.parse(ev.data);
+		if (msg.type === 'viewers') {
+			viewersEl.textContent = msg.count === 1 ? '1 viewer' : msg.count + ' viewers';
+		} else if (msg.type === 'exit') {
+			exited = true;
+			stateEl.textContent = 'session ended: ' + msg.status;
+		}
+	};
+	ws.onclose = () => {
+		viewersEl.textContent = '';
+		if (!exited) {
+			stateEl.textContent = 'disconnected, reconnecting';
+			setTimeout(connect, 2000);
+		}
+	};
+}
+
+input.addEventListener('keydown', (e) => {
+	const s = term.key(e);
+	if (s !== null) {
+		e.preventDefault();
+		term.send(s);
+	}
+});
+input.addEventListener('compositionend', (e) => {
+	term.send(e.data);
+	input.value = '';
+});
+input.addEventListener('input', (e) => {
+	if (!e.isComposing) {
+		input.value = '';
+	}
+});
+input.addEventListener('paste', (e) => {
+	e.preventDefault();
+	term.paste(e.clipboardData.getData('text/plain'));
+});
+// A click focuses the keyboard input, unless it ends a text selection.
+termEl.addEventListener('mouseup', () => {
+	if (String(window.getSelection()) === '') {
+		input.focus();
+	}
+});
+
+let resizeTimer = 0;
+window.addEventListener('resize', () => {
+	clearTimeout(resizeTimer);
+	resizeTimer = setTimeout(fit, 100);
+});
+
+fit();
+input.focus();
+connect();
+
+})();
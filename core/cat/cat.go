@@ -0,0 +1,210 @@
+package cat
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ilnarildarovuch/CoreUtils-On-GO/internal/cli"
+)
+
+const (
+	version    = "1.1.0"
+	maxLineLen = 10 * 1024 * 1024 // 10MB
+)
+
+var (
+	number          bool
+	numberNonblank  bool
+	squeezeBlank    bool
+	showEnds        bool
+	showNonprinting bool
+	showTabs        bool
+	showAll         bool
+	followSymlinks  bool
+)
+
+var flags = cli.NewFlagSet(version)
+
+func init() {
+	flags.BoolVar(&showAll, 'A', "show-all", "equivalent to -vET")
+	flags.BoolVar(&numberNonblank, 'b', "number-nonblank", "number nonempty output lines, overrides -n")
+	flags.BoolFunc('e', "", "equivalent to -vE", func() { showNonprinting, showEnds = true, true })
+	flags.BoolVar(&showEnds, 'E', "show-ends", "display $ at end of each line")
+	flags.BoolVar(&number, 'n', "number", "number all output lines")
+	flags.BoolVar(&squeezeBlank, 's', "squeeze-blank", "suppress repeated empty output lines")
+	flags.BoolFunc('t', "", "equivalent to -vT", func() { showNonprinting, showTabs = true, true })
+	flags.BoolVar(&showTabs, 'T', "show-tabs", "display TAB characters as ^I")
+	flags.BoolVar(&showNonprinting, 'v', "show-nonprinting", "use ^ and M- notation, except for LFD and TAB")
+	flags.BoolVar(&followSymlinks, 'L', "", "follow symbolic links (default false)")
+}
+
+func Main() {
+	flags.Usage = usage
+	flags.Parse(os.Args[1:])
+
+	handleCombinedOptions()
+
+	for _, file := range getInputFiles() {
+		if err := processFile(file); err != nil {
+			cli.Failf("%s: %v", file, err)
+		}
+	}
+
+	cli.Exit()
+}
+
+func usage() {
+	w := flags.Output()
+	fmt.Fprintf(w, "Secure cat %s\n", version)
+	fmt.Fprintf(w, "Usage: %s [OPTION]... [FILE]...\n", cli.Name)
+	fmt.Fprintln(w, "\nOptions:")
+	flags.PrintDefaults()
+	fmt.Fprintf(w, "\nExamples:\n  %s -n file.txt\n  %s -v binary.data\n", cli.Name, cli.Name)
+}
+
+func handleCombinedOptions() {
+	if showAll {
+		showNonprinting = true
+		showEnds = true
+		showTabs = true
+	}
+	if numberNonblank {
+		number = true
+	}
+}
+
+func getInputFiles() []string {
+	files := flags.Args()
+	if len(files) == 0 {
+		return []string{"-"}
+	}
+	return files
+}
+
+func processFile(filename string) error {
+	if filename == "-" {
+		return processStdin()
+	}
+	return processRegularFile(filename)
+}
+
+func processStdin() error {
+	if showNonprinting || showTabs || showEnds || number || numberNonblank || squeezeBlank {
+		return processWithOptions(os.Stdin, os.Stdout)
+	}
+	_, err := io.Copy(os.Stdout, os.Stdin)
+	return err
+}
+
+func processRegularFile(filename string) error {
+	if _, err := os.Lstat(filename); err != nil {
+		return fmt.Errorf("cannot access file: %w", err)
+	}
+
+	var input *os.File
+	var err error
+
+	if followSymlinks {
+		input, err = os.Open(filename)
+	} else {
+		input, err = os.OpenFile(filename, os.O_RDONLY, 0)
+		if err != nil && os.IsPermission(err) {
+			if resolved, err := filepath.EvalSymlinks(filename); err == nil && resolved != filename {
+				return fmt.Errorf("refusing to follow symlink")
+			}
+			input, err = os.Open(filename)
+		}
+	}
+
+	if err != nil {
+		return fmt.Errorf("cannot open: %w", err)
+	}
+	defer safeClose(input)
+
+	if showNonprinting || showTabs || showEnds || number || numberNonblank || squeezeBlank {
+		return processWithOptions(input, os.Stdout)
+	}
+
+	_, err = io.Copy(os.Stdout, input)
+	return err
+}
+
+func safeClose(closer io.Closer) {
+	if err := closer.Close(); err != nil {
+		cli.Warnf("warning: error closing file: %v", err)
+	}
+}
+
+func processWithOptions(input io.Reader, output io.Writer) error {
+	scanner := bufio.NewScanner(input)
+	buf := make([]byte, 0, 64*1024)
+	scanner.Buffer(buf, maxLineLen)
+
+	lineNum := 1
+	prevBlank := false
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		isBlank := len(line) == 0
+
+		if squeezeBlank && isBlank && prevBlank {
+			continue
+		}
+		prevBlank = isBlank
+
+		if (number && !numberNonblank) || (numberNonblank && !isBlank) {
+			fmt.Fprintf(output, "%6d\t", lineNum)
+			lineNum++
+		}
+
+		if showNonprinting || showTabs {
+			line = processNonprinting(line)
+		}
+
+		fmt.Fprint(output, line)
+
+		if showEnds {
+			fmt.Fprint(output, "$")
+		}
+
+		fmt.Fprintln(output)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("reading input: %w", err)
+	}
+	return nil
+}
+
+func processNonprinting(s string) string {
+	if !(showNonprinting || showTabs) {
+		return s
+	}
+
+	var buf strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '\t' && showTabs:
+			buf.WriteString("^I")
+		case r >= 32 && r < 127:
+			buf.WriteRune(r)
+		case r == 127:
+			buf.WriteString("^?")
+		case r < 32:
+			buf.WriteString(fmt.Sprintf("^%c", r+64))
+		case r >= 128 && r < 128+32:
+			buf.WriteString(fmt.Sprintf("M-^%c", r-128+64))
+		case r >= 128+32 && r < 128+127:
+			buf.WriteString(fmt.Sprintf("M-%c", r-128))
+		case r >= 128+127:
+			buf.WriteString("M-^?")
+		default:
+			buf.WriteRune(r)
+		}
+	}
+	return buf.String()
+}
\ No newline at end of file
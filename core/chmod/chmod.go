@@ -0,0 +1,195 @@
+package chmod
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ilnarildarovuch/CoreUtils-On-GO/internal/cli"
+	"github.com/ilnarildarovuch/CoreUtils-On-GO/internal/mode"
+)
+
+const version = "1.0.0"
+
+var (
+	changesOnly   bool
+	forceSilent   bool
+	verbose       bool
+	noDereference bool
+	preserveRoot  bool
+	reference     string
+	recursive     bool
+	umask         uint32
+)
+
+var flags = cli.NewFlagSet(version)
+
+func init() {
+	flags.BoolVar(&changesOnly, 'c', "changes", "like verbose but report only when a change is made")
+	flags.BoolVar(&forceSilent, 'f', "silent", "suppress most error messages")
+	flags.BoolVar(&forceSilent, 0, "quiet", "equivalent to -f")
+	flags.BoolVar(&verbose, 'v', "verbose", "output a diagnostic for every file processed")
+	flags.BoolFunc(0, "dereference", "affect the referent of each symbolic link", func() { noDereference = false })
+	flags.BoolVar(&noDereference, 'h', "no-dereference", "affect each symbolic link, rather than the referent")
+	flags.BoolVar(&preserveRoot, 0, "preserve-root", "fail to operate recursively on '/'")
+	flags.BoolFunc(0, "no-preserve-root", "do not treat '/' specially (default)", func() { preserveRoot = false })
+	flags.StringVar(&reference, 0, "reference", "", "use `RFILE`'s mode instead of MODE values")
+	flags.BoolVar(&recursive, 'R', "recursive", "change files and directories recursively")
+}
+
+func Main() {
+	flags.Usage = usage
+	dashModes, rest := splitDashModes(os.Args[1:])
+	flags.Parse(rest)
+
+	args := flags.Args()
+	spec := dashModes
+	if reference == "" && spec == "" && len(args) > 0 {
+		spec, args = args[0], args[1:]
+	}
+	if len(args) < 1 {
+		flags.UsageError("missing operand")
+	}
+
+	if reference != "" {
+		refMode, err := getFileMode(reference)
+		if err != nil {
+			cli.Fatalf("%s: %v", reference, err)
+		}
+		spec = fmt.Sprintf("%o", mode.FromFileMode(refMode))
+	}
+
+	modeBits, err := mode.Parse(spec)
+	if err != nil {
+		cli.Fatalf("%v", err)
+	}
+	umask = mode.Umask()
+
+	for _, file := range args {
+		if err := changeMode(file, modeBits); err != nil {
+			cli.Failf("%s: %v", file, err)
+		}
+	}
+
+	cli.Exit()
+}
+
+// splitDashModes takes out the arguments before "--" that are modes
+// starting with a dash, such as "-w" or "-x,o-r", which would otherwise be
+// read as options. Like GNU chmod, several of them are joined into one.
+func splitDashModes(args []string) (string, []string) {
+	var modes []string
+	var rest []string
+	for i, arg := range args {
+		if arg == "--" {
+			rest = append(rest, args[i:]...)
+			break
+		}
+		if len(arg) > 1 && arg[0] == '-' && strings.Trim(arg, "rwxXstugoa,+-=01234567") == "" {
+			if _, err := mode.Parse(arg); err == nil {
+				modes = append(modes, arg)
+				continue
+			}
+		}
+		rest = append(rest, arg)
+	}
+	return strings.Join(modes, ","), rest
+}
+
+func usage() {
+	w := flags.Output()
+	fmt.Fprintf(w, "Usage: %s [OPTION]... MODE[,MODE]... FILE...\n", cli.Name)
+	fmt.Fprintf(w, "  or:  %s [OPTION]... OCTAL-MODE FILE...\n", cli.Name)
+	fmt.Fprintf(w, "  or:  %s [OPTION]... --reference=RFILE FILE...\n", cli.Name)
+	fmt.Fprintln(w, "\nChange the mode of each FILE to MODE.")
+	fmt.Fprintln(w, "\nOptions:")
+	flags.PrintDefaults()
+	fmt.Fprintf(w, "\nExamples:\n  %s 644 file.txt\n  %s u=rw,go=r file.txt\n", cli.Name, cli.Name)
+}
+
+func getFileMode(path string) (os.FileMode, error) {
+	fileInfo, err := os.Stat(path)
+	if err != nil {
+		return 0, err
+	}
+	return fileInfo.Mode(), nil
+}
+
+func changeMode(path string, m *mode.Mode) error {
+	if recursive {
+		return filepath.Walk(path, func(p string, info os.FileInfo, err error) error {
+			if err != nil {
+				if !forceSilent {
+					return err
+				}
+				return nil
+			}
+
+			if preserveRoot && p == "/" {
+				return fmt.Errorf("it is dangerous to operate recursively on '/'")
+			}
+
+			return applyMode(p, info, m)
+		})
+	}
+
+	info, err := os.Lstat(path)
+	if err != nil {
+		return err
+	}
+
+	return applyMode(path, info, m)
+}
+
+func applyMode(path string, info os.FileInfo, m *mode.Mode) error {
+	if info.Mode()&os.ModeSymlink != 0 {
+		if noDereference {
+			if verbose {
+				fmt.Printf("mode of %s retained as symbolic link\n", path)
+			}
+			return nil
+		}
+		// Dereference symlink
+		var err error
+		info, err = os.Stat(path)
+		if err != nil {
+			if !forceSilent {
+				return err
+			}
+			return nil
+		}
+	}
+	targetMode := m.Apply(info.Mode(), umask)
+
+	if mode.FromFileMode(info.Mode()) == mode.FromFileMode(targetMode) {
+		if verbose {
+			fmt.Printf("mode of %s retained as %04o\n", path, info.Mode().Perm())
+		}
+		return nil
+	}
+
+	err := os.Chmod(path, targetMode)
+	if err != nil {
+		return err
+	}
+
+	if verbose || changesOnly {
+		newInfo, err := os.Lstat(path)
+		if err != nil {
+			if !forceSilent {
+				return err
+			}
+			return nil
+		}
+
+		if newInfo.Mode().Perm() != info.Mode().Perm() {
+			fmt.Printf("mode of %s changed from %04o to %04o\n",
+				path, info.Mode().Perm(), newInfo.Mode().Perm())
+		} else if verbose {
+			fmt.Printf("mode of %s retained as %04o\n", path, info.Mode().Perm())
+		}
+	}
+
+	return nil
+}
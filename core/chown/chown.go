@@ -0,0 +1,307 @@
+package chown
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/ilnarildarovuch/CoreUtils-On-GO/internal/cli"
+	"github.com/ilnarildarovuch/CoreUtils-On-GO/internal/users"
+)
+
+type ChownMode int
+type Verbosity int
+
+const (
+	version           = "1.0.0"
+	CHOWN   ChownMode = iota
+	CHGRP
+	V_normal Verbosity = iota
+	V_changes_only
+	V_high
+)
+
+var (
+	recursive     bool
+	changes       bool
+	noDereference bool
+	from          string
+	preserveRoot  bool
+	quiet         bool
+	verbose       bool
+	reference     string
+	chownMode     = CHOWN
+)
+
+var flags = cli.NewFlagSet(version)
+
+func init() {
+	flags.BoolVar(&changes, 'c', "changes", "like verbose but report only when a change is made")
+	flags.BoolVar(&quiet, 'f', "silent", "suppress most error messages")
+	flags.BoolVar(&quiet, 0, "quiet", "equivalent to -f")
+	flags.BoolVar(&verbose, 'v', "verbose", "output a diagnostic for every file processed")
+	flags.BoolFunc(0, "dereference", "affect the referent of each symbolic link (default)", func() { noDereference = false })
+	flags.BoolVar(&noDereference, 'h', "no-dereference", "affect symbolic links instead of any referenced file")
+	flags.StringVar(&from, 0, "from", "", "change the ownership only if current owner/group matches `CURRENT_OWNER:CURRENT_GROUP`")
+	flags.BoolVar(&preserveRoot, 0, "preserve-root", "fail to operate recursively on '/'")
+	flags.BoolFunc(0, "no-preserve-root", "do not treat '/' specially (default)", func() { preserveRoot = false })
+	flags.StringVar(&reference, 0, "reference", "", "use `RFILE`'s ownership rather than explicit values")
+	flags.BoolVar(&recursive, 'R', "recursive", "operate on files and directories recursively")
+}
+
+func Main() {
+	// Determine if chgrp
+	if strings.TrimSuffix(cli.Name, ".exe") == "chgrp" {
+		chownMode = CHGRP
+	}
+
+	flags.Usage = usage
+	flags.Parse(os.Args[1:])
+
+	args := flags.Args()
+	if len(args) < 1 {
+		flags.UsageError("missing operand")
+	}
+
+	var uid, gid int = -1, -1
+	var requiredUid, requiredGid int = -1, -1
+	var userName, groupName string
+
+	if reference != "" {
+		var err error
+		uid, gid, err = getFileOwnership(reference)
+		if err != nil {
+			cli.Fatalf("failed to get attributes of '%s': %v", reference, err)
+		}
+		if chownMode == CHGRP {
+			uid = -1
+		} else {
+			userName = users.UserString(uid)
+		}
+		groupName = users.GroupString(gid)
+	} else {
+		ownerSpec := args[0]
+		if chownMode == CHGRP {
+			ownerSpec = ":" + ownerSpec
+		}
+
+		var err error
+		uid, gid, requiredUid, requiredGid, userName, groupName, err = parseOwnerSpec(ownerSpec)
+		if err != nil {
+			cli.Fatalf("%v", err)
+		}
+
+		args = args[1:]
+	}
+
+	if len(args) == 0 {
+		flags.UsageError("missing operand after '%s'", flags.Arg(0))
+	}
+
+	verbosity := V_normal
+	if verbose {
+		verbosity = V_high
+	} else if changes {
+		verbosity = V_changes_only
+	}
+
+	options := ChownOptions{
+		recurse:               recursive,
+		verbosity:             verbosity,
+		forceSilent:           quiet,
+		affectSymlinkReferent: !noDereference,
+		userName:              userName,
+		groupName:             groupName,
+		preserveRoot:          preserveRoot,
+	}
+
+	for _, file := range args {
+		if err := chownFile(file, uid, gid, requiredUid, requiredGid, &options); err != nil {
+			if options.forceSilent {
+				cli.Status = cli.ExitFailure
+			} else {
+				cli.Failf("%s: %v", file, err)
+			}
+		}
+	}
+
+	cli.Exit()
+}
+
+func usage() {
+	w := flags.Output()
+	if chownMode == CHGRP {
+		fmt.Fprintf(w, "Usage: %s [OPTION]... GROUP FILE...\n", cli.Name)
+	} else {
+		fmt.Fprintf(w, "Usage: %s [OPTION]... [OWNER][:[GROUP]] FILE...\n", cli.Name)
+	}
+	fmt.Fprintf(w, "  or:  %s [OPTION]... --reference=RFILE FILE...\n", cli.Name)
+	fmt.Fprintln(w, "\nOptions:")
+	flags.PrintDefaults()
+	if chownMode == CHGRP {
+		fmt.Fprintf(w, "\nExamples:\n  %s staff /u\n  %s -hR staff /u\n", cli.Name, cli.Name)
+	} else {
+		fmt.Fprintf(w, "\nExamples:\n  %s root /u\n  %s root:staff /u\n  %s -hR root /u\n",
+			cli.Name, cli.Name, cli.Name)
+	}
+}
+
+type ChownOptions struct {
+	recurse               bool
+	verbosity             Verbosity
+	forceSilent           bool
+	affectSymlinkReferent bool
+	userName              string
+	groupName             string
+	preserveRoot          bool
+}
+
+func getFileOwnership(filename string) (int, int, error) {
+	fileInfo, err := os.Stat(filename)
+	if err != nil {
+		return -1, -1, err
+	}
+	stat, ok := fileInfo.Sys().(*syscall.Stat_t)
+	if !ok {
+		return -1, -1, fmt.Errorf("could not get file stats")
+	}
+	return int(stat.Uid), int(stat.Gid), nil
+}
+
+func parseOwnerSpec(spec string) (uid, gid, requiredUid, requiredGid int, userName, groupName string, err error) {
+	uid, gid, requiredUid, requiredGid = -1, -1, -1, -1
+	if from != "" {
+		parts := strings.Split(from, ":")
+		if len(parts) > 0 && parts[0] != "" {
+			var name string
+			requiredUid, name, err = users.LookupUser(parts[0])
+			if err != nil {
+				return
+			}
+			_ = name // not needed for requiredUid
+		}
+		if len(parts) > 1 && parts[1] != "" {
+			var name string
+			requiredGid, name, err = users.LookupGroup(parts[1])
+			if err != nil {
+				return
+			}
+			_ = name // not needed for requiredGid
+		}
+	}
+
+	parts := strings.Split(spec, ":")
+	if len(parts) == 0 {
+		return -1, -1, -1, -1, "", "", fmt.Errorf("invalid owner specification")
+	}
+
+	if parts[0] != "" {
+		uid, userName, err = users.LookupUser(parts[0])
+		if err != nil {
+			return
+		}
+	}
+
+	if len(parts) > 1 && parts[1] != "" {
+		gid, groupName, err = users.LookupGroup(parts[1])
+		if err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+func chownFile(path string, uid, gid, requiredUid, requiredGid int, options *ChownOptions) error {
+	if options.recurse {
+		return filepath.Walk(path, func(filePath string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if options.preserveRoot && filePath == "/" && path != "/" {
+				return filepath.SkipDir
+			}
+
+			return changeOwnership(filePath, uid, gid, requiredUid, requiredGid, options)
+		})
+	}
+	return changeOwnership(path, uid, gid, requiredUid, requiredGid, options)
+}
+
+func changeOwnership(path string, uid, gid, requiredUid, requiredGid int, options *ChownOptions) error {
+	var fileInfo os.FileInfo
+	var err error
+
+	if options.affectSymlinkReferent {
+		fileInfo, err = os.Stat(path)
+	} else {
+		fileInfo, err = os.Lstat(path)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	stat, ok := fileInfo.Sys().(*syscall.Stat_t)
+	if !ok {
+		return fmt.Errorf("could not get file stats")
+	}
+
+	if requiredUid != -1 && int(stat.Uid) != requiredUid {
+		return nil
+	}
+	if requiredGid != -1 && int(stat.Gid) != requiredGid {
+		return nil
+	}
+
+	newUid := stat.Uid
+	if uid != -1 {
+		newUid = uint32(uid)
+	}
+
+	newGid := stat.Gid
+	if gid != -1 {
+		newGid = uint32(gid)
+	}
+
+	if newUid == stat.Uid && newGid == stat.Gid {
+		return nil
+	}
+
+	if options.affectSymlinkReferent {
+		err = os.Chown(path, int(newUid), int(newGid))
+	} else {
+		err = os.Lchown(path, int(newUid), int(newGid))
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if options.verbosity == V_high || (options.verbosity == V_changes_only && (newUid != stat.Uid || newGid != stat.Gid)) {
+		displayName := path
+		if !options.affectSymlinkReferent {
+			displayName = fmt.Sprintf("%s (symlink)", path)
+		}
+
+		changedOwner := options.userName
+		if changedOwner == "" {
+			changedOwner = users.UserString(int(newUid))
+		}
+
+		changedGroup := options.groupName
+		if changedGroup == "" {
+			changedGroup = users.GroupString(int(newGid))
+		}
+
+		fmt.Printf("changed ownership of '%s' from %s:%s to %s:%s\n",
+			displayName,
+			users.UserString(int(stat.Uid)), users.GroupString(int(stat.Gid)),
+			changedOwner, changedGroup)
+	}
+
+	return nil
+}
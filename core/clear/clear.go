@@ -0,0 +1,72 @@
+package clear
+
+import (
+    "fmt"
+    "os"
+    "strings"
+
+    "github.com/ilnarildarovuch/CoreUtils-On-GO/internal/cli"
+)
+
+const (
+	version = "1.0.0"
+)
+
+var (
+    termType     string
+    printVersion bool
+    noScrollback bool
+)
+
+// clear takes ncurses' options: -V rather than --version.
+var flags = cli.NewFlagSet("")
+
+func init() {
+    flags.StringVar(&termType, 'T', "", "", "use this `TERM` instead of $TERM")
+    flags.BoolVar(&printVersion, 'V', "", "print curses-version")
+    flags.BoolVar(&noScrollback, 'x', "", "do not try to clear scrollback")
+}
+
+func Main() {
+    flags.Usage = usage
+    flags.Parse(os.Args[1:])
+
+    if printVersion {
+        fmt.Println(version)
+        os.Exit(0)
+    }
+
+    if flags.NArg() > 0 {
+        flags.UsageError("extra operand '%s'", flags.Arg(0))
+    }
+
+    terminalType := termType
+    if terminalType == "" {
+        terminalType = os.Getenv("TERM")
+    }
+    if terminalType == "" {
+        terminalType = "xterm"
+    }
+
+    clearTerminal(terminalType, noScrollback)
+}
+
+func usage() {
+    w := flags.Output()
+    fmt.Fprintf(w, "Usage: %s [options]\n", cli.Name)
+    fmt.Fprintln(w, "\nClear the terminal screen.\n\nOptions:")
+    flags.PrintDefaults()
+}
+
+func clearTerminal(term string, noScrollback bool) {
+    switch strings.ToLower(term) {
+    case "xterm", "xterm-256color", "linux", "vt100":
+        fmt.Print("\033[H\033[2J")
+
+        if !noScrollback {
+            fmt.Print("\033[3J")
+        }
+    default:
+        cli.Fatalf("unsupported terminal type '%s'", term)
+    }
+}
\ No newline at end of file
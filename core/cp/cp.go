@@ -1,50 +1,49 @@
-package main
+package cp
 
 import (
-	"flag"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
 	"strings"
 	"syscall"
-)
 
-const (
-	version = "1.0.0"
+	"github.com/ilnarildarovuch/CoreUtils-On-GO/internal/cli"
 )
 
+const version = "1.0.0"
+
+var flags = cli.NewFlagSet(version)
+
 var (
-	archive           = flag.Bool("a", false, "same as -dR --preserve=all")
-	attributesOnly    = flag.Bool("attributes-only", false, "don't copy the file data, just the attributes")
-	backup            = flag.String("backup", "", "make a backup of each existing destination file")
-	noBackup          = flag.Bool("b", false, "like --backup but does not accept an argument")
-	copyContents      = flag.Bool("copy-contents", false, "copy contents of special files when recursive")
-	debug             = flag.Bool("debug", false, "explain how a file is copied. Implies -v")
-	force             = flag.Bool("f", false, "if an existing destination file cannot be opened, remove it and try again")
-	interactive       = flag.Bool("i", false, "prompt before overwrite (overrides a previous -n option)")
-	link              = flag.Bool("l", false, "hard link files instead of copying")
-	dereference       = flag.Bool("L", false, "always follow symbolic links in SOURCE")
-	noClobber         = flag.Bool("n", false, "silently skip existing files")
-	noDereference     = flag.Bool("P", false, "never follow symbolic links in SOURCE")
-	preserve          = flag.String("preserve", "", "preserve the specified attributes (mode,ownership,timestamps,links,context,xattr,all)")
-	noPreserve        = flag.String("no-preserve", "", "don't preserve the specified attributes")
-	parents           = flag.Bool("parents", false, "use full source file name under DIRECTORY")
-	recursive         = flag.Bool("r", false, "copy directories recursively")
-	recursiveLong     = flag.Bool("R", false, "equivalent to -r")
-	reflink           = flag.String("reflink", "auto", "control clone/CoW copies (auto, always, never)")
-	removeDestination = flag.Bool("remove-destination", false, "remove each existing destination file before attempting to open it")
-	sparse            = flag.String("sparse", "auto", "control creation of sparse files (auto, always, never)")
-	stripSlashes      = flag.Bool("strip-trailing-slashes", false, "remove any trailing slashes from each SOURCE argument")
-	symbolicLink      = flag.Bool("s", false, "make symbolic links instead of copying")
-	suffix            = flag.String("S", "", "override the usual backup suffix")
-	targetDirectory   = flag.String("t", "", "copy all SOURCE arguments into DIRECTORY")
-	noTargetDirectory = flag.Bool("T", false, "treat DEST as a normal file")
-	update            = flag.String("update", "", "control which existing files are updated (all,none,none-fail,older)")
-	verbose           = flag.Bool("v", false, "explain what is being done")
-	keepDirSymlink    = flag.Bool("keep-directory-symlink", false, "follow existing symlinks to directories")
-	oneFileSystem     = flag.Bool("x", false, "stay on this file system")
-	versionFlag       = flag.Bool("version", false, "output version information and exit")
+	archive           = flags.Bool('a', "archive", "same as -dR --preserve=all")
+	attributesOnly    = flags.Bool(0, "attributes-only", "don't copy the file data, just the attributes")
+	backup            = flags.StringOpt(0, "backup", "", "existing", "make a backup of each existing destination file, as `CONTROL` says")
+	noBackup          = flags.Bool('b', "", "like --backup but does not accept an argument")
+	copyContents      = flags.Bool(0, "copy-contents", "copy contents of special files when recursive")
+	debug             = flags.Bool(0, "debug", "explain how a file is copied. Implies -v")
+	force             = flags.Bool('f', "force", "if an existing destination file cannot be opened, remove it and try again")
+	interactive       = flags.Bool('i', "interactive", "prompt before overwrite (overrides a previous -n option)")
+	link              = flags.Bool('l', "link", "hard link files instead of copying")
+	dereference       = flags.Bool('L', "dereference", "always follow symbolic links in SOURCE")
+	noClobber         = flags.Bool('n', "no-clobber", "silently skip existing files")
+	noDereference     = flags.Bool('P', "no-dereference", "never follow symbolic links in SOURCE")
+	preserve          = flags.StringOpt(0, "preserve", "", "mode,ownership,timestamps", "preserve the attributes in `ATTR_LIST` (mode,ownership,timestamps,links,context,xattr,all)")
+	noPreserve        = flags.String(0, "no-preserve", "", "don't preserve the attributes in `ATTR_LIST`")
+	parents           = flags.Bool(0, "parents", "use full source file name under DIRECTORY")
+	recursive         = flags.Bool('r', "recursive", "copy directories recursively")
+	reflink           = flags.StringOpt(0, "reflink", "auto", "always", "control clone/CoW copies: `WHEN` is auto, always or never")
+	removeDestination = flags.Bool(0, "remove-destination", "remove each existing destination file before attempting to open it")
+	sparse            = flags.String(0, "sparse", "auto", "control creation of sparse files: `WHEN` is auto, always or never")
+	stripSlashes      = flags.Bool(0, "strip-trailing-slashes", "remove any trailing slashes from each SOURCE argument")
+	symbolicLink      = flags.Bool('s', "symbolic-link", "make symbolic links instead of copying")
+	suffix            = flags.String('S', "suffix", "", "override the usual backup `SUFFIX`")
+	targetDirectory   = flags.String('t', "target-directory", "", "copy all SOURCE arguments into `DIRECTORY`")
+	noTargetDirectory = flags.Bool('T', "no-target-directory", "treat DEST as a normal file")
+	update            = flags.StringOpt(0, "update", "", "older", "control which existing files are updated: `UPDATE` is all, none, none-fail or older")
+	verbose           = flags.Bool('v', "verbose", "explain what is being done")
+	keepDirSymlink    = flags.Bool(0, "keep-directory-symlink", "follow existing symlinks to directories")
+	oneFileSystem     = flags.Bool('x', "one-file-system", "stay on this file system")
 )
 
 type cpOptions struct {
@@ -77,57 +76,50 @@ type cpOptions struct {
 	oneFileSystem        bool
 }
 
-func main() {
-	flag.Usage = usage
-	flag.Parse()
+func init() {
+	flags.BoolVar(recursive, 'R', "", "equivalent to -r")
+	flags.BoolFunc('p', "", "same as --preserve=mode,ownership,timestamps", func() { *preserve = "mode,ownership,timestamps" })
+	flags.BoolFunc('u', "", "equivalent to --update=older", func() { *update = "older" })
+}
 
-	if *versionFlag {
-		printVersion()
-		os.Exit(0)
-	}
+func Main() {
+	flags.Usage = usage
+	flags.Parse(os.Args[1:])
 
 	options := parseOptions()
 
 	if *noTargetDirectory && *targetDirectory != "" {
-		fmt.Fprintf(os.Stderr, "cannot combine --target-directory (-t) and --no-target-directory (-T)\n")
-		os.Exit(1)
+		cli.Fatalf("cannot combine --target-directory (-t) and --no-target-directory (-T)")
 	}
 
 	if *link && *symbolicLink {
-		fmt.Fprintf(os.Stderr, "cannot make both hard and symbolic links\n")
-		os.Exit(1)
+		cli.Fatalf("cannot make both hard and symbolic links")
 	}
 
-	sources, dest := getSourceDest(flag.Args(), options)
+	sources, dest := getSourceDest(flags.Args(), options)
 
 	if err := validateArgs(sources, dest, options); err != nil {
-		fmt.Fprintf(os.Stderr, "%s\n", err)
-		os.Exit(1)
+		cli.Fatalf("%v", err)
 	}
 
 	if err := copyFiles(sources, dest, options); err != nil {
-		fmt.Fprintf(os.Stderr, "cp: %v\n", err)
-		os.Exit(1)
+		cli.Fatalf("%v", err)
 	}
 }
 
 func usage() {
-	fmt.Fprintf(os.Stderr, "Secure cp %s\n", version)
-	fmt.Fprintf(os.Stderr, "Usage: %s [OPTION]... [-T] SOURCE DEST\n", os.Args[0])
-	fmt.Fprintf(os.Stderr, "  or:  %s [OPTION]... SOURCE... DIRECTORY\n", os.Args[0])
-	fmt.Fprintf(os.Stderr, "  or:  %s [OPTION]... -t DIRECTORY SOURCE...\n", os.Args[0])
-	fmt.Fprintln(os.Stderr, "\nCopy SOURCE to DEST, or multiple SOURCE(s) to DIRECTORY.")
-	fmt.Fprintln(os.Stderr, "\nOptions:")
-	flag.PrintDefaults()
-	fmt.Fprintf(os.Stderr, "\nExamples:\n  %s file.txt copy.txt\n  %s -r dir1 dir2\n", os.Args[0], os.Args[0])
-}
-
-func printVersion() {
-	fmt.Printf("%s %s\n", os.Args[0], version)
+	w := flags.Output()
+	fmt.Fprintf(w, "Usage: %s [OPTION]... [-T] SOURCE DEST\n", cli.Name)
+	fmt.Fprintf(w, "  or:  %s [OPTION]... SOURCE... DIRECTORY\n", cli.Name)
+	fmt.Fprintf(w, "  or:  %s [OPTION]... -t DIRECTORY SOURCE...\n", cli.Name)
+	fmt.Fprintln(w, "\nCopy SOURCE to DEST, or multiple SOURCE(s) to DIRECTORY.")
+	fmt.Fprintln(w, "\nOptions:")
+	flags.PrintDefaults()
+	fmt.Fprintf(w, "\nExamples:\n  %s file.txt copy.txt\n  %s -r dir1 dir2\n", cli.Name, cli.Name)
 }
 
 func parseOptions() cpOptions {
-	recursive := *recursive || *recursiveLong
+	recursive := *recursive
 
 	if *archive {
 		*preserve = "all"
@@ -176,8 +168,7 @@ func parseOptions() cpOptions {
 func getSourceDest(args []string, options cpOptions) ([]string, string) {
 	if options.noTargetDirectory {
 		if len(args) != 2 {
-			fmt.Fprintf(os.Stderr, "missing destination file operand after %s\n", args[0])
-			os.Exit(1)
+			flags.UsageError("missing destination file operand after '%s'", args[0])
 		}
 		return []string{args[0]}, args[1]
 	}
@@ -187,8 +178,7 @@ func getSourceDest(args []string, options cpOptions) ([]string, string) {
 	}
 
 	if len(args) < 2 {
-		fmt.Fprintln(os.Stderr, "missing file operand")
-		os.Exit(1)
+		flags.UsageError("missing file operand")
 	}
 
 	dest := args[len(args)-1]
@@ -408,4 +398,4 @@ func contains(slice []string, item string) bool {
 		}
 	}
 	return false
-}
\ No newline at end of file
+}
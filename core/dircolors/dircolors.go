@@ -0,0 +1,222 @@
+package dircolors
+
+import (
+	"bufio"
+	_ "embed"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/ilnarildarovuch/CoreUtils-On-GO/internal/cli"
+)
+
+const version = "1.0.0"
+
+// database is the configuration used when no FILE is given, the one
+// --print-database shows.
+//
+//go:embed dircolors.hin
+var database string
+
+var flags = cli.NewFlagSet(version)
+
+var (
+	bourneShell   = flags.Bool('b', "sh", "output Bourne shell code to set LS_COLORS")
+	cShell        = flags.Bool('c', "csh", "output C shell code to set LS_COLORS")
+	printDatabase = flags.Bool('p', "print-database", "output defaults")
+	printLSColors = flags.Bool(0, "print-ls-colors", "output fully escaped colors for display")
+)
+
+// keywords maps the configuration keywords to LS_COLORS keys.
+var keywords = map[string]string{
+	"NORMAL": "no", "NORM": "no", "FILE": "fi", "RESET": "rs", "DIR": "di",
+	"LNK": "ln", "LINK": "ln", "SYMLINK": "ln", "ORPHAN": "or", "MISSING": "mi",
+	"FIFO": "pi", "PIPE": "pi", "SOCK": "so", "BLK": "bd", "BLOCK": "bd",
+	"CHR": "cd", "CHAR": "cd", "DOOR": "do", "EXEC": "ex",
+	"LEFT": "lc", "LEFTCODE": "lc", "RIGHT": "rc", "RIGHTCODE": "rc", "END": "ec", "ENDCODE": "ec",
+	"SUID": "su", "SETUID": "su", "SGID": "sg", "SETGID": "sg", "STICKY": "st",
+	"OTHER_WRITABLE": "ow", "OWR": "ow", "STICKY_OTHER_WRITABLE": "tw", "OWT": "tw",
+	"CAPABILITY": "ca", "MULTIHARDLINK": "mh", "CLRTOEOL": "cl",
+}
+
+func Main() {
+	flags.Usage = usage
+	flags.Parse(os.Args[1:])
+
+	if (*bourneShell || *cShell) && (*printDatabase || *printLSColors) {
+		flags.UsageError("the options to output non shell syntax,\nand to select a shell syntax are mutually exclusive")
+	}
+	if *printDatabase && *printLSColors {
+		flags.UsageError("options --print-database and --print-ls-colors are mutually exclusive")
+	}
+	if *printDatabase {
+		if flags.NArg() > 0 {
+			flags.UsageError("extra operand '%s'\nfile operands cannot be combined with --print-database (-p)", flags.Arg(0))
+		}
+		fmt.Print(database)
+		return
+	}
+	if flags.NArg() > 1 {
+		flags.UsageError("extra operand '%s'", flags.Arg(1))
+	}
+
+	csh := *cShell
+	if !*bourneShell && !*cShell && !*printLSColors {
+		shell := os.Getenv("SHELL")
+		if shell == "" {
+			cli.Fatalf("no SHELL environment variable, and no shell type option given")
+		}
+		csh = strings.HasSuffix(filepath.Base(shell), "csh")
+	}
+
+	name, r := "<internal>", io.Reader(strings.NewReader(database))
+	if flags.NArg() == 1 {
+		name = flags.Arg(0)
+		if name == "-" {
+			r = os.Stdin
+		} else {
+			f, err := os.Open(name)
+			if err != nil {
+				cli.Fatalf("%s: %v", name, err)
+			}
+			defer f.Close()
+			r = f
+		}
+	}
+
+	entries := parse(r, name)
+
+	switch {
+	case *printLSColors:
+		for _, e := range entries {
+			fmt.Printf("\033[%sm%s\t%s\033[0m\n", e.value, e.key, e.value)
+		}
+	case csh:
+		fmt.Printf("setenv LS_COLORS '%s'\n", join(entries))
+	default:
+		fmt.Printf("LS_COLORS='%s';\nexport LS_COLORS\n", join(entries))
+	}
+	cli.Exit()
+}
+
+func usage() {
+	w := flags.Output()
+	fmt.Fprintf(w, "Usage: %s [OPTION]... [FILE]\n", cli.Name)
+	fmt.Fprintln(w, "Output commands to set the LS_COLORS environment variable.")
+	fmt.Fprintln(w, "\nDetermine format of output:")
+	flags.PrintDefaults()
+	fmt.Fprintln(w, "\nIf FILE is specified, read it to determine which colors to use for which")
+	fmt.Fprintln(w, "file types and extensions.  Otherwise, a precompiled database is used.")
+	fmt.Fprintf(w, "For details on the format of these files, run '%s --print-database'.\n", cli.Name)
+}
+
+type entry struct {
+	key   string
+	value string
+}
+
+// parse reads a dircolors configuration. Lines are KEYWORD VALUE, .EXT
+// VALUE or *SUFFIX VALUE; TERM and COLORTERM lines, which may be glob
+// patterns, make the lines after them apply only to matching terminals.
+// Errors are reported and the line skipped.
+func parse(r io.Reader, name string) []entry {
+	termName := os.Getenv("TERM")
+	if termName == "" {
+		termName = "none"
+	}
+	colorTerm := os.Getenv("COLORTERM")
+
+	const (
+		global   = iota // no TERM lines yet
+		termNo          // after TERM lines that did not match
+		termSure        // in TERM lines, one of which matched
+		termYes         // after TERM lines one of which matched
+	)
+	state := global
+
+	var entries []entry
+	sc := bufio.NewScanner(r)
+	for lineno := 1; sc.Scan(); lineno++ {
+		keyword, arg, ok := parseLine(sc.Text())
+		if keyword == "" {
+			continue
+		}
+		if !ok {
+			cli.Failf("%s:%d: invalid line;  missing second token", name, lineno)
+			continue
+		}
+
+		switch strings.ToUpper(keyword) {
+		case "TERM":
+			if state != termSure {
+				state = termNo
+				if matched, _ := path.Match(arg, termName); matched {
+					state = termSure
+				}
+			}
+			continue
+		case "COLORTERM":
+			if state != termSure {
+				state = termNo
+				if matched, _ := path.Match(arg, colorTerm); matched {
+					state = termSure
+				}
+			}
+			continue
+		}
+		if state == termSure {
+			state = termYes
+		}
+		if state == termNo {
+			continue
+		}
+
+		switch upper := strings.ToUpper(keyword); {
+		case keyword[0] == '.':
+			entries = append(entries, entry{"*" + keyword, arg})
+		case keyword[0] == '*':
+			entries = append(entries, entry{keyword, arg})
+		case upper == "OPTIONS" || upper == "COLOR" || upper == "EIGHTBIT":
+			// Slackware's keywords, ignored.
+		case keywords[upper] != "":
+			entries = append(entries, entry{keywords[upper], arg})
+		default:
+			cli.Failf("%s:%d: unrecognized keyword %s", name, lineno, keyword)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		cli.Fatalf("%s: %v", name, err)
+	}
+	return entries
+}
+
+// parseLine splits a line into its keyword and argument. The argument
+// ends at a #, which starts a comment; so does one at the start of the
+// line.
+func parseLine(line string) (keyword, arg string, ok bool) {
+	line = strings.TrimLeft(line, " \t")
+	if line == "" || line[0] == '#' {
+		return "", "", false
+	}
+	keyword, rest := line, ""
+	if i := strings.IndexAny(line, " \t"); i >= 0 {
+		keyword, rest = line[:i], strings.TrimLeft(line[i:], " \t")
+	}
+	if i := strings.IndexByte(rest, '#'); i >= 0 {
+		rest = rest[:i]
+	}
+	arg = strings.TrimRight(rest, " \t")
+	return keyword, arg, arg != ""
+}
+
+// join makes the LS_COLORS value, quoted for the shell's single quotes.
+func join(entries []entry) string {
+	var b strings.Builder
+	for _, e := range entries {
+		b.WriteString(e.key + "=" + e.value + ":")
+	}
+	return strings.ReplaceAll(b.String(), "'", `'\''`)
+}
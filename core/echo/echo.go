@@ -1,50 +1,76 @@
-package main
+package echo
 
 import (
-	"flag"
 	"fmt"
 	"os"
 	"strings"
+
+	"github.com/ilnarildarovuch/CoreUtils-On-GO/internal/cli"
 )
 
 const (
-	version    = "1.0.0"
+	version = "1.0.0"
 )
 
 var (
-	noNewline      = flag.Bool("n", false, "do not output the trailing newline")
-	enableEscape   = flag.Bool("e", false, "enable interpretation of backslash escapes")
-	disableEscape  = flag.Bool("E", false, "disable interpretation of backslash escapes")
-	showHelp       = flag.Bool("help", false, "display this help and exit")
-	showVersion    = flag.Bool("version", false, "output version information and exit")
+	noNewline     bool
+	enableEscape  bool
+	disableEscape bool
 )
 
-func main() {
-	flag.Usage = usage
-	flag.Parse()
-
-	if *showVersion {
-		printVersion()
-		os.Exit(0)
+func Main() {
+	args := os.Args[1:]
+
+	// Like GNU echo, --help and --version count only as the sole argument,
+	// and leading arguments are options only when made of n, e and E.
+	if len(args) == 1 {
+		switch args[0] {
+		case "--help":
+			usage()
+			os.Exit(cli.ExitSuccess)
+		case "--version":
+			cli.PrintVersion(version)
+			os.Exit(cli.ExitSuccess)
+		}
 	}
 
-	if *showHelp {
-		usage()
-		os.Exit(0)
+	for len(args) > 0 && isOptionArg(args[0]) {
+		for _, c := range args[0][1:] {
+			switch c {
+			case 'n':
+				noNewline = true
+			case 'e':
+				enableEscape, disableEscape = true, false
+			case 'E':
+				enableEscape, disableEscape = false, true
+			}
+		}
+		args = args[1:]
 	}
 
-	args := flag.Args()
 	processEcho(args)
 }
 
+// isOptionArg reports whether arg is an option cluster such as "-ne".
+func isOptionArg(arg string) bool {
+	if len(arg) < 2 || arg[0] != '-' {
+		return false
+	}
+	return strings.Trim(arg[1:], "neE") == ""
+}
+
 func usage() {
 	fmt.Printf(`Usage: %s [SHORT-OPTION]... [STRING]...
   or:  %s LONG-OPTION
 Echo the STRING(s) to standard output.
 
 Options:
-`, os.Args[0], os.Args[0])
-	flag.PrintDefaults()
+  -n             do not output the trailing newline
+  -e             enable interpretation of backslash escapes
+  -E             disable interpretation of backslash escapes
+      --help     display this help and exit
+      --version  output version information and exit
+`, cli.Name, cli.Name)
 	fmt.Printf(`
 If -e is in effect, the following sequences are recognized:
 
@@ -66,12 +92,8 @@ as it avoids problems when outputting option-like strings.
 `)
 }
 
-func printVersion() {
-	fmt.Printf("%s %s\n", os.Args[0], version)
-}
-
 func processEcho(args []string) {
-	processEscapes := *enableEscape || (!*disableEscape && os.Getenv("POSIXLY_CORRECT") == "")
+	processEscapes := enableEscape || (!disableEscape && os.Getenv("POSIXLY_CORRECT") == "")
 
 	var output strings.Builder
 	first := true
@@ -89,7 +111,7 @@ func processEcho(args []string) {
 		}
 	}
 
-	if !*noNewline {
+	if !noNewline {
 		output.WriteString("\n")
 	}
 
@@ -200,4 +222,4 @@ func hexToByte(c byte) byte {
 	default:
 		return 0
 	}
-}
\ No newline at end of file
+}
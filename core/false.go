@@ -1,7 +0,0 @@
-package main
-
-import "os"
-
-func main() {
-	os.Exit(1)
-}
\ No newline at end of file
@@ -0,0 +1,7 @@
+package false
+
+import "os"
+
+func Main() {
+	os.Exit(1)
+}
\ No newline at end of file
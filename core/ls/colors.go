@@ -0,0 +1,276 @@
+package ls
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// colors maps the two-letter LS_COLORS keys to SGR parameters; these are
+// the defaults GNU ls uses when LS_COLORS leaves a key out.
+var colors = map[string]string{
+	"lc": "\033[",
+	"rc": "m",
+	"ec": "",
+	"rs": "0",
+	"no": "",
+	"fi": "",
+	"di": "01;34",
+	"ln": "01;36",
+	"pi": "33",
+	"so": "01;35",
+	"bd": "01;33",
+	"cd": "01;33",
+	"mi": "",
+	"or": "",
+	"ex": "01;32",
+	"do": "01;35",
+	"su": "37;41",
+	"sg": "30;43",
+	"st": "37;44",
+	"ow": "34;42",
+	"tw": "30;42",
+	"ca": "",
+	"mh": "",
+	"cl": "\033[K",
+}
+
+// extColors are the *SUFFIX entries of LS_COLORS in order; later ones win.
+var extColors []extColor
+
+type extColor struct {
+	suffix string
+	color  string
+}
+
+// parseLSColors reads an LS_COLORS value: KEY=VALUE or *SUFFIX=VALUE
+// entries separated by colons.
+func parseLSColors(s string) error {
+	for _, entry := range strings.Split(s, ":") {
+		if entry == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(entry, "=")
+		if !ok {
+			return errors.New("unparsable value for LS_COLORS environment variable")
+		}
+		value, err := unescape(value)
+		if err != nil {
+			return errors.New("unparsable value for LS_COLORS environment variable")
+		}
+		if strings.HasPrefix(key, "*") {
+			suffix, err := unescape(key[1:])
+			if err != nil {
+				return errors.New("unparsable value for LS_COLORS environment variable")
+			}
+			extColors = append(extColors, extColor{suffix, value})
+			continue
+		}
+		if _, ok := colors[key]; !ok {
+			return errors.New("unrecognized prefix: " + key)
+		}
+		colors[key] = value
+	}
+	return nil
+}
+
+// unescape decodes the escapes dircolors allows in values: backslash
+// escapes as in C, \e for escape, \_ for a space, and ^X for control
+// characters.
+func unescape(s string) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '^':
+			i++
+			if i == len(s) {
+				return "", errors.New("trailing ^")
+			}
+			switch c := s[i]; {
+			case c == '?':
+				b.WriteByte(0x7f)
+			case c >= '@' && c <= '~':
+				b.WriteByte(c & 0x1f)
+			default:
+				return "", errors.New("bad ^ escape")
+			}
+		case c == '\\':
+			i++
+			if i == len(s) {
+				return "", errors.New("trailing backslash")
+			}
+			c = s[i]
+			switch {
+			case c >= '0' && c <= '7':
+				n := 0
+				j := i
+				for ; j < len(s) && j < i+3 && s[j] >= '0' && s[j] <= '7'; j++ {
+					n = n*8 + int(s[j]-'0')
+				}
+				b.WriteByte(byte(n))
+				i = j - 1
+			case c == 'x' || c == 'X':
+				j := i + 1
+				for j < len(s) && j < i+3 && strings.IndexByte("0123456789abcdefABCDEF", s[j]) >= 0 {
+					j++
+				}
+				n, _ := strconv.ParseUint(s[i+1:j], 16, 8)
+				b.WriteByte(byte(n))
+				i = j - 1
+			default:
+				if e, ok := map[byte]byte{'a': 7, 'b': 8, 'e': 27, 'f': 12, 'n': 10, 'r': 13, 't': 9, 'v': 11, '?': 0x7f, '_': ' '}[c]; ok {
+					b.WriteByte(e)
+				} else {
+					b.WriteByte(c)
+				}
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String(), nil
+}
+
+// nameColor returns the SGR parameters for the name of file, or "" to
+// leave it uncoloured.
+func nameColor(file FileInfo) string {
+	if file.Mode()&os.ModeSymlink != 0 {
+		switch {
+		case file.Target == nil && colors["or"] != "":
+			return colors["or"]
+		case colors["ln"] == "target" && file.Target != nil:
+			return modeColor(file.Target, file.Path)
+		case colors["ln"] == "target":
+			return ""
+		}
+		return colors["ln"]
+	}
+	return modeColor(file.FileInfo, file.Path)
+}
+
+// targetColor returns the colour of the target a symbolic link points to
+// in the long format.
+func targetColor(file FileInfo) string {
+	if file.Target == nil {
+		// mi=00, as dircolors sets it, leaves the target to or.
+		if mi := colors["mi"]; mi != "" && mi != "0" && mi != "00" {
+			return mi
+		}
+		return colors["or"]
+	}
+	return modeColor(file.Target, file.LinkTarget)
+}
+
+func modeColor(info fs.FileInfo, name string) string {
+	m := info.Mode()
+	switch {
+	case m.IsRegular():
+		var nlink uint64 = 1
+		if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+			nlink = uint64(stat.Nlink)
+		}
+		switch {
+		case m&os.ModeSetuid != 0 && colors["su"] != "":
+			return colors["su"]
+		case m&os.ModeSetgid != 0 && colors["sg"] != "":
+			return colors["sg"]
+		case m&0111 != 0 && colors["ex"] != "":
+			return colors["ex"]
+		case nlink > 1 && colors["mh"] != "":
+			return colors["mh"]
+		}
+		if color, ok := suffixColor(name); ok {
+			return color
+		}
+		return colors["fi"]
+	case m.IsDir():
+		otherWritable := m&0002 != 0
+		sticky := m&os.ModeSticky != 0
+		switch {
+		case sticky && otherWritable && colors["tw"] != "":
+			return colors["tw"]
+		case otherWritable && colors["ow"] != "":
+			return colors["ow"]
+		case sticky && colors["st"] != "":
+			return colors["st"]
+		}
+		return colors["di"]
+	case m&os.ModeSymlink != 0:
+		return colors["ln"]
+	case m&os.ModeNamedPipe != 0:
+		return colors["pi"]
+	case m&os.ModeSocket != 0:
+		return colors["so"]
+	case m&os.ModeCharDevice != 0:
+		return colors["cd"]
+	case m&os.ModeDevice != 0:
+		return colors["bd"]
+	}
+	return colors["or"]
+}
+
+// suffixColor finds the last *SUFFIX entry name ends with, preferring an
+// exact match to one that differs in case.
+func suffixColor(name string) (string, bool) {
+	for i := len(extColors) - 1; i >= 0; i-- {
+		if strings.HasSuffix(name, extColors[i].suffix) {
+			return extColors[i].color, true
+		}
+	}
+	lower := strings.ToLower(name)
+	for i := len(extColors) - 1; i >= 0; i-- {
+		if strings.HasSuffix(lower, strings.ToLower(extColors[i].suffix)) {
+			return extColors[i].color, true
+		}
+	}
+	return "", false
+}
+
+// colorize wraps s in the escape sequences for color.
+func colorize(s, color string) string {
+	if color == "" {
+		return s
+	}
+	end := colors["ec"]
+	if end == "" {
+		end = colors["lc"] + colors["rs"] + colors["rc"]
+	}
+	return colors["lc"] + color + colors["rc"] + s + end
+}
+
+// Indicator styles, from none to all of -F.
+const (
+	indicatorNone = iota
+	indicatorSlash
+	indicatorFileType
+	indicatorClassify
+)
+
+var indicatorStyle = indicatorNone
+
+// indicator returns the character appended to a name of mode m: / for
+// directories, @ for symbolic links, | for FIFOs, = for sockets and, with
+// -F, * for executables.
+func indicator(m fs.FileMode) string {
+	switch {
+	case indicatorStyle == indicatorNone:
+		return ""
+	case m.IsDir():
+		return "/"
+	case indicatorStyle == indicatorSlash:
+		return ""
+	case m&os.ModeSymlink != 0:
+		return "@"
+	case m&os.ModeNamedPipe != 0:
+		return "|"
+	case m&os.ModeSocket != 0:
+		return "="
+	case m.IsRegular() && m&0111 != 0 && indicatorStyle == indicatorClassify:
+		return "*"
+	}
+	return ""
+}
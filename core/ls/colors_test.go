@@ -0,0 +1,131 @@
+package ls
+
+import (
+	"io/fs"
+	"maps"
+	"testing"
+)
+
+// saveColors restores the colours and the *SUFFIX entries when the test
+// ends.
+func saveColors(t *testing.T) {
+	saved, savedExt := maps.Clone(colors), extColors
+	t.Cleanup(func() { colors, extColors = saved, savedExt })
+}
+
+func TestUnescape(t *testing.T) {
+	tests := []struct {
+		in, want string
+		ok       bool
+	}{
+		{"01;34", "01;34", true},
+		{`\e[`, "\033[", true},
+		{`\033[K`, "\033[K", true},
+		{`\x1b\x41z`, "\033Az", true},
+		{`\0`, "\000", true},
+		{`\1234`, "S4", true},
+		{`a\_b`, "a b", true},
+		{`\a\b\f\n\r\t\v\?`, "\a\b\f\n\r\t\v\x7f", true},
+		{`\:`, ":", true},
+		{"^[[0m", "\033[0m", true},
+		{"^?^@^a", "\x7f\x00\x01", true},
+		{"^", "", false},
+		{"^1", "", false},
+		{`\`, "", false},
+	}
+	for _, tt := range tests {
+		got, err := unescape(tt.in)
+		if got != tt.want || (err == nil) != tt.ok {
+			t.Errorf("unescape(%q) = %q, %v, want %q", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestParseLSColors(t *testing.T) {
+	saveColors(t)
+
+	if err := parseLSColors("di=01;33:ln=target::*.tar=01;31:*.TAR=32:*.gz=33:*.tar=35"); err != nil {
+		t.Fatal(err)
+	}
+	if colors["di"] != "01;33" || colors["ln"] != "target" || colors["ex"] != "01;32" {
+		t.Errorf("di=%q ln=%q ex=%q", colors["di"], colors["ln"], colors["ex"])
+	}
+	tests := []struct {
+		name, want string
+		ok         bool
+	}{
+		// The last entry wins, and an exact match one differing in case.
+		{"a.tar", "35", true},
+		{"a.TAR", "32", true},
+		{"a.Tar", "35", true},
+		{"a.tar.gz", "33", true},
+		{"tar", "", false},
+	}
+	for _, tt := range tests {
+		if got, ok := suffixColor(tt.name); got != tt.want || ok != tt.ok {
+			t.Errorf("suffixColor(%q) = %q, %v, want %q, %v", tt.name, got, ok, tt.want, tt.ok)
+		}
+	}
+
+	for _, bad := range []string{"di", "xx=1", "di=^", `*.c\=1`} {
+		if err := parseLSColors(bad); err == nil {
+			t.Errorf("parseLSColors(%q) accepted", bad)
+		}
+	}
+}
+
+func TestModeColor(t *testing.T) {
+	saveColors(t)
+	extColors = []extColor{{".tar", "01;31"}}
+
+	tests := []struct {
+		name string
+		mode fs.FileMode
+		want string
+	}{
+		{"f", 0644, ""},
+		{"a.tar", 0644, "01;31"},
+		{"a.tar", 0755, "01;32"},
+		{"su", fs.ModeSetuid | 0755, "37;41"},
+		{"sg", fs.ModeSetgid | 0755, "30;43"},
+		{"d", fs.ModeDir | 0755, "01;34"},
+		{"tmp", fs.ModeDir | fs.ModeSticky | 0777, "30;42"},
+		{"ow", fs.ModeDir | 0777, "34;42"},
+		{"st", fs.ModeDir | fs.ModeSticky | 0755, "37;44"},
+		{"l", fs.ModeSymlink | 0777, "01;36"},
+		{"p", fs.ModeNamedPipe | 0644, "33"},
+		{"s", fs.ModeSocket | 0755, "01;35"},
+		{"c", fs.ModeDevice | fs.ModeCharDevice | 0644, "01;33"},
+		{"b", fs.ModeDevice | 0644, "01;33"},
+	}
+	for _, tt := range tests {
+		if got := modeColor(testInfo{name: tt.name, mode: tt.mode}, tt.name); got != tt.want {
+			t.Errorf("modeColor(%s, %v) = %q, want %q", tt.name, tt.mode, got, tt.want)
+		}
+	}
+}
+
+func TestIndicator(t *testing.T) {
+	defer func(saved int) { indicatorStyle = saved }(indicatorStyle)
+
+	modes := []fs.FileMode{fs.ModeDir | 0755, fs.ModeSymlink | 0777, fs.ModeNamedPipe, fs.ModeSocket, 0755, 0644}
+	tests := []struct {
+		style int
+		want  string
+	}{
+		{indicatorNone, ""},
+		{indicatorSlash, "/"},
+		{indicatorFileType, "/@|="},
+		{indicatorClassify, "/@|=*"},
+	}
+	for _, tt := range tests {
+		indicatorStyle = tt.style
+		got := ""
+		for _, m := range modes {
+			got += indicator(m)
+		}
+		if got != tt.want {
+			t.Errorf("indicator style %d: %q, want %q", tt.style, got, tt.want)
+		}
+	}
+}
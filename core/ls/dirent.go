@@ -0,0 +1,72 @@
+package ls
+
+import (
+	"errors"
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// os.File.ReadDir and syscall.ParseDirent both drop . and .., which -f and
+// -U -a must list where the directory stream has them, so ls reads the
+// linux_dirent64 records itself.
+
+const (
+	direntIno    = int(unsafe.Offsetof(syscall.Dirent{}.Ino))
+	direntReclen = int(unsafe.Offsetof(syscall.Dirent{}.Reclen))
+	direntName   = int(unsafe.Offsetof(syscall.Dirent{}.Name))
+)
+
+// errBadDirent is returned for a record that does not fit in what
+// getdents64 returned.
+var errBadDirent = errors.New("invalid directory entry")
+
+// readDirNames returns the names in dir, . and .. included, in directory
+// order. On an error it returns the names read so far.
+func readDirNames(dir *os.File) ([]string, error) {
+	var names []string
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := syscall.ReadDirent(int(dir.Fd()), buf)
+		if err == syscall.EINTR {
+			continue
+		}
+		if err == nil && n > 0 {
+			names, err = parseDirents(buf[:n], names)
+		}
+		if err != nil {
+			return names, &os.PathError{Op: "readdirent", Path: dir.Name(), Err: err}
+		}
+		if n <= 0 {
+			return names, nil
+		}
+	}
+}
+
+// parseDirents appends the names of the records in buf to names. Records
+// with a zero inode number stand for deleted entries and are skipped.
+func parseDirents(buf []byte, names []string) ([]string, error) {
+	for off := 0; off < len(buf); {
+		if off+direntName > len(buf) {
+			return names, errBadDirent
+		}
+		reclen := int(*(*uint16)(unsafe.Pointer(&buf[off+direntReclen])))
+		if reclen < direntName || off+reclen > len(buf) {
+			return names, errBadDirent
+		}
+		if *(*uint64)(unsafe.Pointer(&buf[off+direntIno])) == 0 {
+			off += reclen
+			continue
+		}
+		name := buf[off+direntName : off+reclen]
+		for i, c := range name {
+			if c == 0 {
+				name = name[:i]
+				break
+			}
+		}
+		names = append(names, string(name))
+		off += reclen
+	}
+	return names, nil
+}
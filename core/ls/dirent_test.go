@@ -0,0 +1,81 @@
+package ls
+
+import (
+	"encoding/binary"
+	"os"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// dirent encodes a linux_dirent64 record, padded to 8 bytes as the
+// kernel pads them.
+func dirent(ino uint64, name string) []byte {
+	reclen := (direntName + len(name) + 1 + 7) &^ 7
+	rec := make([]byte, reclen)
+	binary.NativeEndian.PutUint64(rec[direntIno:], ino)
+	binary.NativeEndian.PutUint16(rec[direntReclen:], uint16(reclen))
+	copy(rec[direntName:], name)
+	return rec
+}
+
+func concat(recs ...[]byte) []byte {
+	var buf []byte
+	for _, rec := range recs {
+		buf = append(buf, rec...)
+	}
+	return buf
+}
+
+func TestParseDirents(t *testing.T) {
+	zeroReclen := dirent(7, "x")
+	binary.NativeEndian.PutUint16(zeroReclen[direntReclen:], 0)
+	longReclen := dirent(7, "x")
+	binary.NativeEndian.PutUint16(longReclen[direntReclen:], uint16(len(longReclen)+8))
+
+	tests := []struct {
+		buf     []byte
+		want    []string
+		wantErr bool
+	}{
+		{nil, nil, false},
+		{concat(dirent(1, "."), dirent(2, ".."), dirent(3, "a long file name")),
+			[]string{".", "..", "a long file name"}, false},
+		// Deleted entries have inode number 0.
+		{concat(dirent(1, "a"), dirent(0, "gone"), dirent(3, "b")), []string{"a", "b"}, false},
+		// Records must fit in what getdents64 returned; the names before
+		// a bad one are still returned.
+		{concat(dirent(1, "a"), zeroReclen), []string{"a"}, true},
+		{concat(dirent(1, "a"), longReclen), []string{"a"}, true},
+		{concat(dirent(1, "a"), dirent(2, "b")[:direntName-1]), []string{"a"}, true},
+	}
+	for _, tt := range tests {
+		got, err := parseDirents(tt.buf, nil)
+		if !reflect.DeepEqual(got, tt.want) || (err != nil) != tt.wantErr {
+			t.Errorf("parseDirents(%q) = %q, %v", tt.buf, got, err)
+		}
+	}
+}
+
+func TestReadDirNames(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a", "b", ".hidden"} {
+		if err := os.WriteFile(dir+"/"+name, nil, 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Remove(dir + "/b"); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	names, err := readDirNames(f)
+	sort.Strings(names)
+	if want := []string{".", "..", ".hidden", "a"}; err != nil || !reflect.DeepEqual(names, want) {
+		t.Errorf("readDirNames = %q, %v, want %q", names, err, want)
+	}
+}
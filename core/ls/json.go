@@ -0,0 +1,239 @@
+package ls
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ilnarildarovuch/CoreUtils-On-GO/internal/cli"
+	"github.com/ilnarildarovuch/CoreUtils-On-GO/internal/mode"
+	"github.com/ilnarildarovuch/CoreUtils-On-GO/internal/users"
+)
+
+// record describes a file in the JSON formats. Times are in RFC 3339 with
+// nanoseconds; blocks are 512 bytes whatever the block size. Names that
+// are not UTF-8 have their invalid bytes replaced by U+FFFD, and their
+// exact bytes in base64 in path_bytes, name_bytes or target_bytes.
+type record struct {
+	Path         string    `json:"path"`
+	PathBytes    []byte    `json:"path_bytes,omitempty"`
+	Name         string    `json:"name"`
+	NameBytes    []byte    `json:"name_bytes,omitempty"`
+	Type         string    `json:"type"`
+	Mode         string    `json:"mode"`
+	ModeString   string    `json:"mode_string"`
+	Size         int64     `json:"size"`
+	Blocks       int64     `json:"blocks"`
+	Nlink        uint64    `json:"nlink"`
+	UID          uint32    `json:"uid"`
+	GID          uint32    `json:"gid"`
+	User         string    `json:"user,omitempty"`
+	Group        string    `json:"group,omitempty"`
+	Inode        uint64    `json:"inode"`
+	Dev          uint64    `json:"dev"`
+	Major        *uint32   `json:"major,omitempty"`
+	Minor        *uint32   `json:"minor,omitempty"`
+	Atime        string    `json:"atime"`
+	Mtime        string    `json:"mtime"`
+	Ctime        string    `json:"ctime"`
+	Birthtime    string    `json:"birthtime,omitempty"`
+	Target       *string   `json:"target,omitempty"`
+	TargetBytes  []byte    `json:"target_bytes,omitempty"`
+	TargetExists *bool     `json:"target_exists,omitempty"`
+	Entries      *[]record `json:"entries,omitempty"`
+}
+
+// newRecord describes file, found at path.
+func newRecord(file FileInfo, path, name string) record {
+	m := file.Mode()
+	r := record{
+		Path:       path,
+		PathBytes:  invalidUTF8(path),
+		Name:       name,
+		NameBytes:  invalidUTF8(name),
+		Type:       fileTypeName(m),
+		Mode:       fmt.Sprintf("%04o", unixPerm(m)),
+		ModeString: mode.String(m),
+		Size:       file.Size(),
+		Mtime:      file.ModTime().Format(time.RFC3339Nano),
+	}
+	if m&os.ModeSymlink != 0 {
+		target := file.LinkTarget
+		exists := file.Target != nil
+		r.Target, r.TargetExists = &target, &exists
+		r.TargetBytes = invalidUTF8(target)
+	}
+	if t, ok := birthTime(path, m&os.ModeSymlink == 0); ok {
+		r.Birthtime = t.Format(time.RFC3339Nano)
+	}
+
+	stat, ok := file.Sys().(*syscall.Stat_t)
+	if !ok {
+		return r
+	}
+	r.Blocks = int64(stat.Blocks)
+	r.Nlink = uint64(stat.Nlink)
+	r.UID, r.GID = stat.Uid, stat.Gid
+	r.User, _ = users.UserName(int(stat.Uid))
+	r.Group, _ = users.GroupName(int(stat.Gid))
+	r.Inode = stat.Ino
+	r.Dev = uint64(stat.Dev)
+	if m&os.ModeDevice != 0 {
+		major, minor := major(uint64(stat.Rdev)), minor(uint64(stat.Rdev))
+		r.Major, r.Minor = &major, &minor
+	}
+	r.Atime = time.Unix(stat.Atim.Unix()).Format(time.RFC3339Nano)
+	r.Ctime = time.Unix(stat.Ctim.Unix()).Format(time.RFC3339Nano)
+	return r
+}
+
+// invalidUTF8 returns the bytes of s if it is not UTF-8, for the *_bytes
+// fields, and nil otherwise.
+func invalidUTF8(s string) []byte {
+	if utf8.ValidString(s) {
+		return nil
+	}
+	return []byte(s)
+}
+
+func fileTypeName(m fs.FileMode) string {
+	switch {
+	case m.IsRegular():
+		return "file"
+	case m.IsDir():
+		return "directory"
+	case m&os.ModeSymlink != 0:
+		return "symlink"
+	case m&os.ModeNamedPipe != 0:
+		return "fifo"
+	case m&os.ModeSocket != 0:
+		return "socket"
+	case m&os.ModeCharDevice != 0:
+		return "char-device"
+	case m&os.ModeDevice != 0:
+		return "block-device"
+	}
+	return "unknown"
+}
+
+// unixPerm returns the permission bits of m with set-user-ID, set-group-ID
+// and sticky where chmod puts them.
+func unixPerm(m fs.FileMode) uint32 {
+	perm := uint32(m.Perm())
+	if m&os.ModeSetuid != 0 {
+		perm |= 04000
+	}
+	if m&os.ModeSetgid != 0 {
+		perm |= 02000
+	}
+	if m&os.ModeSticky != 0 {
+		perm |= 01000
+	}
+	return perm
+}
+
+// printJSON prints the files and directories named on the command line as
+// one JSON array. A directory listed by its contents has them in
+// "entries", and with -R so do the directories among them.
+func printJSON(files, dirs []FileInfo) {
+	records := make([]record, 0, len(files)+len(dirs))
+	for _, file := range files {
+		records = append(records, newRecord(file, file.Path, filepath.Base(file.Path)))
+	}
+	for _, dir := range dirs {
+		r := newRecord(dir, dir.Path, filepath.Base(dir.Path))
+		r.Entries = directoryRecords(dir.Path, true)
+		records = append(records, r)
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(records); err != nil {
+		cli.Fatalf("%v", err)
+	}
+}
+
+// directoryRecords describes the entries of the directory at path, or
+// returns nil if it cannot be read.
+func directoryRecords(path string, operand bool) *[]record {
+	if *recursive {
+		leave, ok := enterDirectory(path)
+		if !ok {
+			return nil
+		}
+		defer leave()
+	}
+	files, ok := readDirectory(path, operand)
+	if !ok {
+		return nil
+	}
+	records := make([]record, 0, len(files))
+	for _, file := range files {
+		r := newRecord(file, entryPath(path, file.Path), file.Path)
+		if *recursive && file.IsDir() && !isDotOrDotDot(file.Path) {
+			r.Entries = directoryRecords(r.Path, false)
+		}
+		records = append(records, r)
+	}
+	return &records
+}
+
+// printNDJSON prints one JSON record per line: the files named on the
+// command line, then each directory followed by its entries, and with -R
+// by those of the directories among them.
+func printNDJSON(files, dirs []FileInfo) {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetEscapeHTML(false)
+	for _, file := range files {
+		encodeRecord(enc, newRecord(file, file.Path, filepath.Base(file.Path)))
+	}
+	for _, dir := range dirs {
+		encodeRecord(enc, newRecord(dir, dir.Path, filepath.Base(dir.Path)))
+		streamDirectory(enc, dir.Path, true)
+	}
+}
+
+func streamDirectory(enc *json.Encoder, path string, operand bool) {
+	if *recursive {
+		leave, ok := enterDirectory(path)
+		if !ok {
+			return
+		}
+		defer leave()
+	}
+	files, ok := readDirectory(path, operand)
+	if !ok {
+		return
+	}
+	for _, file := range files {
+		encodeRecord(enc, newRecord(file, entryPath(path, file.Path), file.Path))
+	}
+	if *recursive {
+		for _, file := range files {
+			if file.IsDir() && !isDotOrDotDot(file.Path) {
+				streamDirectory(enc, filepath.Join(path, file.Path), false)
+			}
+		}
+	}
+}
+
+// entryPath joins a directory and the name of one of its entries; . and
+// .. are kept as they are.
+func entryPath(dir, name string) string {
+	if isDotOrDotDot(name) {
+		return dir + "/" + name
+	}
+	return filepath.Join(dir, name)
+}
+
+func encodeRecord(enc *json.Encoder, r record) {
+	if err := enc.Encode(r); err != nil {
+		cli.Fatalf("%v", err)
+	}
+}
@@ -0,0 +1,56 @@
+package ls
+
+import (
+	"encoding/json"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNewRecord(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "f"), []byte("hello"), 0640); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(filepath.Join(dir, "f"), 0640|fs.ModeSetgid); err != nil {
+		t.Fatal(err)
+	}
+	for name, target := range map[string]string{"l": "f", "dang": "nowhere", "bad\xff": "bad\xfe"} {
+		if err := os.Symlink(target, filepath.Join(dir, name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name string
+		want []string // in the JSON encoding
+	}{
+		{"f", []string{`"name":"f"`, `"type":"file"`, `"mode":"2640"`, `"mode_string":"-rw-r-S---"`, `"size":5`, `"nlink":1`}},
+		{"l", []string{`"type":"symlink"`, `"target":"f"`, `"target_exists":true`}},
+		{"dang", []string{`"type":"symlink"`, `"target":"nowhere"`, `"target_exists":false`}},
+		// Names that are not UTF-8 come in base64 too.
+		{"bad\xff", []string{`"name":"bad�"`, `"name_bytes":"YmFk/w=="`, `"target_bytes":"YmFk/g=="`}},
+		{".", []string{`"type":"directory"`, `"name":"."`}},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(dir, tt.name)
+		info, err := os.Lstat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := json.Marshal(newRecord(newFileInfo(info, path, tt.name), path, tt.name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, field := range tt.want {
+			if !strings.Contains(string(data), field) {
+				t.Errorf("record of %q = %s, want %s in it", tt.name, data, field)
+			}
+		}
+		if strings.Contains(string(data), "path_bytes") != strings.Contains(tt.name, "\xff") {
+			t.Errorf("record of %q = %s: path_bytes only belongs to names that are not UTF-8", tt.name, data)
+		}
+	}
+}
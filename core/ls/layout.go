@@ -0,0 +1,286 @@
+package ls
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/ilnarildarovuch/CoreUtils-On-GO/internal/cli"
+	"github.com/ilnarildarovuch/CoreUtils-On-GO/internal/term"
+)
+
+// Listing formats.
+const (
+	formatAuto    = iota - 1 // none given: -C on a terminal, else -1
+	formatColumns            // -C, down the columns
+	formatAcross             // -x, across the rows
+	formatCommas             // -m
+	formatSingle             // -1
+	formatLong               // -l
+	formatJSON               // --format=json
+	formatNDJSON             // --format=ndjson
+)
+
+// format is set by each format option as it is parsed, so that the last
+// one given wins, as in GNU ls.
+var format = formatAuto
+
+// -g and -n select the long format too, and leave out the owner or show
+// numeric IDs.
+var noOwner, numericIDs bool
+
+// lineLength is how wide the formats other than -l and -1 may make a
+// line; 0 means there is no limit.
+var lineLength = 80
+
+// tabStop is how far apart the tab stops columns are padded to are; 0
+// pads with spaces only.
+var tabStop = 8
+
+// setFormat returns the option function that selects format f.
+func setFormat(f int) func() {
+	return func() { format = f }
+}
+
+// parseFormatWord sets format from the WORD of --format.
+func parseFormatWord(word string) {
+	switch word {
+	case "long", "verbose":
+		format = formatLong
+	case "single-column":
+		format = formatSingle
+	case "commas":
+		format = formatCommas
+	case "across", "horizontal":
+		format = formatAcross
+	case "vertical":
+		format = formatColumns
+	case "json":
+		format = formatJSON
+	case "ndjson":
+		format = formatNDJSON
+	default:
+		flags.UsageError("invalid argument '%s' for '--format'\n"+
+			"Valid arguments are:\n"+
+			"  - 'verbose', 'long'\n"+
+			"  - 'commas'\n"+
+			"  - 'horizontal', 'across'\n"+
+			"  - 'vertical'\n"+
+			"  - 'single-column'\n"+
+			"  - 'json'\n"+
+			"  - 'ndjson'", word)
+	}
+}
+
+// parseFormat settles the format when no option chose one: columns on a
+// terminal and one file per line otherwise. It then settles the width of
+// the line: that of -w, else $COLUMNS, else the terminal's.
+func parseFormat() {
+	if format == formatAuto {
+		format = formatSingle
+		if term.IsTerminal(os.Stdout) {
+			format = formatColumns
+		}
+	}
+
+	if *tabSize != "" {
+		n, err := strconv.Atoi(*tabSize)
+		if err != nil || n < 0 {
+			cli.Warnf("invalid tab size: '%s'", *tabSize)
+			os.Exit(cli.ExitTrouble)
+		}
+		tabStop = n
+	}
+
+	if *width != "" {
+		n, err := strconv.Atoi(*width)
+		if err != nil || n < 0 {
+			cli.Warnf("invalid line width: '%s'", *width)
+			os.Exit(cli.ExitTrouble)
+		}
+		lineLength = n
+		return
+	}
+	if format != formatColumns && format != formatAcross && format != formatCommas {
+		return
+	}
+	if s := os.Getenv("COLUMNS"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err == nil && n >= 0 {
+			lineLength = n
+			return
+		}
+		cli.Warnf("ignoring invalid width in environment variable COLUMNS: '%s'", s)
+	}
+	if n, ok := term.Width(os.Stdout); ok {
+		lineLength = n
+	}
+}
+
+// shortEntries formats files for the formats other than -l: each name
+// coloured and with its indicator, after its inode number with -i and its
+// blocks with -s, and the number of columns each takes. The numbers are
+// right-aligned unless align is false.
+func shortEntries(files []FileInfo, useColor, align bool) ([]string, []int) {
+	inodes := make([]string, len(files))
+	sizes := make([]string, len(files))
+	inodeWidth, sizeWidth := 0, 0
+	for i, file := range files {
+		if *showInode {
+			inodes[i] = "?"
+			if stat, ok := file.Sys().(*syscall.Stat_t); ok {
+				inodes[i] = strconv.FormatUint(stat.Ino, 10)
+			}
+		}
+		if *showBlocks {
+			sizes[i] = formatBlocks(file)
+		}
+		if align {
+			inodeWidth = max(inodeWidth, len(inodes[i]))
+			sizeWidth = max(sizeWidth, len(sizes[i]))
+		}
+	}
+
+	names := make([]string, len(files))
+	widths := make([]int, len(files))
+	for i, file := range files {
+		names[i], widths[i] = formatName(file, useColor)
+		prefix := ""
+		if *showInode {
+			prefix += fmt.Sprintf("%*s ", inodeWidth, inodes[i])
+		}
+		if *showBlocks {
+			prefix += fmt.Sprintf("%*s ", sizeWidth, sizes[i])
+		}
+		names[i] = prefix + names[i]
+		widths[i] += len(prefix)
+	}
+	return names, widths
+}
+
+func printOnePerLine(files []FileInfo, useColor bool) {
+	names, _ := shortEntries(files, useColor, true)
+	for _, name := range names {
+		fmt.Println(name)
+	}
+}
+
+// printColumns lists files in as many columns as fit in lineLength, each
+// as wide as its widest name, two spaces apart. They go down the columns,
+// or across the rows with -x.
+func printColumns(files []FileInfo, useColor bool) {
+	if len(files) == 0 {
+		return
+	}
+	names, widths := shortEntries(files, useColor, true)
+	byRows := format == formatAcross
+
+	cols, colWidths := fitColumns(widths, byRows)
+	rows := (len(files) + cols - 1) / cols
+
+	var b strings.Builder
+	for row := 0; row < rows; row++ {
+		b.Reset()
+		pos := 0
+		for col := 0; col < cols; col++ {
+			i := col*rows + row
+			if byRows {
+				i = row*cols + col
+			}
+			if i >= len(files) {
+				break
+			}
+			b.WriteString(names[i])
+			// The last name on a line is not padded.
+			next := (col+1)*rows + row
+			if byRows {
+				next = i + 1
+			}
+			if col < cols-1 && next < len(files) {
+				indent(&b, pos+widths[i], pos+colWidths[col])
+			}
+			pos += colWidths[col]
+		}
+		fmt.Println(b.String())
+	}
+}
+
+// indent pads from column from to column to with tabs, as far as the tab
+// stops allow, and spaces. Lines of unlimited length get only spaces.
+func indent(b *strings.Builder, from, to int) {
+	for from < to {
+		if tabStop > 0 && lineLength > 0 && to/tabStop > (from+1)/tabStop {
+			b.WriteByte('\t')
+			from += tabStop - from%tabStop
+		} else {
+			b.WriteByte(' ')
+			from++
+		}
+	}
+}
+
+// fitColumns finds the most columns names of the given widths fit in, as
+// GNU ls does: every column but the last takes its widest name and two
+// spaces, no column is narrower than three, and a line must stay shorter
+// than lineLength. It returns the number of columns and their widths.
+func fitColumns(widths []int, byRows bool) (int, []int) {
+	const minColumnWidth = 3
+	n := len(widths)
+	maxCols := n
+	if lineLength > 0 {
+		maxCols = min(n, max(1, lineLength/minColumnWidth))
+	}
+
+	for cols := maxCols; cols > 1; cols-- {
+		rows := (n + cols - 1) / cols
+		colWidths := make([]int, cols)
+		for col := range colWidths {
+			colWidths[col] = minColumnWidth
+		}
+		for i, w := range widths {
+			col := i / rows
+			if byRows {
+				col = i % cols
+			}
+			if col < cols-1 {
+				w += 2
+			}
+			colWidths[col] = max(colWidths[col], w)
+		}
+		total := 0
+		for _, w := range colWidths {
+			total += w
+		}
+		if lineLength == 0 || total < lineLength {
+			return cols, colWidths
+		}
+	}
+	return 1, []int{0}
+}
+
+// printCommas lists files separated by commas, starting a new line before
+// a name that would reach lineLength.
+func printCommas(files []FileInfo, useColor bool) {
+	if len(files) == 0 {
+		return
+	}
+	names, widths := shortEntries(files, useColor, false)
+	var b strings.Builder
+	pos := 0
+	for i, name := range names {
+		if i > 0 {
+			if lineLength == 0 || pos+widths[i]+2 < lineLength {
+				b.WriteString(", ")
+				pos += 2
+			} else {
+				b.WriteString(",\n")
+				pos = 0
+			}
+		}
+		b.WriteString(name)
+		pos += widths[i]
+	}
+	fmt.Println(b.String())
+}
@@ -0,0 +1,94 @@
+package ls
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/ilnarildarovuch/CoreUtils-On-GO/internal/term"
+)
+
+func TestFitColumns(t *testing.T) {
+	defer func(saved int) { lineLength = saved }(lineLength)
+
+	tests := []struct {
+		widths     []int
+		lineLength int
+		byRows     bool
+		cols       int
+		colWidths  []int
+	}{
+		{[]int{4, 4, 4, 4, 4, 4}, 40, false, 6, []int{6, 6, 6, 6, 6, 4}},
+		{[]int{4, 4, 4, 4, 4, 4}, 20, false, 3, []int{6, 6, 4}},
+		{[]int{4, 4, 4, 4, 4, 4}, 20, true, 3, []int{6, 6, 4}},
+		// A column is as wide as its widest name, and each but the last
+		// at least minColumnWidth.
+		{[]int{1, 10, 1, 1}, 16, false, 2, []int{12, 3}},
+		{[]int{1, 10, 1, 1}, 16, true, 2, []int{3, 10}},
+		{[]int{1, 10, 1, 1}, 15, false, 1, []int{0}},
+		{[]int{1, 10, 1, 1}, 13, true, 1, []int{0}},
+		// The total must stay below the line length.
+		{[]int{4, 4}, 10, false, 1, []int{0}},
+		{[]int{4, 4}, 11, false, 2, []int{6, 4}},
+		// 0 means there is no limit.
+		{[]int{1, 10, 1, 1}, 0, false, 4, []int{3, 12, 3, 3}},
+		{[]int{5}, 80, false, 1, []int{0}},
+		{nil, 80, false, 1, []int{0}},
+	}
+	for _, tt := range tests {
+		lineLength = tt.lineLength
+		cols, colWidths := fitColumns(tt.widths, tt.byRows)
+		if cols != tt.cols || !reflect.DeepEqual(colWidths, tt.colWidths) {
+			t.Errorf("fitColumns(%v, %v) with width %d = %d, %v, want %d, %v",
+				tt.widths, tt.byRows, tt.lineLength, cols, colWidths, tt.cols, tt.colWidths)
+		}
+	}
+}
+
+func TestFormatOptions(t *testing.T) {
+	defer func(saved int) { lineLength = saved }(lineLength)
+
+	auto := formatSingle
+	if term.IsTerminal(os.Stdout) {
+		auto = formatColumns
+	}
+	tests := []struct {
+		args []string
+		want int
+	}{
+		{nil, auto},
+		{[]string{"-C"}, formatColumns},
+		// The last format option wins.
+		{[]string{"-l", "-m"}, formatCommas},
+		{[]string{"-m", "-l"}, formatLong},
+		{[]string{"-lx"}, formatAcross},
+		{[]string{"-1", "-C"}, formatColumns},
+		{[]string{"-x", "-C"}, formatColumns},
+		{[]string{"-C", "-x"}, formatAcross},
+		{[]string{"-C", "-m", "-1"}, formatSingle},
+		{[]string{"--format=long", "-x"}, formatAcross},
+		{[]string{"-x", "--format=single-column"}, formatSingle},
+		{[]string{"-m", "--format=json"}, formatJSON},
+		{[]string{"-g", "-m"}, formatCommas},
+		{[]string{"-m", "-g"}, formatLong},
+		{[]string{"-m", "-o"}, formatLong},
+		{[]string{"-m", "-n"}, formatLong},
+		{[]string{"-m", "--full-time"}, formatLong},
+		// -1 does not undo -l, and -f undoes only -l.
+		{[]string{"-l", "-1"}, formatLong},
+		{[]string{"-l", "-f"}, auto},
+		{[]string{"-m", "-f"}, formatCommas},
+		{[]string{"-f", "-l"}, formatLong},
+	}
+	for _, tt := range tests {
+		format = formatAuto
+		flags.Parse(append([]string{"-w", "40"}, tt.args...))
+		parseFormat()
+		if format != tt.want {
+			t.Errorf("ls %v: format %d, want %d", tt.args, format, tt.want)
+		}
+		if lineLength != 40 {
+			t.Errorf("ls -w 40 %v: line length %d", tt.args, lineLength)
+		}
+	}
+}
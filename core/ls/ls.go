@@ -0,0 +1,717 @@
+package ls
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/ilnarildarovuch/CoreUtils-On-GO/internal/cli"
+	"github.com/ilnarildarovuch/CoreUtils-On-GO/internal/mode"
+	"github.com/ilnarildarovuch/CoreUtils-On-GO/internal/quotearg"
+	"github.com/ilnarildarovuch/CoreUtils-On-GO/internal/term"
+	"github.com/ilnarildarovuch/CoreUtils-On-GO/internal/users"
+)
+
+const version = "1.0.0"
+
+var flags = cli.NewFlagSet(version)
+
+var (
+	all           = flags.Bool('a', "all", "do not ignore entries starting with .")
+	almostAll     = flags.Bool('A', "almost-all", "do not list implied . and ..")
+	ignoreBackups = flags.Bool('B', "ignore-backups", "do not list implied entries ending with ~")
+	sizeSpec      = flags.String(0, "block-size", "", "with -l, scale sizes by `SIZE` when printing them; e.g., '--block-size=M'; see SIZE format below")
+	classify      = flags.StringOpt(0, "classify", "", "always", "append indicator (one of */=>@|) to entries; `WHEN` is always, auto or never")
+	colorOutput   = flags.StringOpt(0, "color", "auto", "always", "colorize the output; `WHEN` is always, auto or never")
+	directory     = flags.Bool('d', "directory", "list directories themselves, not their contents")
+	derefDirArgs  = flags.Bool(0, "dereference-command-line-symlink-to-dir", "follow each command line symbolic link that points to a directory")
+	fileType      = flags.Bool(0, "file-type", "likewise, except do not append '*'")
+	groupDirs     = flags.Bool(0, "group-directories-first", "group directories before files; can be augmented with a --sort option, but any use of --sort=none (-U) disables grouping")
+	noGroup       = flags.Bool('G', "no-group", "in a long listing, don't print group names")
+	derefArgs     = flags.Bool('H', "dereference-command-line", "follow symbolic links listed on the command line")
+	showInode     = flags.Bool('i', "inode", "print the index number of each file")
+	kibibytes     = flags.Bool('k', "kibibytes", "default to 1024-byte blocks for file system usage; used only with -s and per directory totals")
+	indicators    = flags.String(0, "indicator-style", "", "append indicator with style `WORD` to entry names: none, slash (-p), file-type (--file-type) or classify (-F)")
+	dereference   = flags.Bool('L', "dereference", "when showing file information for a symbolic link, show information for the file the link references rather than for the link itself")
+	slash         = flags.Bool('p', "", "append / indicator to directories")
+	quotingWord   = flags.String(0, "quoting-style", "", "use quoting style `WORD` for entry names: literal, locale, shell, shell-always, shell-escape, shell-escape-always, c, escape (overrides QUOTING_STYLE environment variable)")
+	sortWord      = flags.String(0, "sort", "", "sort by `WORD` instead of name: none (-U), size (-S), time (-t), version (-v), extension (-X) or width")
+	reverse       = flags.Bool('r', "reverse", "reverse order while sorting")
+	recursive     = flags.Bool('R', "recursive", "list subdirectories recursively")
+	showBlocks    = flags.Bool('s', "size", "print the allocated size of each file, in blocks")
+	tabSize       = flags.String('T', "tabsize", "", "assume tab stops at each `COLS` instead of 8")
+	timeStyle     = flags.String(0, "time-style", "", "show times with -l in `STYLE`: full-iso, long-iso, iso, locale or +FORMAT, as for date; a FORMAT of two lines is for old, then recent files (default $TIME_STYLE, or locale)")
+	width         = flags.String('w', "width", "", "set output width to `COLS`; 0 means no limit")
+)
+
+type FileInfo struct {
+	fs.FileInfo
+	Path       string
+	LinkTarget string
+	Target     fs.FileInfo // what a symbolic link points to, nil if missing
+	Birth      time.Time   // when ls shows birth times, zero if unknown
+}
+
+// unstatted stands for a file ls failed to stat. Only its type, from
+// lstat, is known; the long format shows "?" for everything else.
+type unstatted struct{ fs.FileInfo }
+
+func (u unstatted) Mode() fs.FileMode { return u.FileInfo.Mode().Type() }
+func (unstatted) Size() int64         { return 0 }
+func (unstatted) Sys() interface{}    { return nil }
+
+// newFileInfo describes the file at path, listed as name.
+func newFileInfo(info fs.FileInfo, path, name string) FileInfo {
+	file := FileInfo{FileInfo: info, Path: name}
+	if info.Mode()&os.ModeSymlink != 0 {
+		file.LinkTarget, _ = os.Readlink(path)
+		file.Target, _ = os.Stat(path)
+	}
+	if timeType == timeBirth {
+		file.Birth, _ = birthTime(path, info.Mode()&os.ModeSymlink == 0)
+	}
+	return file
+}
+
+// Which symbolic links ls follows.
+const (
+	followNone       = iota
+	followDirOperand // those named on the command line that lead to directories
+	followOperands   // those named on the command line, -H
+	followAll        // those in directories too, -L
+)
+
+var follow = followNone
+
+// parseDereference settles -L, -H and
+// --dereference-command-line-symlink-to-dir. Without them, links to
+// directories named on the command line are followed unless -d, -F or the
+// long format is asked for.
+func parseDereference() {
+	switch {
+	case *dereference:
+		follow = followAll
+	case *derefArgs:
+		follow = followOperands
+	case *derefDirArgs:
+		follow = followDirOperand
+	case !*directory && indicatorStyle != indicatorClassify && format != formatLong:
+		follow = followDirOperand
+	}
+}
+
+// statOperand describes a file named on the command line.
+func statOperand(path string) (fs.FileInfo, error) {
+	switch follow {
+	case followAll, followOperands:
+		return os.Stat(path)
+	case followDirOperand:
+		if info, err := os.Stat(path); err == nil && info.IsDir() {
+			return info, nil
+		}
+	}
+	return os.Lstat(path)
+}
+
+func init() {
+	flags.BoolFunc('b', "escape", "print C-style escapes for nongraphic characters", func() { *quotingWord = "escape" })
+	// -F takes no argument, so that it bundles like "-1Fl"; only
+	// --classify has the optional WHEN.
+	flags.BoolFunc('F', "", "like --classify=always", func() { *classify = "always" })
+	flags.BoolFunc('f', "", "list all entries in directory order, without color and not in long format", func() {
+		*all = true
+		*sortWord = "none"
+		*colorOutput = "never"
+		if format == formatLong {
+			format = formatAuto
+		}
+	})
+	flags.Func(0, "hide", "do not list implied entries matching shell `PATTERN` (overridden by -a or -A)", func(p string) error {
+		hidePatterns = append(hidePatterns, p)
+		return nil
+	})
+	flags.BoolFunc('h', "human-readable", "with -l and -s, print sizes like 1K 234M 2G etc.", func() { *sizeSpec = "human-readable" })
+	flags.Func('I', "ignore", "do not list implied entries matching shell `PATTERN`", func(p string) error {
+		ignorePatterns = append(ignorePatterns, p)
+		return nil
+	})
+	flags.BoolFunc('N', "literal", "print entry names without quoting", func() { *quotingWord = "literal" })
+	flags.BoolFunc('q', "hide-control-chars", "print ? instead of nongraphic characters", func() { controlChars = "hide" })
+	flags.BoolFunc('Q', "quote-name", "enclose entry names in double quotes", func() { *quotingWord = "c" })
+	flags.BoolFunc(0, "show-control-chars", "show nongraphic characters as-is (the default, unless output is a terminal)", func() { controlChars = "show" })
+	flags.BoolFunc(0, "si", "likewise, but use powers of 1000 not 1024", func() { *sizeSpec = "si" })
+	flags.BoolFunc('S', "", "sort by file size, largest first", func() { *sortWord = "size" })
+	flags.BoolFunc('t', "", "sort by time, newest first; see --time", func() { *sortWord = "time" })
+	flags.BoolFunc('U', "", "do not sort; list entries in directory order", func() { *sortWord = "none" })
+	flags.BoolFunc('v', "", "natural sort of (version) numbers within text", func() { *sortWord = "version" })
+	flags.BoolFunc('X', "", "sort alphabetically by entry extension", func() { *sortWord = "extension" })
+
+	// The format options set format as they are parsed, so that the last
+	// one wins.
+	flags.BoolFunc('C', "", "list entries by columns", setFormat(formatColumns))
+	flags.Func(0, "format", "use format `WORD`: across or horizontal (-x), commas (-m), long or verbose (-l), single-column (-1), vertical (-C), or json or ndjson for programs", func(word string) error {
+		parseFormatWord(word)
+		return nil
+	})
+	flags.BoolFunc(0, "full-time", "like -l --time-style=full-iso", func() {
+		*timeStyle = "full-iso"
+		format = formatLong
+	})
+	flags.BoolFunc('g', "", "like -l, but do not list owner", func() {
+		noOwner = true
+		format = formatLong
+	})
+	flags.BoolFunc('l', "", "use a long listing format", setFormat(formatLong))
+	flags.BoolFunc('m', "", "fill width with a comma separated list of entries", setFormat(formatCommas))
+	flags.BoolFunc('n', "numeric-uid-gid", "like -l, but list numeric user and group IDs", func() {
+		numericIDs = true
+		format = formatLong
+	})
+	flags.BoolFunc('o', "", "like -l, but do not list group information", func() {
+		*noGroup = true
+		format = formatLong
+	})
+	flags.BoolFunc('x', "", "list entries by lines instead of by columns", setFormat(formatAcross))
+	flags.BoolFunc('1', "", "list one file per line", func() {
+		// As in GNU ls, -1 does not undo -l.
+		if format != formatLong {
+			format = formatSingle
+		}
+	})
+
+	// So do the options choosing the time shown.
+	flags.BoolFunc('c', "", "with -lt: sort by, and show, ctime (time of last change of file status information); with -l: show ctime and sort by name; otherwise: sort by ctime, newest first", setTime(timeCtime))
+	flags.Func(0, "time", "show and sort by time `WORD` instead of the modification time: atime, access or use (-u); ctime or status (-c); birth or creation", func(word string) error {
+		parseTimeWord(word)
+		return nil
+	})
+	flags.BoolFunc('u', "", "with -lt: sort by, and show, access time; with -l: show access time and sort by name; otherwise: sort by access time, newest first", setTime(timeAtime))
+}
+
+func Main() {
+	flags.Usage = usage
+	flags.ErrorStatus = cli.ExitTrouble
+	flags.Parse(os.Args[1:])
+	parseTimeOptions()
+	parseFormat()
+	parseIndicatorStyle()
+	parseQuoting()
+	parseSortOptions()
+	parseSizeOptions()
+	parseDereference()
+
+	paths := flags.Args()
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+
+	useColor := shouldUseColor()
+	if useColor {
+		if err := parseLSColors(os.Getenv("LS_COLORS")); err != nil {
+			cli.Warnf("%v", err)
+			useColor = false
+		}
+	}
+	// With -L, dangling links among the entries only come to light when
+	// more than their names is listed.
+	statEntries = format == formatLong || *showBlocks || *showInode || *recursive || *groupDirs ||
+		sortType == sortTime || sortType == sortSize || indicatorStyle != indicatorNone || useColor
+
+	if useColor {
+		// Tabs would take the colour of the background under them on
+		// some terminals; GNU ls pads with spaces.
+		tabStop = 0
+	}
+
+	// Files named on the command line are listed together, then the
+	// contents of each directory, under its name when there are several.
+	var files, dirs []FileInfo
+	for _, path := range paths {
+		info, err := statOperand(path)
+		if err != nil {
+			fileFailure(true, "cannot access", path, err)
+			continue
+		}
+		if info.IsDir() && !*directory {
+			dirs = append(dirs, newFileInfo(info, path, path))
+			continue
+		}
+		files = append(files, newFileInfo(info, path, path))
+	}
+	sortFiles(dirs)
+
+	sortFiles(files)
+	switch format {
+	case formatJSON:
+		printJSON(files, dirs)
+		cli.Exit()
+	case formatNDJSON:
+		printNDJSON(files, dirs)
+		cli.Exit()
+	}
+
+	if len(files) > 0 {
+		// As in GNU ls, the names of the directories count in lining up
+		// those of the files.
+		someQuoted = alignQuotes && (anyQuoted(files) || anyQuoted(dirs))
+		printFiles(files, useColor, false)
+		if len(dirs) > 0 {
+			fmt.Println()
+		}
+	}
+	// A lone directory goes without a header, unless -R lists others.
+	printDirName = len(files) > 0 || len(flags.Args()) > 1 || len(dirs) > 1
+	for _, dir := range dirs {
+		listDirectory(dir.Path, useColor, true)
+	}
+
+	cli.Exit()
+}
+
+func usage() {
+	w := flags.Output()
+	fmt.Fprintf(w, "Usage: %s [OPTION]... [FILE]...\n", cli.Name)
+	fmt.Fprintln(w, "List information about the FILEs (the current directory by default).")
+	fmt.Fprintln(w, "Sort entries alphabetically if none of -cftuvSUX nor --sort is specified.")
+	fmt.Fprintln(w, "\nOptions:")
+	flags.PrintDefaults()
+	fmt.Fprintln(w, "\n--format=json prints a JSON array with an object describing each file, the")
+	fmt.Fprintln(w, "contents of a directory in its \"entries\"; --format=ndjson prints an object")
+	fmt.Fprintln(w, "per line instead. With -R, both descend into subdirectories. Names that are")
+	fmt.Fprintln(w, "not UTF-8 also come in base64, in path_bytes, name_bytes and target_bytes.")
+	fmt.Fprintln(w, "\nThe SIZE argument is an integer and optional unit (example: 10K is 10*1024).")
+	fmt.Fprintln(w, "Units are K,M,G,T,P,E,Z,Y (powers of 1024) or KB,MB,... (powers of 1000).")
+	fmt.Fprintln(w, "\nNames are compared byte by byte, as in the C locale, whatever the locale.")
+	fmt.Fprintln(w, "\nExit status:")
+	fmt.Fprintln(w, " 0  if OK,")
+	fmt.Fprintln(w, " 1  if minor problems (e.g., cannot access subdirectory),")
+	fmt.Fprintln(w, " 2  if serious trouble (e.g., cannot access command-line argument).")
+	fmt.Fprintf(w, "\nExamples:\n  %s -l\n  %s -a /tmp\n", cli.Name, cli.Name)
+}
+
+func shouldUseColor() bool {
+	switch *colorOutput {
+	case "always", "yes", "force":
+		return true
+	case "never", "no", "none":
+		return false
+	case "auto", "tty", "if-tty":
+		return term.IsTerminal(os.Stdout)
+	}
+	flags.UsageError("invalid argument '%s' for '--color'\n"+
+		"Valid arguments are:\n"+
+		"  - 'always', 'yes', 'force'\n"+
+		"  - 'never', 'no', 'none'\n"+
+		"  - 'auto', 'tty', 'if-tty'", *colorOutput)
+	return false
+}
+
+// parseIndicatorStyle settles -F, --file-type, -p and --indicator-style.
+func parseIndicatorStyle() {
+	switch *classify {
+	case "":
+	case "always", "yes", "force":
+		indicatorStyle = indicatorClassify
+	case "auto", "tty", "if-tty":
+		if term.IsTerminal(os.Stdout) {
+			indicatorStyle = indicatorClassify
+		}
+	case "never", "no", "none":
+	default:
+		flags.UsageError("invalid argument '%s' for '--classify'\n"+
+			"Valid arguments are:\n"+
+			"  - 'always', 'yes', 'force'\n"+
+			"  - 'never', 'no', 'none'\n"+
+			"  - 'auto', 'tty', 'if-tty'", *classify)
+	}
+	if indicatorStyle == indicatorNone {
+		switch {
+		case *fileType:
+			indicatorStyle = indicatorFileType
+		case *slash:
+			indicatorStyle = indicatorSlash
+		}
+	}
+	switch *indicators {
+	case "":
+	case "none":
+		indicatorStyle = indicatorNone
+	case "slash":
+		indicatorStyle = indicatorSlash
+	case "file-type":
+		indicatorStyle = indicatorFileType
+	case "classify":
+		indicatorStyle = indicatorClassify
+	default:
+		flags.UsageError("invalid argument '%s' for '--indicator-style'\n"+
+			"Valid arguments are:\n"+
+			"  - 'none'\n"+
+			"  - 'slash'\n"+
+			"  - 'file-type'\n"+
+			"  - 'classify'", *indicators)
+	}
+}
+
+// formatName returns the name of file as listed, quoted, coloured and
+// followed by its indicator, and how many columns it takes.
+func formatName(file FileInfo, useColor bool) (string, int) {
+	name, pad := quotedName(file)
+	width := term.StringWidth(name)
+	if useColor {
+		name = colorize(name, nameColor(file))
+	}
+	if pad {
+		name = " " + name
+		width++
+	}
+	ind := indicator(file.Mode())
+	return name + ind, width + len(ind)
+}
+
+// quotedName quotes the name of file, and tells whether it goes one column
+// in to line up with the quoted names around it.
+func quotedName(file FileInfo) (string, bool) {
+	name, quoted := quoteName(file.Path, nameQuoteChars)
+	return name, alignQuotes && someQuoted && !quoted
+}
+
+// statEntries tells whether ls looks up what the symbolic links among
+// the entries of a directory point to with -L.
+var statEntries bool
+
+// printDirName heads each listing of a directory with its name; -R does
+// too. headerPrinted tells whether one has been, for the blank lines
+// between them.
+var printDirName, headerPrinted bool
+
+// listDirectory lists the directory at path, and with -R those below it.
+// Failures are reported as they come, and are serious for a directory
+// named on the command line.
+func listDirectory(path string, useColor, operand bool) {
+	dir, err := os.Open(path)
+	if err != nil {
+		fileFailure(operand, "cannot open directory", path, err)
+		return
+	}
+	if *recursive {
+		leave, ok := enterDirectory(path)
+		if !ok {
+			dir.Close()
+			return
+		}
+		defer leave()
+	}
+
+	if *recursive || printDirName {
+		if headerPrinted {
+			fmt.Println()
+		}
+		headerPrinted = true
+		fmt.Println(dirHeader(path))
+	}
+	files := readEntries(dir, path, operand)
+	someQuoted = alignQuotes && anyQuoted(files)
+	printFiles(files, useColor, true)
+
+	if *recursive {
+		for _, file := range files {
+			if file.IsDir() && !isDotOrDotDot(file.Path) {
+				listDirectory(childPath(path, file.Path), useColor, false)
+			}
+		}
+	}
+}
+
+// activeDirs holds the directories -R is listing, from the one named on
+// the command line down to the current one, by device and inode.
+var activeDirs = map[[2]uint64]bool{}
+
+// enterDirectory marks the directory at path as being listed, and returns
+// a function to unmark it. A directory that is already being listed is
+// inside itself, through a symbolic link or a bind mount; ls reports the
+// loop and does not list it again.
+func enterDirectory(path string) (func(), bool) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return func() {}, true
+	}
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return func() {}, true
+	}
+	key := [2]uint64{uint64(stat.Dev), stat.Ino}
+	if activeDirs[key] {
+		cli.Warnf("%s: not listing already-listed directory", quotearg.Quote(path, quotearg.ShellEscape, ":"))
+		cli.Status = cli.ExitTrouble
+		return nil, false
+	}
+	activeDirs[key] = true
+	return func() { delete(activeDirs, key) }, true
+}
+
+// readDirectory returns the entries of the directory at path that ls
+// lists, in order, reporting failures as listDirectory does. It returns
+// false if the directory cannot be opened.
+func readDirectory(path string, operand bool) ([]FileInfo, bool) {
+	dir, err := os.Open(path)
+	if err != nil {
+		fileFailure(operand, "cannot open directory", path, err)
+		return nil, false
+	}
+	return readEntries(dir, path, operand), true
+}
+
+// readEntries reads the open directory dir, found at path, and closes it.
+// Entries read before an error are still listed.
+func readEntries(dir *os.File, path string, operand bool) []FileInfo {
+	// Entries come in directory order, for -U.
+	names, err := readDirNames(dir)
+	dir.Close()
+	if err != nil {
+		fileFailure(operand, "reading directory", path, err)
+	}
+
+	files := make([]FileInfo, 0, len(names))
+	for _, base := range names {
+		if shouldSkipEntry(base) {
+			continue
+		}
+
+		name := entryName(path, base)
+		info, err := os.Lstat(name)
+		if err != nil {
+			fileFailure(false, "cannot access", name, err)
+			continue
+		}
+		if *dereference && info.Mode()&os.ModeSymlink != 0 {
+			target, err := os.Stat(name)
+			if err == nil {
+				info = target
+			} else if statEntries {
+				// A dangling link is listed with only its type known.
+				fileFailure(false, "cannot access", name, err)
+				files = append(files, FileInfo{FileInfo: unstatted{info}, Path: base})
+				continue
+			}
+		}
+
+		files = append(files, newFileInfo(info, name, base))
+	}
+
+	sortFiles(files)
+	return files
+}
+
+// entryName returns the name by which ls reaches an entry of the
+// directory dir: the entry's own name in the current directory.
+func entryName(dir, name string) string {
+	if dir == "." {
+		return name
+	}
+	if strings.HasSuffix(dir, "/") {
+		return dir + name
+	}
+	return dir + "/" + name
+}
+
+// childPath returns the path -R lists a subdirectory of dir by, dir and
+// name joined with one slash.
+func childPath(dir, name string) string {
+	trimmed := strings.TrimRight(dir, "/")
+	if trimmed == "" {
+		return dir + name
+	}
+	return trimmed + "/" + name
+}
+
+// fileFailure reports that ls failed to do what message says to path, as
+// GNU ls does. Failures over files named on the command line are serious
+// and make the exit status 2; the rest make it 1.
+func fileFailure(serious bool, message, path string, err error) {
+	cli.Warnf("%s %s: %s", message, quotearg.Quote(path, quotearg.ShellEscapeAlways, ""), errorText(err))
+	if serious {
+		cli.Status = cli.ExitTrouble
+	} else if cli.Status == cli.ExitSuccess {
+		cli.Status = cli.ExitFailure
+	}
+}
+
+// errorText describes err the way strerror does, without the operation
+// and path os adds.
+func errorText(err error) string {
+	var errno syscall.Errno
+	if !errors.As(err, &errno) {
+		return err.Error()
+	}
+	s := errno.Error()
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+// printFiles prints one listing in the chosen format; with -l or -s, that
+// of a directory starts with the total of the blocks allocated to it.
+func printFiles(files []FileInfo, useColor bool, showTotal bool) {
+	if showTotal && (format == formatLong || *showBlocks) {
+		fmt.Printf("total %s\n", formatTotal(files))
+	}
+	switch format {
+	case formatLong:
+		printLongFormat(files, useColor)
+	case formatSingle:
+		printOnePerLine(files, useColor)
+	case formatCommas:
+		printCommas(files, useColor)
+	default:
+		printColumns(files, useColor)
+	}
+}
+
+func printLongFormat(files []FileInfo, useColor bool) {
+	// Every column is as wide as its widest entry in the listing.
+	lines := make([]longLine, len(files))
+	var w longWidths
+	for i, file := range files {
+		l := newLongLine(file)
+		lines[i] = l
+		w.inode = max(w.inode, len(l.inode))
+		w.blocks = max(w.blocks, len(l.blocks))
+		w.nlink = max(w.nlink, len(l.nlink))
+		w.owner = max(w.owner, term.StringWidth(l.owner))
+		w.group = max(w.group, term.StringWidth(l.group))
+		w.date = max(w.date, term.StringWidth(l.date))
+		if l.device {
+			w.major = max(w.major, len(l.major))
+			w.minor = max(w.minor, len(l.minor))
+		} else {
+			w.size = max(w.size, len(l.size))
+		}
+	}
+	if w.major > 0 {
+		w.size = max(w.size, w.major+2+w.minor)
+	}
+
+	for i, file := range files {
+		printFileLong(file, lines[i], w, useColor)
+	}
+}
+
+// longLine holds the columns of a long listing line before the name.
+type longLine struct {
+	inode, blocks, mode, nlink, owner, group string
+	size, major, minor                       string
+	device                                   bool
+	date                                     string
+}
+
+type longWidths struct {
+	inode, blocks, nlink, owner, group, size, major, minor, date int
+}
+
+func newLongLine(file FileInfo) longLine {
+	l := longLine{
+		inode:  "?",
+		blocks: formatBlocks(file),
+		mode:   mode.String(file.Mode()),
+		nlink:  "?",
+		owner:  "?",
+		group:  "?",
+		date:   formatTime(fileTime(file)),
+	}
+	l.size = formatSize(file)
+	if _, ok := file.FileInfo.(unstatted); ok {
+		l.mode = l.mode[:1] + "?????????"
+		l.size, l.date = "?", "?"
+		return l
+	}
+
+	stat, ok := file.Sys().(*syscall.Stat_t)
+	if !ok {
+		return l
+	}
+	l.inode = strconv.FormatUint(stat.Ino, 10)
+	l.nlink = strconv.FormatUint(uint64(stat.Nlink), 10)
+	if numericIDs {
+		l.owner = strconv.FormatUint(uint64(stat.Uid), 10)
+		l.group = strconv.FormatUint(uint64(stat.Gid), 10)
+	} else {
+		l.owner = users.UserString(int(stat.Uid))
+		l.group = users.GroupString(int(stat.Gid))
+	}
+	if file.Mode()&os.ModeDevice != 0 {
+		l.device = true
+		l.major = strconv.FormatUint(uint64(major(uint64(stat.Rdev))), 10)
+		l.minor = strconv.FormatUint(uint64(minor(uint64(stat.Rdev))), 10)
+	}
+	return l
+}
+
+// major and minor split a device number the way the Linux kernel encodes
+// it.
+func major(dev uint64) uint32 {
+	return uint32((dev>>8)&0xfff | (dev>>32)&^0xfff)
+}
+
+func minor(dev uint64) uint32 {
+	return uint32(dev&0xff | (dev>>12)&^0xff)
+}
+
+func printFileLong(file FileInfo, l longLine, w longWidths, useColor bool) {
+	var b strings.Builder
+	if *showInode {
+		fmt.Fprintf(&b, "%*s ", w.inode, l.inode)
+	}
+	if *showBlocks {
+		fmt.Fprintf(&b, "%*s ", w.blocks, l.blocks)
+	}
+	fmt.Fprintf(&b, "%s %*s ", l.mode, w.nlink, l.nlink)
+	if !noOwner {
+		b.WriteString(padRight(l.owner, w.owner) + " ")
+	}
+	if !*noGroup {
+		b.WriteString(padRight(l.group, w.group) + " ")
+	}
+	if l.device {
+		fmt.Fprintf(&b, "%*s ", w.size, fmt.Sprintf("%*s, %*s", w.major, l.major, w.minor, l.minor))
+	} else {
+		fmt.Fprintf(&b, "%*s ", w.size, l.size)
+	}
+	if l.date == "?" {
+		fmt.Fprintf(&b, "%*s ", w.date, l.date)
+	} else {
+		b.WriteString(l.date + " ")
+	}
+
+	if file.Mode()&os.ModeSymlink != 0 && file.LinkTarget != "" {
+		// The link goes uncharacterised; its target gets the indicator.
+		name, pad := quotedName(file)
+		target, _ := quoteName(file.LinkTarget, nameQuoteChars)
+		if pad {
+			b.WriteByte(' ')
+		}
+		if useColor {
+			b.WriteString(colorize(name, nameColor(file)))
+			b.WriteString(" -> " + colorize(target, targetColor(file)))
+		} else {
+			b.WriteString(name + " -> " + target)
+		}
+		if file.Target != nil {
+			b.WriteString(indicator(file.Target.Mode()))
+		}
+	} else {
+		name, _ := formatName(file, useColor)
+		b.WriteString(name)
+	}
+	fmt.Println(b.String())
+}
+
+// padRight pads s with spaces to width characters.
+func padRight(s string, width int) string {
+	if n := term.StringWidth(s); n < width {
+		return s + strings.Repeat(" ", width-n)
+	}
+	return s
+}
@@ -0,0 +1,153 @@
+package ls
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/ilnarildarovuch/CoreUtils-On-GO/internal/cli"
+)
+
+func TestUnstattedLongLine(t *testing.T) {
+	dang := filepath.Join(t.TempDir(), "dang")
+	if err := os.Symlink("nowhere", dang); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Lstat(dang)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	l := newLongLine(FileInfo{FileInfo: unstatted{info}, Path: "dang"})
+	want := longLine{
+		inode: "?", blocks: "?", mode: "l?????????", nlink: "?",
+		owner: "?", group: "?", size: "?", date: "?",
+	}
+	if l != want {
+		t.Errorf("newLongLine(dangling link) = %+v, want %+v", l, want)
+	}
+
+	// Statted, the same link is listed in full.
+	if l := newLongLine(newFileInfo(info, dang, "dang")); l.mode != "lrwxrwxrwx" || l.size != "7" {
+		t.Errorf("newLongLine(link) = %+v", l)
+	}
+}
+
+func TestMajorMinor(t *testing.T) {
+	tests := []struct {
+		dev          uint64
+		major, minor uint32
+	}{
+		{0x0103, 1, 3}, // /dev/null
+		{0x0801, 8, 1},
+		{0x10010300, 259, 65536},
+		{0x100000000001, 4096, 1},
+	}
+	for _, tt := range tests {
+		if major, minor := major(tt.dev), minor(tt.dev); major != tt.major || minor != tt.minor {
+			t.Errorf("device %#x = %d, %d, want %d, %d", tt.dev, major, minor, tt.major, tt.minor)
+		}
+	}
+}
+
+func TestPadRight(t *testing.T) {
+	tests := []struct {
+		s     string
+		width int
+		want  string
+	}{
+		{"root", 6, "root  "},
+		{"root", 4, "root"},
+		{"root", 2, "root"},
+		{"élan", 5, "élan "},
+		{"日本", 5, "日本 "},
+	}
+	for _, tt := range tests {
+		if got := padRight(tt.s, tt.width); got != tt.want {
+			t.Errorf("padRight(%q, %d) = %q, want %q", tt.s, tt.width, got, tt.want)
+		}
+	}
+}
+
+func TestNewLongLine(t *testing.T) {
+	defer func(saved bool) { numericIDs = saved }(numericIDs)
+	numericIDs = true
+
+	path := filepath.Join(t.TempDir(), "f")
+	if err := os.WriteFile(path, []byte("hello"), 0640); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Link(path, path+"2"); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Lstat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	l := newLongLine(newFileInfo(info, path, "f"))
+	uid, gid := strconv.Itoa(os.Getuid()), strconv.Itoa(os.Getgid())
+	if l.mode != "-rw-r-----" || l.nlink != "2" || l.owner != uid || l.group != gid || l.size != "5" || l.device {
+		t.Errorf("newLongLine(f) = %+v", l)
+	}
+
+	if info, err := os.Stat("/dev/null"); err == nil {
+		l := newLongLine(newFileInfo(info, "/dev/null", "null"))
+		if !l.device || l.major != "1" || l.minor != "3" || l.mode[0] != 'c' {
+			t.Errorf("newLongLine(/dev/null) = %+v", l)
+		}
+	}
+}
+
+func TestEntryPaths(t *testing.T) {
+	tests := []struct {
+		dir, name   string
+		entry, path string
+	}{
+		{".", "a", "a", "./a"},
+		{"d", "a", "d/a", "d/a"},
+		{"d/", "a", "d/a", "d/a"},
+		{"d//", "a", "d//a", "d/a"},
+		{"/", "etc", "/etc", "/etc"},
+		{"//", "etc", "//etc", "//etc"},
+	}
+	for _, tt := range tests {
+		if got := entryName(tt.dir, tt.name); got != tt.entry {
+			t.Errorf("entryName(%q, %q) = %q, want %q", tt.dir, tt.name, got, tt.entry)
+		}
+		if got := childPath(tt.dir, tt.name); got != tt.path {
+			t.Errorf("childPath(%q, %q) = %q, want %q", tt.dir, tt.name, got, tt.path)
+		}
+	}
+}
+
+func TestEnterDirectory(t *testing.T) {
+	defer func(saved int) { cli.Status = saved }(cli.Status)
+
+	dir := t.TempDir()
+	loop := filepath.Join(dir, "loop")
+	if err := os.Symlink(".", loop); err != nil {
+		t.Fatal(err)
+	}
+
+	leave, ok := enterDirectory(dir)
+	if !ok {
+		t.Fatalf("enterDirectory(%s) refused", dir)
+	}
+	// The same directory by another name is a loop while it is listed...
+	if _, ok := enterDirectory(loop); ok {
+		t.Errorf("enterDirectory(%s) inside %s accepted", loop, dir)
+	}
+	if cli.Status != cli.ExitTrouble {
+		t.Errorf("exit status %d after a loop, want %d", cli.Status, cli.ExitTrouble)
+	}
+	// ...but not once it is done.
+	leave()
+	leave, ok = enterDirectory(loop)
+	if !ok {
+		t.Errorf("enterDirectory(%s) refused after leaving %s", loop, dir)
+	} else {
+		leave()
+	}
+}
@@ -0,0 +1,139 @@
+package ls
+
+import (
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ilnarildarovuch/CoreUtils-On-GO/internal/cli"
+	"github.com/ilnarildarovuch/CoreUtils-On-GO/internal/quotearg"
+	"github.com/ilnarildarovuch/CoreUtils-On-GO/internal/term"
+)
+
+var quotingStyle = quotearg.Literal
+
+// controlChars is "hide" after -q and "show" after --show-control-chars,
+// whichever came last.
+var controlChars string
+
+// hideControlChars prints ? for the characters a name cannot show, in the
+// styles that do not escape them.
+var hideControlChars bool
+
+// alignQuotes indents the names of a listing that are not quoted by one
+// column when others are, so that the names line up inside the quotes.
+var alignQuotes bool
+
+// someQuoted tells whether any name in the listing being printed is
+// quoted, for alignQuotes.
+var someQuoted bool
+
+// nameQuoteChars are the characters quoted in names beyond those the style
+// quotes: spaces for -b, and the indicators -F and --file-type append.
+var nameQuoteChars string
+
+// parseQuoting settles --quoting-style, -b, -N, -Q, -q and
+// --show-control-chars. Without a style, $QUOTING_STYLE chooses one; failing
+// that, names are quoted for the shell on a terminal and printed as they
+// are otherwise. On a terminal control characters are hidden.
+func parseQuoting() {
+	tty := term.IsTerminal(os.Stdout)
+	hideControlChars = controlChars == "hide" || controlChars == "" && tty
+
+	switch {
+	case *quotingWord != "":
+		style, ok := quotearg.ParseStyle(*quotingWord)
+		if !ok {
+			valid := ""
+			for _, name := range quotearg.Names {
+				valid += "\n  - '" + name + "'"
+			}
+			flags.UsageError("invalid argument '%s' for '--quoting-style'\n"+
+				"Valid arguments are:%s", *quotingWord, valid)
+		}
+		quotingStyle = style
+	case os.Getenv("QUOTING_STYLE") != "":
+		s := os.Getenv("QUOTING_STYLE")
+		if style, ok := quotearg.ParseStyle(s); ok {
+			quotingStyle = style
+			break
+		}
+		cli.Warnf("ignoring invalid value of environment variable QUOTING_STYLE: '%s'", s)
+		if tty {
+			quotingStyle = quotearg.ShellEscape
+		}
+	case tty:
+		quotingStyle = quotearg.ShellEscape
+	}
+
+	alignQuotes = (format == formatLong || (format == formatColumns || format == formatAcross) && lineLength > 0) &&
+		(quotingStyle == quotearg.Shell || quotingStyle == quotearg.ShellEscape || quotingStyle == quotearg.CMaybe)
+
+	if quotingStyle == quotearg.Escape {
+		nameQuoteChars = " "
+	}
+	switch indicatorStyle {
+	case indicatorFileType:
+		nameQuoteChars += "*=>@|"
+	case indicatorClassify:
+		nameQuoteChars += "=>@|"
+	}
+}
+
+// quoteName quotes name in the chosen style, also quoting the characters
+// in also, and tells whether that changed it.
+func quoteName(name, also string) (string, bool) {
+	q := name
+	if quotingStyle != quotearg.Literal {
+		q = quotearg.Quote(name, quotingStyle, also)
+	}
+	// As in GNU ls, a name only counts as quoted when its start or length
+	// changed.
+	quoted := len(q) != len(name) || q != "" && q[0] != name[0]
+
+	switch quotingStyle {
+	case quotearg.Literal, quotearg.Shell, quotearg.ShellAlways:
+		if hideControlChars {
+			q = hideUnprintable(q)
+		}
+	}
+	return q, quoted
+}
+
+// hideUnprintable replaces each character of s that cannot be printed,
+// and each byte that is not UTF-8, with ?.
+func hideUnprintable(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		r, n := utf8.DecodeRuneInString(s[i:])
+		if quotearg.IsPrint(r) {
+			b.WriteString(s[i : i+n])
+		} else {
+			b.WriteByte('?')
+		}
+		i += n
+	}
+	return b.String()
+}
+
+// anyQuoted tells whether the name of any of files is quoted when listed.
+func anyQuoted(files []FileInfo) bool {
+	for _, file := range files {
+		if _, quoted := quoteName(file.Path, nameQuoteChars); quoted {
+			return true
+		}
+	}
+	return false
+}
+
+// dirHeader returns the line naming a directory before its listing.
+func dirHeader(path string) string {
+	name, _ := quoteName(path, ":")
+	return name + ":"
+}
+
+// nameWidth returns how many columns name takes once quoted.
+func nameWidth(name string) int {
+	q, _ := quoteName(name, nameQuoteChars)
+	return term.StringWidth(q)
+}
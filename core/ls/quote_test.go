@@ -0,0 +1,124 @@
+package ls
+
+import (
+	"os"
+	"testing"
+
+	"github.com/ilnarildarovuch/CoreUtils-On-GO/internal/quotearg"
+	"github.com/ilnarildarovuch/CoreUtils-On-GO/internal/term"
+)
+
+// setQuoting parses args and QUOTING_STYLE as ls does, from the defaults.
+func setQuoting(t *testing.T, env string, args ...string) {
+	t.Setenv("QUOTING_STYLE", env)
+	*quotingWord, controlChars = "", ""
+	*classify, *fileType, *slash, *indicators = "", false, false, ""
+	quotingStyle, indicatorStyle, nameQuoteChars = quotearg.Literal, indicatorNone, ""
+
+	flags.Parse(args)
+	parseIndicatorStyle()
+	parseQuoting()
+}
+
+// Expected results come from GNU ls -1 in the C.UTF-8 locale, writing to a
+// pipe.
+func TestQuoteName(t *testing.T) {
+	if term.IsTerminal(os.Stdout) {
+		t.Skip("the defaults differ on a terminal")
+	}
+	defer setQuoting(t, "")
+
+	tests := []struct {
+		env    string
+		args   []string
+		name   string
+		want   string
+		quoted bool
+	}{
+		{"", nil, "a b", "a b", false},
+		{"", nil, "tab\there", "tab\there", false},
+		{"", nil, "é", "é", false},
+		{"", []string{"-q"}, "new\nline", "new?line", false},
+		{"", []string{"-q"}, "\x01", "?", false},
+		{"", []string{"-q"}, "é", "é", false},
+		{"", []string{"-q", "--show-control-chars"}, "tab\there", "tab\there", false},
+		{"", []string{"-b"}, "a b", `a\ b`, true},
+		{"", []string{"-b"}, "tab\there", `tab\there`, true},
+		{"", []string{"-b"}, "a:b", "a:b", false},
+		{"", []string{"-Q"}, `say "hi"`, `"say \"hi\""`, true},
+		{"", []string{"-Q"}, "ok", `"ok"`, true},
+		// The last of -b, -N, -Q and --quoting-style wins.
+		{"", []string{"-b", "-N"}, "a b", "a b", false},
+		{"", []string{"-N", "-Q"}, "ok", `"ok"`, true},
+		{"", []string{"-Q", "--quoting-style=shell"}, "ok", "ok", false},
+		{"", []string{"--quoting-style=shell"}, "it's", `"it's"`, true},
+		{"", []string{"--quoting-style=shell"}, "x*", "'x*'", true},
+		{"", []string{"--quoting-style=shell", "-q"}, "tab\there", "'tab?here'", true},
+		{"", []string{"--quoting-style=shell-always"}, "ok", "'ok'", true},
+		{"", []string{"--quoting-style=shell-escape"}, "tab\there", `'tab'$'\t''here'`, true},
+		{"", []string{"--quoting-style=shell-escape"}, "\x01", `''$'\001'`, true},
+		{"", []string{"--quoting-style=shell-escape"}, "a:b", "a:b", false},
+		{"", []string{"--quoting-style=shell-escape-always"}, "a:b", "'a:b'", true},
+		{"", []string{"--quoting-style=c"}, "new\nline", `"new\nline"`, true},
+		{"", []string{"--quoting-style=escape"}, "é", "é", false},
+		{"", []string{"--quoting-style=locale"}, "it's", `'it\'s'`, true},
+		{"", []string{"--quoting-style=literal", "-q"}, "tab\there", "tab?here", false},
+		// With -b, the indicators appended are escaped in names too.
+		{"", []string{"-bF"}, "p|q", `p\|q`, true},
+		{"", []string{"-bF"}, "x*", "x*", false},
+		{"", []string{"-b", "--file-type"}, "x*", `x\*`, true},
+		{"", []string{"-b", "-p"}, "p|q", "p|q", false},
+		// QUOTING_STYLE applies without an option.
+		{"c", nil, "ok", `"ok"`, true},
+		{"c", []string{"-N"}, "ok", "ok", false},
+	}
+	for _, tt := range tests {
+		setQuoting(t, tt.env, tt.args...)
+		got, quoted := quoteName(tt.name, nameQuoteChars)
+		if got != tt.want || quoted != tt.quoted {
+			t.Errorf("QUOTING_STYLE=%q ls %q: %q is listed as %q, quoted %v, want %q, %v",
+				tt.env, tt.args, tt.name, got, quoted, tt.want, tt.quoted)
+		}
+	}
+}
+
+func TestDirHeader(t *testing.T) {
+	defer setQuoting(t, "")
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"-N"}, "./d:ir:"},
+		{[]string{"-b"}, `./d\:ir:`},
+		{[]string{"--quoting-style=shell-escape"}, "'./d:ir':"},
+	}
+	for _, tt := range tests {
+		setQuoting(t, "", tt.args...)
+		if got := dirHeader("./d:ir"); got != tt.want {
+			t.Errorf("ls -R %q: header %q, want %q", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestNameWidth(t *testing.T) {
+	defer setQuoting(t, "")
+
+	tests := []struct {
+		args []string
+		name string
+		want int
+	}{
+		{[]string{"-N"}, "abc", 3},
+		{[]string{"-N"}, "é", 1},
+		{[]string{"-N"}, "日本", 4},
+		{[]string{"-b"}, "a b", 4},
+		{[]string{"--quoting-style=shell-escape"}, "tab\there", 16},
+	}
+	for _, tt := range tests {
+		setQuoting(t, "", tt.args...)
+		if got := nameWidth(tt.name); got != tt.want {
+			t.Errorf("ls %q: width of %q = %d, want %d", tt.args, tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package ls
+
+import (
+	"os"
+	"syscall"
+
+	"github.com/ilnarildarovuch/CoreUtils-On-GO/internal/cli"
+	"github.com/ilnarildarovuch/CoreUtils-On-GO/internal/human"
+)
+
+// The blocks of -s and the totals are counted in units of blockSize
+// bytes, and the sizes of the long format in units of fileBlockSize.
+var (
+	blockSize     uint64 = 1024
+	blockOpts     human.Options
+	fileBlockSize uint64 = 1
+	fileOpts      human.Options
+)
+
+// parseSizeOptions settles -h, --si, --block-size and -k. Without them,
+// $LS_BLOCK_SIZE or $BLOCK_SIZE set both units, and $BLOCKSIZE the one of
+// the blocks; POSIXLY_CORRECT makes blocks 512 bytes.
+func parseSizeOptions() {
+	if *sizeSpec != "" {
+		size, opts, err := human.ParseBlockSize(*sizeSpec)
+		if err == human.ErrRange {
+			cli.Warnf("--block-size argument '%s' too large", *sizeSpec)
+			os.Exit(cli.ExitTrouble)
+		} else if err != nil {
+			cli.Warnf("invalid --block-size argument '%s'", *sizeSpec)
+			os.Exit(cli.ExitTrouble)
+		}
+		blockSize, blockOpts = size, opts
+		fileBlockSize, fileOpts = size, opts
+		return
+	}
+
+	if os.Getenv("POSIXLY_CORRECT") != "" {
+		blockSize = 512
+	}
+	for _, name := range []string{"LS_BLOCK_SIZE", "BLOCK_SIZE", "BLOCKSIZE"} {
+		spec := os.Getenv(name)
+		if spec == "" {
+			continue
+		}
+		// An invalid value leaves the default size, but still counts.
+		if size, opts, err := human.ParseBlockSize(spec); err == nil {
+			blockSize, blockOpts = size, opts
+		}
+		if name != "BLOCKSIZE" {
+			fileBlockSize, fileOpts = blockSize, blockOpts
+		}
+		break
+	}
+	if *kibibytes {
+		blockSize, blockOpts = 1024, 0
+	}
+}
+
+// blocks returns the 512-byte blocks allocated to file, and false if it
+// is not known.
+func blocks(file FileInfo) (uint64, bool) {
+	stat, ok := file.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, false
+	}
+	return uint64(stat.Blocks), true
+}
+
+// formatBlocks returns the space allocated to file as -s shows it.
+func formatBlocks(file FileInfo) string {
+	n, ok := blocks(file)
+	if !ok {
+		return "?"
+	}
+	return human.Readable(n, 512, blockSize, blockOpts)
+}
+
+// formatSize returns the size of file as the long format shows it.
+func formatSize(file FileInfo) string {
+	return human.Readable(uint64(file.Size()), 1, fileBlockSize, fileOpts)
+}
+
+// formatTotal returns the blocks allocated to files, for the total line.
+func formatTotal(files []FileInfo) string {
+	var total uint64
+	for _, file := range files {
+		n, _ := blocks(file)
+		total += n
+	}
+	return human.Readable(total, 512, blockSize, blockOpts)
+}
@@ -0,0 +1,79 @@
+package ls
+
+import (
+	"syscall"
+	"testing"
+)
+
+// statInfo is a testInfo with the stat data blocks reads.
+type statInfo struct {
+	testInfo
+	stat syscall.Stat_t
+}
+
+func (i statInfo) Sys() interface{} { return &i.stat }
+
+// Expected results come from GNU ls -ls on a 5000-byte file taking 16
+// blocks of 512 bytes.
+func TestSizeOptions(t *testing.T) {
+	defer func(spec string, k bool) { *sizeSpec, *kibibytes = spec, k }(*sizeSpec, *kibibytes)
+	defer func(b, f uint64) { blockSize, blockOpts, fileBlockSize, fileOpts = b, 0, f, 0 }(blockSize, fileBlockSize)
+
+	file := FileInfo{FileInfo: statInfo{testInfo{name: "f", size: 5000}, syscall.Stat_t{Blocks: 16}}}
+	tests := []struct {
+		env          []string
+		spec         string
+		k            bool
+		blocks, size string
+	}{
+		{nil, "", false, "8", "5000"},
+		{nil, "K", false, "8K", "5K"},
+		{nil, "human-readable", false, "8.0K", "4.9K"},
+		{nil, "si", false, "8.2k", "5.0k"},
+		{nil, "", true, "8", "5000"},
+		{nil, "1M", false, "1", "1"},
+		{nil, "KB", false, "9kB", "5kB"},
+		// ' groups thousands, with no separator in the C locale.
+		{nil, "'1", false, "8192", "5000"},
+		{[]string{"POSIXLY_CORRECT", "1"}, "", false, "16", "5000"},
+		{[]string{"POSIXLY_CORRECT", "1"}, "", true, "8", "5000"},
+		{[]string{"POSIXLY_CORRECT", "1"}, "K", false, "8K", "5K"},
+		// LS_BLOCK_SIZE and BLOCK_SIZE set both units, BLOCKSIZE only the
+		// one of the blocks; --block-size overrides them all.
+		{[]string{"LS_BLOCK_SIZE", "1000"}, "", false, "9", "5"},
+		{[]string{"LS_BLOCK_SIZE", "1000"}, "", true, "8", "5"},
+		{[]string{"LS_BLOCK_SIZE", "1000"}, "K", false, "8K", "5K"},
+		{[]string{"BLOCK_SIZE", "K"}, "", false, "8K", "5K"},
+		{[]string{"BLOCK_SIZE", "K"}, "", true, "8", "5K"},
+		{[]string{"BLOCK_SIZE", "human-readable"}, "", false, "8.0K", "4.9K"},
+		{[]string{"BLOCK_SIZE", "human-readable"}, "", true, "8", "4.9K"},
+		{[]string{"BLOCKSIZE", "1000"}, "", false, "9", "5000"},
+		{[]string{"BLOCKSIZE", "1000"}, "", true, "8", "5000"},
+		{[]string{"LS_BLOCK_SIZE", "1000", "BLOCK_SIZE", "K"}, "", false, "9", "5"},
+		// An invalid value still counts, with the default unit.
+		{[]string{"LS_BLOCK_SIZE", "junk"}, "", false, "8", "5"},
+		{[]string{"LS_BLOCK_SIZE", "junk", "BLOCK_SIZE", "K"}, "", false, "8", "5"},
+	}
+	for _, tt := range tests {
+		for _, name := range []string{"POSIXLY_CORRECT", "LS_BLOCK_SIZE", "BLOCK_SIZE", "BLOCKSIZE"} {
+			t.Setenv(name, "")
+		}
+		for i := 0; i < len(tt.env); i += 2 {
+			t.Setenv(tt.env[i], tt.env[i+1])
+		}
+		*sizeSpec, *kibibytes = tt.spec, tt.k
+		blockSize, blockOpts, fileBlockSize, fileOpts = 1024, 0, 1, 0
+
+		parseSizeOptions()
+		blocks, size := formatBlocks(file), formatSize(file)
+		if blocks != tt.blocks || size != tt.size {
+			t.Errorf("%v ls -ls --block-size=%q -k=%v: blocks %s, size %s, want %s, %s",
+				tt.env, tt.spec, tt.k, blocks, size, tt.blocks, tt.size)
+		}
+	}
+
+	blockSize, blockOpts = 1024, 0
+	if total := formatTotal([]FileInfo{file, file}); total != "16" {
+		t.Errorf("formatTotal of two files = %s, want 16", total)
+	}
+}
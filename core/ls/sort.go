@@ -0,0 +1,161 @@
+package ls
+
+import (
+	"cmp"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/ilnarildarovuch/CoreUtils-On-GO/internal/filevercmp"
+)
+
+// Sort orders. Ties, and all names, are compared byte by byte, as in the
+// C locale, whatever the locale settings are.
+const (
+	sortName = iota
+	sortNone
+	sortSize
+	sortTime
+	sortExtension
+	sortVersion
+	sortWidth
+)
+
+var sortType = sortName
+
+// Patterns of names -I, -B and --hide leave out.
+var ignorePatterns, hidePatterns []string
+
+// parseSortOptions settles --sort and -S, -t, -U, -v and -X, of which the
+// last one given wins. Without any of them, -c, -u and --time sort by time
+// unless the format is long.
+func parseSortOptions() {
+	switch *sortWord {
+	case "":
+		if explicitTime && format != formatLong {
+			sortType = sortTime
+		}
+	case "none":
+		sortType = sortNone
+	case "time":
+		sortType = sortTime
+	case "size":
+		sortType = sortSize
+	case "extension":
+		sortType = sortExtension
+	case "version":
+		sortType = sortVersion
+	case "width":
+		sortType = sortWidth
+	default:
+		flags.UsageError("invalid argument '%s' for '--sort'\n"+
+			"Valid arguments are:\n"+
+			"  - 'none'\n"+
+			"  - 'time'\n"+
+			"  - 'size'\n"+
+			"  - 'extension'\n"+
+			"  - 'version'\n"+
+			"  - 'width'", *sortWord)
+	}
+	if *ignoreBackups {
+		ignorePatterns = append(ignorePatterns, "*~", ".*~")
+	}
+}
+
+// sortFiles puts files in the chosen order, reversed with -r, and with
+// --group-directories-first the directories before the rest.
+func sortFiles(files []FileInfo) {
+	if sortType == sortNone {
+		return
+	}
+	sort.SliceStable(files, func(i, j int) bool {
+		if *groupDirs {
+			if a, b := isDirectory(files[i]), isDirectory(files[j]); a != b {
+				return a
+			}
+		}
+		c := compareFiles(files[i], files[j])
+		if *reverse {
+			c = -c
+		}
+		return c < 0
+	})
+}
+
+// compareFiles compares a and b by the sort key, then by name.
+func compareFiles(a, b FileInfo) int {
+	switch sortType {
+	case sortSize:
+		if c := cmp.Compare(b.Size(), a.Size()); c != 0 {
+			return c
+		}
+	case sortTime:
+		at, _ := fileTime(a)
+		bt, _ := fileTime(b)
+		if c := bt.Compare(at); c != 0 {
+			return c
+		}
+	case sortExtension:
+		if c := strings.Compare(extension(a.Path), extension(b.Path)); c != 0 {
+			return c
+		}
+	case sortVersion:
+		if c := filevercmp.Compare(a.Path, b.Path); c != 0 {
+			return c
+		}
+	case sortWidth:
+		if c := cmp.Compare(nameWidth(a.Path), nameWidth(b.Path)); c != 0 {
+			return c
+		}
+	}
+	return strings.Compare(a.Path, b.Path)
+}
+
+// extension returns name from its last dot on, or "" if it has none.
+func extension(name string) string {
+	if i := strings.LastIndexByte(name, '.'); i >= 0 {
+		return name[i:]
+	}
+	return ""
+}
+
+// isDirectory reports whether file is a directory or a symbolic link to
+// one, which --group-directories-first puts first.
+func isDirectory(file FileInfo) bool {
+	if file.Mode()&os.ModeSymlink != 0 {
+		return file.Target != nil && file.Target.IsDir()
+	}
+	return file.IsDir()
+}
+
+func shouldSkipEntry(name string) bool {
+	if matchAny(ignorePatterns, name) {
+		return true
+	}
+	if *all {
+		return false
+	}
+	if *almostAll {
+		return isDotOrDotDot(name)
+	}
+	return name[0] == '.' || matchAny(hidePatterns, name)
+}
+
+// matchAny reports whether name matches one of the shell patterns. As
+// with fnmatch's FNM_PERIOD, a leading dot must be matched by a dot.
+func matchAny(patterns []string, name string) bool {
+	for _, p := range patterns {
+		if strings.HasPrefix(name, ".") && !strings.HasPrefix(p, ".") {
+			continue
+		}
+		if ok, _ := path.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func isDotOrDotDot(name string) bool {
+	return name == "." || name == ".."
+}
@@ -0,0 +1,116 @@
+package ls
+
+import (
+	"io/fs"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testInfo is a file that exists only for the tests.
+type testInfo struct {
+	name string
+	size int64
+	mode fs.FileMode
+}
+
+func (i testInfo) Name() string       { return i.name }
+func (i testInfo) Size() int64        { return i.size }
+func (i testInfo) Mode() fs.FileMode  { return i.mode }
+func (i testInfo) ModTime() time.Time { return time.Time{} }
+func (i testInfo) IsDir() bool        { return i.mode.IsDir() }
+func (i testInfo) Sys() interface{}   { return nil }
+
+// testFiles makes files of names: a trailing / makes a directory, and
+// "name@dir" or "name@file" a symbolic link to one.
+func testFiles(names ...string) []FileInfo {
+	var files []FileInfo
+	for _, name := range names {
+		file := FileInfo{Path: name}
+		switch {
+		case strings.HasSuffix(name, "/"):
+			file.Path = strings.TrimSuffix(name, "/")
+			file.FileInfo = testInfo{name: file.Path, mode: fs.ModeDir | 0755}
+		case strings.Contains(name, "@"):
+			var target string
+			file.Path, target, _ = strings.Cut(name, "@")
+			file.FileInfo = testInfo{name: file.Path, mode: fs.ModeSymlink | 0777}
+			if target == "dir" {
+				file.Target = testInfo{name: target, mode: fs.ModeDir | 0755}
+			} else {
+				file.Target = testInfo{name: target, mode: 0644}
+			}
+		default:
+			file.FileInfo = testInfo{name: name, mode: 0644, size: int64(len(name))}
+		}
+		files = append(files, file)
+	}
+	return files
+}
+
+func TestSortFiles(t *testing.T) {
+	defer func(t int, g, r bool) { sortType, *groupDirs, *reverse = t, g, r }(sortType, *groupDirs, *reverse)
+
+	tests := []struct {
+		sortType  int
+		groupDirs bool
+		reverse   bool
+		names     []string
+		want      []string
+	}{
+		{sortName, false, false, []string{"b", "B", "a", "_a", ".a"}, []string{".a", "B", "_a", "a", "b"}},
+		{sortName, false, true, []string{"b", "a", "c"}, []string{"c", "b", "a"}},
+		{sortNone, false, true, []string{"b", "a", "c"}, []string{"b", "a", "c"}},
+		// -X: the extension runs from the last dot; names without one
+		// come first.
+		{sortExtension, false, false,
+			[]string{"b.txt", "a", "c.go", "a.txt", "d.tar.gz", ".rc", "e."},
+			[]string{"a", "e.", "c.go", "d.tar.gz", ".rc", "a.txt", "b.txt"}},
+		{sortVersion, false, false,
+			[]string{"a10", "a2", "a1.10", "a1.9", "a1", "a1a", "file-1.2.tar.gz", "file-1.10.tar.gz"},
+			[]string{"a1", "a1a", "a1.9", "a1.10", "a2", "a10", "file-1.2.tar.gz", "file-1.10.tar.gz"}},
+		{sortWidth, false, false, []string{"ccc", "dd", "a", "bb"}, []string{"a", "bb", "dd", "ccc"}},
+		{sortWidth, false, true, []string{"ccc", "dd", "a", "bb"}, []string{"ccc", "dd", "bb", "a"}},
+		// -S is largest first; these files are as big as their names are
+		// long.
+		{sortSize, false, false, []string{"bb", "a", "ccc", "aa"}, []string{"ccc", "aa", "bb", "a"}},
+		// Links to directories are grouped with them, and -r leaves the
+		// groups in place.
+		{sortName, true, false, []string{"a", "z/", "b", "la@file", "y/", "ly@dir"},
+			[]string{"ly", "y", "z", "a", "b", "la"}},
+		{sortName, true, true, []string{"a", "z/", "b", "la@file", "y/", "ly@dir"},
+			[]string{"z", "y", "ly", "la", "b", "a"}},
+		{sortExtension, true, false, []string{"b.c", "a.d/", "c.a", "b/"}, []string{"b", "a.d", "c.a", "b.c"}},
+		// -U does not group either.
+		{sortNone, true, false, []string{"a", "z/", "b"}, []string{"a", "z", "b"}},
+	}
+	for _, tt := range tests {
+		sortType, *groupDirs, *reverse = tt.sortType, tt.groupDirs, tt.reverse
+		files := testFiles(tt.names...)
+		sortFiles(files)
+		var got []string
+		for _, file := range files {
+			got = append(got, file.Path)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("sort %d (group %v, reverse %v) of %q = %q, want %q",
+				tt.sortType, tt.groupDirs, tt.reverse, tt.names, got, tt.want)
+		}
+	}
+}
+
+func TestCompareFilesTies(t *testing.T) {
+	defer func(saved int) { sortType = saved }(sortType)
+
+	// Equal keys fall back to the names.
+	for _, sortType = range []int{sortName, sortSize, sortExtension, sortVersion, sortWidth} {
+		files := testFiles("b.x", "a.x")
+		if c := compareFiles(files[0], files[1]); c <= 0 {
+			t.Errorf("sort %d: compareFiles(b.x, a.x) = %d", sortType, c)
+		}
+		if c := compareFiles(files[0], files[0]); c != 0 {
+			t.Errorf("sort %d: compareFiles(b.x, b.x) = %d", sortType, c)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package ls
+
+import (
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+// The statx(2) call is the only way to the birth time of a file; the
+// syscall package has neither it nor its structure.
+
+const (
+	atFDCWD           = -100
+	atSymlinkNoFollow = 0x100
+	statxBtime        = 0x800
+)
+
+type statxTimestamp struct {
+	Sec  int64
+	Nsec uint32
+	_    int32
+}
+
+type statxT struct {
+	Mask           uint32
+	Blksize        uint32
+	Attributes     uint64
+	Nlink          uint32
+	Uid            uint32
+	Gid            uint32
+	Mode           uint16
+	_              uint16
+	Ino            uint64
+	Size           uint64
+	Blocks         uint64
+	AttributesMask uint64
+	Atime          statxTimestamp
+	Btime          statxTimestamp
+	Ctime          statxTimestamp
+	Mtime          statxTimestamp
+	_              [128]byte
+}
+
+// birthTime returns when path was created, if the kernel and the file
+// system record it.
+func birthTime(path string, follow bool) (time.Time, bool) {
+	if sysStatx < 0 {
+		return time.Time{}, false
+	}
+	p, err := syscall.BytePtrFromString(path)
+	if err != nil {
+		return time.Time{}, false
+	}
+	flags := atSymlinkNoFollow
+	if follow {
+		flags = 0
+	}
+	var stx statxT
+	nr, fd := sysStatx, atFDCWD
+	_, _, errno := syscall.Syscall6(uintptr(nr), uintptr(fd), uintptr(unsafe.Pointer(p)), uintptr(flags), statxBtime, uintptr(unsafe.Pointer(&stx)), 0)
+	if errno != 0 || stx.Mask&statxBtime == 0 {
+		return time.Time{}, false
+	}
+	return time.Unix(stx.Btime.Sec, int64(stx.Btime.Nsec)), true
+}
@@ -0,0 +1,3 @@
+package ls
+
+const sysStatx = 397
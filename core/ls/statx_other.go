@@ -0,0 +1,6 @@
+//go:build !amd64 && !arm64 && !riscv64 && !loong64 && !386 && !arm
+
+package ls
+
+// sysStatx is unknown here; birth times are reported as unavailable.
+const sysStatx = -1
@@ -0,0 +1,143 @@
+package ls
+
+import (
+	"os"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/ilnarildarovuch/CoreUtils-On-GO/internal/strftime"
+)
+
+// Which timestamp the long format shows and -t sorts on.
+const (
+	timeMtime = iota
+	timeAtime
+	timeCtime
+	timeBirth
+)
+
+// timeType is set by -c, -u and --time as they are parsed, so that the
+// last one given wins; explicitTime records that one was.
+var (
+	timeType     = timeMtime
+	explicitTime bool
+)
+
+// The long format shows files changed in the last six months with
+// recentFormat, and older ones, or ones in the future, with oldFormat.
+var (
+	recentFormat string
+	oldFormat    string
+	now          time.Time
+)
+
+const halfYear = 31556952 / 2 * time.Second
+
+// setTime returns the option function that chooses time t.
+func setTime(t int) func() {
+	return func() { timeType, explicitTime = t, true }
+}
+
+// parseTimeWord sets the time from the WORD of --time.
+func parseTimeWord(word string) {
+	switch word {
+	case "atime", "access", "use":
+		timeType = timeAtime
+	case "ctime", "status":
+		timeType = timeCtime
+	case "birth", "creation":
+		timeType = timeBirth
+	case "mtime", "modification":
+		timeType = timeMtime
+	default:
+		flags.UsageError("invalid argument '%s' for '--time'\n"+
+			"Valid arguments are:\n"+
+			"  - 'atime', 'access', 'use'\n"+
+			"  - 'ctime', 'status'\n"+
+			"  - 'birth', 'creation'\n"+
+			"  - 'mtime', 'modification'", word)
+	}
+	explicitTime = true
+}
+
+// parseTimeOptions settles --time-style, which --full-time sets too, and
+// $TIME_STYLE.
+func parseTimeOptions() {
+	style := *timeStyle
+	if style == "" {
+		style = os.Getenv("TIME_STYLE")
+	}
+	if strings.HasPrefix(style, "posix-") {
+		// A posix- style applies outside the POSIX locale only, and ls
+		// knows no other.
+		style = "locale"
+	}
+	switch style {
+	case "full-iso":
+		recentFormat = "%Y-%m-%d %H:%M:%S.%N %z"
+		oldFormat = recentFormat
+	case "long-iso":
+		recentFormat = "%Y-%m-%d %H:%M"
+		oldFormat = recentFormat
+	case "iso":
+		recentFormat = "%m-%d %H:%M"
+		oldFormat = "%Y-%m-%d "
+	case "locale", "":
+		recentFormat = "%b %e %H:%M"
+		oldFormat = "%b %e  %Y"
+	default:
+		if !strings.HasPrefix(style, "+") {
+			flags.UsageError("invalid argument '%s' for 'time style'\n"+
+				"Valid arguments are:\n"+
+				"  - [posix-]full-iso\n"+
+				"  - [posix-]long-iso\n"+
+				"  - [posix-]iso\n"+
+				"  - [posix-]locale\n"+
+				"  - +FORMAT (e.g., +%%H:%%M) for a 'date'-style format", style)
+		}
+		// +FORMAT, or +OLD-FORMAT<newline>RECENT-FORMAT.
+		format := style[1:]
+		oldFormat, recentFormat = format, format
+		if i := strings.IndexByte(format, '\n'); i >= 0 {
+			if strings.IndexByte(format[i+1:], '\n') >= 0 {
+				flags.UsageError("invalid time style format '%s'", format)
+			}
+			oldFormat, recentFormat = format[:i], format[i+1:]
+		}
+	}
+
+	now = time.Now()
+}
+
+// fileTime returns the timestamp of file that ls shows, or false when the
+// file has none, as for a birth time the file system does not keep.
+func fileTime(file FileInfo) (time.Time, bool) {
+	stat, ok := file.Sys().(*syscall.Stat_t)
+	switch {
+	case timeType == timeBirth:
+		return file.Birth, !file.Birth.IsZero()
+	case timeType == timeAtime && ok:
+		return time.Unix(stat.Atim.Unix()), true
+	case timeType == timeCtime && ok:
+		return time.Unix(stat.Ctim.Unix()), true
+	}
+	return file.ModTime(), true
+}
+
+// formatTime formats a timestamp for the long format; "?" stands for an
+// unknown one.
+func formatTime(t time.Time, ok bool) string {
+	if !ok {
+		return "?"
+	}
+	if t.After(now) {
+		// Files written since ls started are not in the future.
+		now = time.Now()
+	}
+	format := oldFormat
+	if t.After(now.Add(-halfYear)) && !t.After(now) {
+		format = recentFormat
+	}
+	return strftime.Format(format, t.Local())
+}
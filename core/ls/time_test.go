@@ -0,0 +1,79 @@
+package ls
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimeOptions(t *testing.T) {
+	defer func(s string) { *timeStyle, timeType, explicitTime = s, timeMtime, false }(*timeStyle)
+
+	tests := []struct {
+		args     []string
+		time     int
+		explicit bool
+	}{
+		{nil, timeMtime, false},
+		{[]string{"-l"}, timeMtime, false},
+		// The last of -c, -u and --time wins.
+		{[]string{"-u", "-c"}, timeCtime, true},
+		{[]string{"-c", "-u"}, timeAtime, true},
+		{[]string{"--time=atime", "-c"}, timeCtime, true},
+		{[]string{"-c", "--time=use"}, timeAtime, true},
+		{[]string{"-u", "--time=birth"}, timeBirth, true},
+		{[]string{"-u", "--time=mtime"}, timeMtime, true},
+	}
+	for _, tt := range tests {
+		timeType, explicitTime = timeMtime, false
+		flags.Parse(tt.args)
+		if timeType != tt.time || explicitTime != tt.explicit {
+			t.Errorf("ls %q: time %d, explicit %v, want %d, %v", tt.args, timeType, explicitTime, tt.time, tt.explicit)
+		}
+	}
+}
+
+func TestTimeStyles(t *testing.T) {
+	defer func(s string, l *time.Location) { *timeStyle, time.Local = s, l }(*timeStyle, time.Local)
+	time.Local = time.UTC
+
+	recent := time.Date(2026, time.October, 1, 9, 5, 7, 123456789, time.UTC)
+	old := time.Date(2025, time.March, 4, 5, 6, 7, 0, time.UTC)
+	tests := []struct {
+		env         string
+		args        []string
+		recent, old string
+	}{
+		{"", nil, "Oct  1 09:05", "Mar  4  2025"},
+		{"", []string{"--time-style=locale"}, "Oct  1 09:05", "Mar  4  2025"},
+		{"", []string{"--time-style=long-iso"}, "2026-10-01 09:05", "2025-03-04 05:06"},
+		{"", []string{"--time-style=iso"}, "10-01 09:05", "2025-03-04 "},
+		{"", []string{"--time-style=full-iso"}, "2026-10-01 09:05:07.123456789 +0000", "2025-03-04 05:06:07.000000000 +0000"},
+		{"", []string{"--time-style=posix-iso"}, "Oct  1 09:05", "Mar  4  2025"},
+		{"", []string{"--time-style=+%Y"}, "2026", "2025"},
+		{"", []string{"--time-style=+%Y\n%m"}, "10", "2025"},
+		// --full-time and --time-style: the last one wins.
+		{"", []string{"--time-style=iso", "--full-time"}, "2026-10-01 09:05:07.123456789 +0000", "2025-03-04 05:06:07.000000000 +0000"},
+		{"", []string{"--full-time", "--time-style=iso"}, "10-01 09:05", "2025-03-04 "},
+		// TIME_STYLE applies without either.
+		{"long-iso", nil, "2026-10-01 09:05", "2025-03-04 05:06"},
+		{"long-iso", []string{"--time-style=iso"}, "10-01 09:05", "2025-03-04 "},
+		{"long-iso", []string{"--full-time"}, "2026-10-01 09:05:07.123456789 +0000", "2025-03-04 05:06:07.000000000 +0000"},
+	}
+	for _, tt := range tests {
+		t.Setenv("TIME_STYLE", tt.env)
+		*timeStyle = ""
+		flags.Parse(tt.args)
+		parseTimeOptions()
+		now = time.Date(2026, time.October, 18, 12, 0, 0, 0, time.UTC)
+
+		if got := formatTime(recent, true); got != tt.recent {
+			t.Errorf("TIME_STYLE=%q ls %q: recent time %q, want %q", tt.env, tt.args, got, tt.recent)
+		}
+		if got := formatTime(old, true); got != tt.old {
+			t.Errorf("TIME_STYLE=%q ls %q: old time %q, want %q", tt.env, tt.args, got, tt.old)
+		}
+	}
+	if got := formatTime(recent, false); got != "?" {
+		t.Errorf("formatTime of an unknown time = %q", got)
+	}
+}
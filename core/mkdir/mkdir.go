@@ -0,0 +1,153 @@
+package mkdir
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/ilnarildarovuch/CoreUtils-On-GO/internal/cli"
+	"github.com/ilnarildarovuch/CoreUtils-On-GO/internal/mode"
+)
+
+const version = "1.0.0"
+
+var (
+	modeSpec string
+	parents  bool
+	verbose  bool
+	context  string
+)
+
+var flags = cli.NewFlagSet(version)
+
+func init() {
+	flags.StringVar(&modeSpec, 'm', "mode", "", "set file mode (as in chmod), not a=rwx - umask")
+	flags.BoolVar(&parents, 'p', "parents", "no error if existing, make parent directories as needed")
+	flags.BoolVar(&verbose, 'v', "verbose", "print a message for each created directory")
+	flags.StringVar(&context, 'Z', "context", "", "set SELinux/SMACK security context to `CTX`")
+}
+
+func Main() {
+	flags.Usage = usage
+	flags.Parse(os.Args[1:])
+
+	if flags.NArg() == 0 {
+		flags.UsageError("missing operand")
+	}
+
+	var m *mode.Mode
+	if modeSpec != "" {
+		var err error
+		if m, err = mode.Parse(modeSpec); err != nil {
+			cli.Fatalf("%v", err)
+		}
+	}
+
+	for _, dir := range flags.Args() {
+		if err := createDir(dir, m); err != nil {
+			cli.Failf("cannot create directory '%s': %v", dir, err)
+		}
+	}
+
+	cli.Exit()
+}
+
+func usage() {
+	w := flags.Output()
+	fmt.Fprintf(w, "Usage: %s [OPTION]... DIRECTORY...\n", cli.Name)
+	fmt.Fprintln(w, "Create the DIRECTORY(ies), if they do not already exist.")
+	fmt.Fprintln(w, "\nOptions:")
+	flags.PrintDefaults()
+	fmt.Fprintf(w, "\nExamples:\n  %s -p dir/subdir\n  %s -m 755 newdir\n", cli.Name, cli.Name)
+}
+
+func createDir(path string, m *mode.Mode) error {
+	if parents {
+		if err := createDirWithParents(path); err != nil {
+			return err
+		}
+	}
+
+	// Created with the default mode, then set exactly: the umask must not
+	// mask what -m asked for, and Mkdir cannot set the set-id bits.
+	err := os.Mkdir(path, 0777)
+	if err != nil {
+		if parents && os.IsExist(err) {
+			if info, serr := os.Stat(path); serr == nil && info.IsDir() {
+				return nil
+			}
+		}
+		return err
+	}
+	if m != nil {
+		if err := os.Chmod(path, m.Apply(os.ModeDir|0777, mode.Umask())); err != nil {
+			return err
+		}
+	}
+
+	if verbose {
+		fmt.Printf("%s: created directory '%s'\n", cli.Name, path)
+	}
+
+	return nil
+}
+
+// createDirWithParents creates the missing parents of path.
+func createDirWithParents(path string) error {
+	components := splitPath(path)
+	if len(components) > 0 {
+		components = components[:len(components)-1]
+	}
+
+	currentPath := ""
+	if path[0] == '/' {
+		currentPath = "/"
+	}
+
+	for _, component := range components {
+		if currentPath != "" && currentPath != "/" {
+			currentPath += "/"
+		}
+		currentPath += component
+
+		if _, err := os.Stat(currentPath); err == nil {
+			continue
+		}
+
+		err := os.Mkdir(currentPath, 0777)
+		if err != nil {
+			return err
+		}
+
+		if verbose {
+			fmt.Printf("%s: created directory '%s'\n", cli.Name, currentPath)
+		}
+
+		if context != "" {
+			// Placeholder... Eh
+		}
+	}
+
+	return nil
+}
+
+func splitPath(path string) []string {
+	var components []string
+	current := ""
+
+	for _, c := range path {
+		if c == '/' {
+			if current != "" {
+				components = append(components, current)
+				current = ""
+			}
+		} else {
+			current += string(c)
+		}
+	}
+
+	if current != "" {
+		components = append(components, current)
+	}
+
+	return components
+}
@@ -1,26 +1,28 @@
-package main
+package rm
 
 import (
-	"flag"
 	"fmt"
-	"os"
 	"io"
+	"os"
 	"path/filepath"
 	"strings"
 	"syscall"
+
+	"github.com/ilnarildarovuch/CoreUtils-On-GO/internal/cli"
+	"github.com/ilnarildarovuch/CoreUtils-On-GO/internal/term"
 )
 
 type InteractiveMode int
 
 const (
-	version = "1.0.0"
+	version                   = "1.0.0"
 	RMI_NEVER InteractiveMode = iota
 	RMI_SOMETIMES
 	RMI_ALWAYS
 )
 
 type RmOptions struct {
-	ignoreMissingFiles      bool
+	ignoreMissingFiles     bool
 	interactive            InteractiveMode
 	oneFileSystem          bool
 	removeEmptyDirectories bool
@@ -31,62 +33,63 @@ type RmOptions struct {
 }
 
 var (
-	force             = flag.Bool("f", false, "ignore nonexistent files and arguments, never prompt")
-	forceLong         = flag.Bool("force", false, "equivalent to -f")
-	interactive       = flag.Bool("i", false, "prompt before every removal")
-	interactiveOnce   = flag.Bool("I", false, "prompt once before removing more than three files, or when removing recursively")
-	interactiveOption = flag.String("interactive", "", "prompt according to WHEN: never, once (-I), or always (-i)")
-	oneFileSystem     = flag.Bool("one-file-system", false, "when removing recursively, skip directories on different file systems")
-	noPreserveRoot    = flag.Bool("no-preserve-root", false, "do not treat '/' specially")
-	preserveRoot      = flag.String("preserve-root", "", "do not remove '/' (default); with 'all', reject arguments on separate devices")
-	recursive         = flag.Bool("r", false, "remove directories and their contents recursively")
-	recursiveLong     = flag.Bool("recursive", false, "equivalent to -r")
-	recursiveAlt      = flag.Bool("R", false, "equivalent to -r")
-	dir               = flag.Bool("d", false, "remove empty directories")
-	dirLong           = flag.Bool("dir", false, "equivalent to -d")
-	verbose           = flag.Bool("v", false, "explain what is being done")
-	verboseLong       = flag.Bool("verbose", false, "equivalent to -v")
-	showVersion       = flag.Bool("version", false, "output version information and exit")
+	force             bool
+	interactive       bool
+	interactiveOnce   bool
+	interactiveOption string
+	oneFileSystem     bool
+	noPreserveRoot    bool
+	preserveRoot      string
+	recursive         bool
+	dir               bool
+	verbose           bool
 )
 
-func main() {
-	flag.Usage = usage
-	flag.Parse()
-
-	if *showVersion {
-		printVersion()
-		os.Exit(0)
-	}
+var flags = cli.NewFlagSet(version)
+
+func init() {
+	flags.BoolVar(&force, 'f', "force", "ignore nonexistent files and arguments, never prompt")
+	flags.BoolVar(&interactive, 'i', "", "prompt before every removal")
+	flags.BoolVar(&interactiveOnce, 'I', "", "prompt once before removing more than three files, or when removing recursively")
+	flags.StringVar(&interactiveOption, 0, "interactive", "", "prompt according to `WHEN`: never, once (-I), or always (-i)")
+	flags.BoolVar(&oneFileSystem, 0, "one-file-system", "when removing recursively, skip directories on different file systems")
+	flags.BoolVar(&noPreserveRoot, 0, "no-preserve-root", "do not treat '/' specially")
+	flags.Lookup("no-preserve-root").NoAbbrev = true
+	flags.Func(0, "preserve-root", "do not remove '/' (default); with '`all`', reject any command line argument on a separate device from its parent", func(v string) error {
+		if v != "" && v != "all" {
+			return fmt.Errorf("the only valid argument is 'all'")
+		}
+		noPreserveRoot, preserveRoot = false, v
+		return nil
+	})
+	flags.Lookup("preserve-root").Optional = true
+	flags.BoolVar(&recursive, 'r', "recursive", "remove directories and their contents recursively")
+	flags.BoolVar(&recursive, 'R', "", "equivalent to -r")
+	flags.BoolVar(&dir, 'd', "dir", "remove empty directories")
+	flags.BoolVar(&verbose, 'v', "verbose", "explain what is being done")
+}
 
-	handleCombinedOptions()
+func Main() {
+	flags.Usage = usage
+	flags.Parse(os.Args[1:])
 
-	args := flag.Args()
+	args := flags.Args()
 	if len(args) == 0 {
-		if *force {
+		if force {
 			os.Exit(0)
 		}
-		fmt.Fprintf(os.Stderr, "rm: missing operand\n")
-		usage()
-		os.Exit(1)
+		flags.UsageError("missing operand")
 	}
 
 	options := RmOptions{
-		ignoreMissingFiles:      *force || *forceLong,
+		ignoreMissingFiles:     force,
 		interactive:            determineInteractiveMode(),
-		oneFileSystem:          *oneFileSystem,
-		removeEmptyDirectories: *dir || *dirLong,
-		recursive:              *recursive || *recursiveLong || *recursiveAlt,
-		preserveAllRoot:        *preserveRoot == "all",
-		stdinTty:               isTerminal(os.Stdin.Fd()),
-		verbose:                *verbose || *verboseLong,
-	}
-
-	if *noPreserveRoot {
-		// Handle --no-preserve-root
-		if !strings.HasPrefix(os.Args[len(os.Args)-len(args)-1], "--no-preserve-root") {
-			fmt.Fprintf(os.Stderr, "rm: you may not abbreviate the --no-preserve-root option\n")
-			os.Exit(1)
-		}
+		oneFileSystem:          oneFileSystem,
+		removeEmptyDirectories: dir,
+		recursive:              recursive,
+		preserveAllRoot:        preserveRoot == "all",
+		stdinTty:               term.IsTerminal(os.Stdin),
+		verbose:                verbose,
 	}
 
 	if options.interactive == RMI_SOMETIMES && (options.recursive || len(args) > 3) {
@@ -95,23 +98,22 @@ func main() {
 		}
 	}
 
-	exitCode := 0
 	for _, file := range args {
 		if err := removeFile(file, &options); err != nil {
-			fmt.Fprintf(os.Stderr, "rm: %s: %v\n", file, err)
-			exitCode = 1
+			cli.Failf("%s: %v", file, err)
 		}
 	}
 
-	os.Exit(exitCode)
+	cli.Exit()
 }
 
 func usage() {
-	fmt.Fprintf(os.Stderr, "Usage: %s [OPTION]... [FILE]...\n", os.Args[0])
-	fmt.Fprintln(os.Stderr, "Remove (unlink) the FILE(s).")
-	fmt.Fprintln(os.Stderr, "\nOptions:")
-	flag.PrintDefaults()
-	fmt.Fprintf(os.Stderr, `
+	w := flags.Output()
+	fmt.Fprintf(w, "Usage: %s [OPTION]... [FILE]...\n", cli.Name)
+	fmt.Fprintln(w, "Remove (unlink) the FILE(s).")
+	fmt.Fprintln(w, "\nOptions:")
+	flags.PrintDefaults()
+	fmt.Fprintf(w, `
 By default, rm does not remove directories. Use the --recursive (-r or -R)
 option to remove each listed directory, too, along with all of its contents.
 
@@ -126,25 +128,17 @@ use one of these commands:
 If you use rm to remove a file, it might be possible to recover
 some of its contents, given sufficient expertise and/or time. For greater
 assurance that the contents are unrecoverable, consider using shred(1).
-`, os.Args[0], os.Args[0])
-}
-
-func printVersion() {
-	fmt.Printf("rm (Go implementation) %s\n", version)
-}
-
-func handleCombinedOptions() {
-	// Handled in RmOptions initialization :-)
+`, cli.Name, cli.Name)
 }
 
 func determineInteractiveMode() InteractiveMode {
 	switch {
-	case *interactive:
+	case interactive:
 		return RMI_ALWAYS
-	case *interactiveOnce:
+	case interactiveOnce:
 		return RMI_SOMETIMES
-	case *interactiveOption != "":
-		switch strings.ToLower(*interactiveOption) {
+	case interactiveOption != "":
+		switch strings.ToLower(interactiveOption) {
 		case "never", "no", "none":
 			return RMI_NEVER
 		case "once":
@@ -152,23 +146,14 @@ func determineInteractiveMode() InteractiveMode {
 		case "always", "yes":
 			return RMI_ALWAYS
 		default:
-			fmt.Fprintf(os.Stderr, "rm: invalid interactive mode: %s\n", *interactiveOption)
-			os.Exit(1)
+			flags.UsageError("invalid argument '%s' for '--interactive'", interactiveOption)
 		}
 	}
 	return RMI_NEVER
 }
 
-func isTerminal(fd uintptr) bool {
-	fileInfo, err := os.Stdin.Stat()
-	if err != nil {
-		return false
-	}
-	return (fileInfo.Mode() & os.ModeCharDevice) != 0
-}
-
 func promptOnce(nFiles int, recursive bool) bool {
-	question := fmt.Sprintf("rm: remove %d argument", nFiles)
+	question := fmt.Sprintf("%s: remove %d argument", cli.Name, nFiles)
 	if nFiles > 1 {
 		question += "s"
 	}
@@ -177,7 +162,7 @@ func promptOnce(nFiles int, recursive bool) bool {
 	}
 	question += "? "
 
-	fmt.Fprintf(os.Stderr, question)
+	fmt.Fprint(os.Stderr, question)
 	var response string
 	_, err := fmt.Scanln(&response)
 	if err != nil {
@@ -192,11 +177,6 @@ func removeFile(path string, options *RmOptions) error {
 		return fmt.Errorf("cannot remove '.' or '..'")
 	}
 
-	if strings.HasPrefix(path, "-") && path != "-" {
-		return fmt.Errorf("invalid option -- '%s'\nTry 'rm -- %s' or 'rm ./%s'", 
-			strings.TrimPrefix(path, "-"), path, path)
-	}
-
 	fileInfo, err := os.Lstat(path)
 	if err != nil {
 		if os.IsNotExist(err) && options.ignoreMissingFiles {
@@ -205,7 +185,7 @@ func removeFile(path string, options *RmOptions) error {
 		return err
 	}
 
-	if path == "/" && !*noPreserveRoot {
+	if path == "/" && !noPreserveRoot {
 		return fmt.Errorf("it is dangerous to operate recursively on '/'")
 	}
 
@@ -265,7 +245,9 @@ func removeDirectoryRecursive(path string, options *RmOptions) error {
 		return err
 	}
 
-	return filepath.Walk(path, func(currentPath string, info os.FileInfo, err error) error {
+	// Directories are removed once their contents are gone, deepest first.
+	var dirs []string
+	err = filepath.Walk(path, func(currentPath string, info os.FileInfo, err error) error {
 		if err != nil {
 			if options.ignoreMissingFiles && os.IsPermission(err) {
 				return nil
@@ -273,7 +255,7 @@ func removeDirectoryRecursive(path string, options *RmOptions) error {
 			return err
 		}
 
-		if currentPath == "/" && !*noPreserveRoot {
+		if currentPath == "/" && !noPreserveRoot {
 			return filepath.SkipDir
 		}
 
@@ -296,19 +278,29 @@ func removeDirectoryRecursive(path string, options *RmOptions) error {
 			}
 		}
 
-		if currentPath == path {
+		if info.IsDir() {
+			dirs = append(dirs, currentPath)
 			return nil
 		}
 
 		if options.verbose {
 			fmt.Printf("removed '%s'\n", currentPath)
 		}
-
-		if info.IsDir() {
-			return os.Remove(currentPath)
-		}
 		return os.Remove(currentPath)
 	})
+	if err != nil {
+		return err
+	}
+
+	for i := len(dirs) - 1; i >= 0; i-- {
+		if options.verbose {
+			fmt.Printf("removed directory '%s'\n", dirs[i])
+		}
+		if err := os.Remove(dirs[i]); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func getDevice(path string) (uint64, error) {
@@ -320,11 +312,11 @@ func getDevice(path string) (uint64, error) {
 }
 
 func confirmRemoval(path string) bool {
-	fmt.Printf("rm: remove '%s'? ", path)
+	fmt.Fprintf(os.Stderr, "%s: remove '%s'? ", cli.Name, path)
 	var response string
 	_, err := fmt.Scanln(&response)
 	if err != nil {
 		return false
 	}
 	return strings.ToLower(response) == "y" || strings.ToLower(response) == "yes"
-}
\ No newline at end of file
+}
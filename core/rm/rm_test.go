@@ -0,0 +1,44 @@
+package rm
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRemoveRecursive(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "tree")
+	for _, dir := range []string{"a/b/c", "a/d", "e"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, file := range []string{"f", "a/f", "a/b/f", "a/b/c/f"} {
+		if err := os.WriteFile(filepath.Join(root, file), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := removeFile(root, &RmOptions{}); err == nil {
+		t.Errorf("removed a directory without -r")
+	}
+	if err := removeFile(root, &RmOptions{recursive: true}); err != nil {
+		t.Fatalf("rm -r: %v", err)
+	}
+	if _, err := os.Lstat(root); !os.IsNotExist(err) {
+		t.Errorf("%s still exists after rm -r: %v", root, err)
+	}
+}
+
+func TestRemoveEmptyDirectory(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "empty")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := removeFile(dir, &RmOptions{removeEmptyDirectories: true}); err != nil {
+		t.Fatalf("rm -d: %v", err)
+	}
+	if _, err := os.Lstat(dir); !os.IsNotExist(err) {
+		t.Errorf("%s still exists after rm -d: %v", dir, err)
+	}
+}
@@ -1,11 +1,12 @@
-package main
+package touch
 
 import (
-	"flag"
 	"fmt"
 	"os"
 	"strings"
 	"time"
+
+	"github.com/ilnarildarovuch/CoreUtils-On-GO/internal/cli"
 )
 
 const (
@@ -15,44 +16,44 @@ const (
 )
 
 var (
-	accessTime        = flag.Bool("a", false, "change only the access time")
-	noCreate          = flag.Bool("c", false, "do not create any files")
-	noCreateLong      = flag.Bool("no-create", false, "equivalent to -c")
-	date              = flag.String("d", "", "parse STRING and use it instead of current time")
-	dateLong          = flag.String("date", "", "equivalent to -d")
-	noDereference     = flag.Bool("h", false, "affect each symbolic link instead of any referenced file")
-	noDereferenceLong = flag.Bool("no-dereference", false, "equivalent to -h")
-	modificationTime  = flag.Bool("m", false, "change only the modification time")
-	reference         = flag.String("r", "", "use this file's times instead of current time")
-	referenceLong     = flag.String("reference", "", "equivalent to -r")
-	timestamp         = flag.String("t", "", "use specified time instead of current time (format: [[CC]YY]MMDDhhmm[.ss])")
-	timeOption        = flag.String("time", "", "specify which time to change: atime/access/use or mtime/modify")
-	showVersion       = flag.Bool("version", false, "output version information and exit")
+	accessTime       bool
+	noCreate         bool
+	date             string
+	noDereference    bool
+	modificationTime bool
+	reference        string
+	timestamp        string
+	timeOption       string
 )
 
-func main() {
-	flag.Usage = usage
-	flag.Parse()
-
-	if *showVersion {
-		printVersion()
-		os.Exit(0)
-	}
+var flags = cli.NewFlagSet(version)
+
+func init() {
+	flags.BoolVar(&accessTime, 'a', "", "change only the access time")
+	flags.BoolVar(&noCreate, 'c', "no-create", "do not create any files")
+	flags.StringVar(&date, 'd', "date", "", "parse `STRING` and use it instead of current time")
+	flags.BoolVar(&noDereference, 'h', "no-dereference", "affect each symbolic link instead of any referenced file")
+	flags.BoolVar(&modificationTime, 'm', "", "change only the modification time")
+	flags.StringVar(&reference, 'r', "reference", "", "use this `FILE`'s times instead of current time")
+	flags.StringVar(&timestamp, 't', "", "", "use `STAMP` ([[CC]YY]MMDDhhmm[.ss]) instead of current time")
+	flags.StringVar(&timeOption, 0, "time", "", "specify which time to change: atime/access/use or mtime/modify (`WORD`)")
+}
 
-	handleCombinedOptions()
+func Main() {
+	flags.Usage = usage
+	flags.Parse(os.Args[1:])
 
-	args := flag.Args()
+	args := flags.Args()
 	if len(args) == 0 {
-		usage()
-		os.Exit(1)
+		flags.UsageError("missing file operand")
 	}
 
 	// Determine which timestamps to change
 	changeTimes := 0
-	if *accessTime {
+	if accessTime {
 		changeTimes |= ATIME
 	}
-	if *modificationTime {
+	if modificationTime {
 		changeTimes |= MTIME
 	}
 	if changeTimes == 0 {
@@ -60,15 +61,14 @@ func main() {
 	}
 
 	// Handle --time option
-	if *timeOption != "" {
-		switch strings.ToLower(*timeOption) {
+	if timeOption != "" {
+		switch strings.ToLower(timeOption) {
 		case "atime", "access", "use":
 			changeTimes = ATIME
 		case "mtime", "modify":
 			changeTimes = MTIME
 		default:
-			fmt.Fprintf(os.Stderr, "invalid time specification: %s\n", *timeOption)
-			os.Exit(1)
+			flags.UsageError("invalid argument '%s' for '--time'", timeOption)
 		}
 	}
 
@@ -76,30 +76,27 @@ func main() {
 	var newTimes [2]time.Time
 	var useCurrentTime bool
 
-	if *reference != "" {
+	if reference != "" {
 		// Use times from reference file
-		refFileInfo, err := getFileInfo(*reference, *noDereference)
+		refFileInfo, err := getFileInfo(reference, noDereference)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "failed to get attributes of %s: %v\n", *reference, err)
-			os.Exit(1)
+			cli.Fatalf("failed to get attributes of '%s': %v", reference, err)
 		}
 		newTimes[0] = getAtime(refFileInfo)
 		newTimes[1] = getMtime(refFileInfo)
-	} else if *date != "" {
+	} else if date != "" {
 		// Parse custom date string
-		t, err := parseDateTime(*date)
+		t, err := parseDateTime(date)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "invalid date format %s: %v\n", *date, err)
-			os.Exit(1)
+			cli.Fatalf("invalid date format '%s': %v", date, err)
 		}
 		newTimes[0] = t
 		newTimes[1] = t
-	} else if *timestamp != "" {
+	} else if timestamp != "" {
 		// Parse timestamp format
-		t, err := parseTimestamp(*timestamp)
+		t, err := parseTimestamp(timestamp)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "invalid timestamp format %s: %v\n", *timestamp, err)
-			os.Exit(1)
+			cli.Fatalf("invalid date format '%s': %v", timestamp, err)
 		}
 		newTimes[0] = t
 		newTimes[1] = t
@@ -107,47 +104,26 @@ func main() {
 		useCurrentTime = true
 	}
 
-	exitCode := 0
 	for _, file := range args {
 		if err := touchFile(file, changeTimes, newTimes, useCurrentTime); err != nil {
-			fmt.Fprintf(os.Stderr, "%s: %s: %v\n", os.Args[0], file, err)
-			exitCode = 1
+			cli.Failf("%s: %v", file, err)
 		}
 	}
 
-	os.Exit(exitCode)
+	cli.Exit()
 }
 
 func usage() {
-	fmt.Fprintf(os.Stderr, "touch %s\n", version)
-	fmt.Fprintf(os.Stderr, "Usage: %s [OPTION]... FILE...\n", os.Args[0])
-	fmt.Fprintln(os.Stderr, "\nUpdate the access and modification times of each FILE to the current time.")
-	fmt.Fprintln(os.Stderr, "A FILE argument that does not exist is created empty, unless -c or -h is supplied.")
-	fmt.Fprintln(os.Stderr, "A FILE argument string of - is handled specially and causes touch to change the times")
-	fmt.Fprintln(os.Stderr, "of the file associated with standard output.\n")
-	fmt.Fprintln(os.Stderr, "Options:")
-	flag.PrintDefaults()
-	fmt.Fprintf(os.Stderr, "\nExamples:\n  %s file.txt\n  %s -a -m -t 202312251200 file.txt\n",
-		os.Args[0], os.Args[0])
-}
-
-func printVersion() {
-	fmt.Printf("%s %s\n", os.Args[0], version)
-}
-
-func handleCombinedOptions() {
-	if *noCreateLong {
-		*noCreate = true
-	}
-	if *noDereferenceLong {
-		*noDereference = true
-	}
-	if *dateLong != "" {
-		*date = *dateLong
-	}
-	if *referenceLong != "" {
-		*reference = *referenceLong
-	}
+	w := flags.Output()
+	fmt.Fprintf(w, "Usage: %s [OPTION]... FILE...\n", cli.Name)
+	fmt.Fprintln(w, "\nUpdate the access and modification times of each FILE to the current time.")
+	fmt.Fprintln(w, "A FILE argument that does not exist is created empty, unless -c or -h is supplied.")
+	fmt.Fprintln(w, "A FILE argument string of - is handled specially and causes touch to change the times")
+	fmt.Fprintln(w, "of the file associated with standard output.")
+	fmt.Fprintln(w, "\nOptions:")
+	flags.PrintDefaults()
+	fmt.Fprintf(w, "\nExamples:\n  %s file.txt\n  %s -a -m -t 202312251200 file.txt\n",
+		cli.Name, cli.Name)
 }
 
 func touchFile(file string, changeTimes int, newTimes [2]time.Time, useCurrentTime bool) error {
@@ -155,11 +131,11 @@ func touchFile(file string, changeTimes int, newTimes [2]time.Time, useCurrentTi
 		return touchStdout()
 	}
 
-	fileInfo, err := getFileInfo(file, *noDereference)
+	fileInfo, err := getFileInfo(file, noDereference)
 	fileExists := err == nil
 
 	if !fileExists {
-		if *noCreate {
+		if noCreate {
 			return nil
 		}
 		f, err := os.Create(file)
@@ -167,7 +143,7 @@ func touchFile(file string, changeTimes int, newTimes [2]time.Time, useCurrentTi
 			return fmt.Errorf("cannot create file: %w", err)
 		}
 		f.Close()
-		fileInfo, err = getFileInfo(file, *noDereference)
+		fileInfo, err = getFileInfo(file, noDereference)
 		if err != nil {
 			return fmt.Errorf("cannot stat newly created file: %w", err)
 		}
@@ -199,7 +175,7 @@ func touchFile(file string, changeTimes int, newTimes [2]time.Time, useCurrentTi
 		}
 	}
 
-	if *noDereference {
+	if noDereference {
 	}
 	err = os.Chtimes(file, atime, mtime)
 	if err != nil {
@@ -284,4 +260,4 @@ func parseTimestamp(timestampStr string) (time.Time, error) {
 	default:
 		return time.Time{}, fmt.Errorf("invalid timestamp format")
 	}
-}
\ No newline at end of file
+}
@@ -1,7 +0,0 @@
-package main
-
-import "os"
-
-func main() {
-    os.Exit(0)
-}
\ No newline at end of file
@@ -0,0 +1,7 @@
+package true
+
+import "os"
+
+func Main() {
+    os.Exit(0)
+}
\ No newline at end of file
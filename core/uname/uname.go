@@ -1,17 +1,18 @@
-package main
+package uname
 
 import (
-	"flag"
 	"fmt"
-	"strings"
 	"os"
 	"runtime"
+	"strings"
 	"syscall"
+
+	"github.com/ilnarildarovuch/CoreUtils-On-GO/internal/cli"
 )
 
 const (
-	version = "1.0.0"
-	PrintKernelName        = 1 << iota
+	version         = "1.0.0"
+	PrintKernelName = 1 << iota
 	PrintNodename
 	PrintKernelRelease
 	PrintKernelVersion
@@ -21,26 +22,26 @@ const (
 	PrintOperatingSystem
 )
 
+var flags = cli.NewFlagSet(version)
+
 var (
-	all              = flag.Bool("a", false, "print all information")
-	kernelName       = flag.Bool("s", false, "print the kernel name")
-	nodename         = flag.Bool("n", false, "print the network node hostname")
-	kernelRelease    = flag.Bool("r", false, "print the kernel release")
-	kernelVersion    = flag.Bool("v", false, "print the kernel version")
-	machine          = flag.Bool("m", false, "print the machine hardware name")
-	processor        = flag.Bool("p", false, "print the processor type")
-	hardwarePlatform = flag.Bool("i", false, "print the hardware platform")
-	operatingSystem  = flag.Bool("o", false, "print the operating system")
-	showVersion      = flag.Bool("version", false, "output version information and exit")
+	all              = flags.Bool('a', "all", "print all information")
+	kernelName       = flags.Bool('s', "kernel-name", "print the kernel name")
+	nodename         = flags.Bool('n', "nodename", "print the network node hostname")
+	kernelRelease    = flags.Bool('r', "kernel-release", "print the kernel release")
+	kernelVersion    = flags.Bool('v', "kernel-version", "print the kernel version")
+	machine          = flags.Bool('m', "machine", "print the machine hardware name")
+	processor        = flags.Bool('p', "processor", "print the processor type")
+	hardwarePlatform = flags.Bool('i', "hardware-platform", "print the hardware platform")
+	operatingSystem  = flags.Bool('o', "operating-system", "print the operating system")
 )
 
-func main() {
-	flag.Usage = usage
-	flag.Parse()
+func Main() {
+	flags.Usage = usage
+	flags.Parse(os.Args[1:])
 
-	if *showVersion {
-		printVersion()
-		os.Exit(0)
+	if flags.NArg() > 0 {
+		flags.UsageError("extra operand '%s'", flags.Arg(0))
 	}
 
 	toprint := decodeSwitches()
@@ -53,25 +54,11 @@ func main() {
 }
 
 func usage() {
-	fmt.Fprintf(os.Stderr, "Usage: %s [OPTION]...\n", os.Args[0])
-	fmt.Fprintln(os.Stderr, `
-Print certain system information. With no OPTION, same as -s.
-
-Options:
-  -a, --all                print all information
-  -s, --kernel-name        print the kernel name
-  -n, --nodename           print the network node hostname
-  -r, --kernel-release     print the kernel release
-  -v, --kernel-version     print the kernel version
-  -m, --machine            print the machine hardware name
-  -p, --processor          print the processor type
-  -i, --hardware-platform  print the hardware platform
-  -o, --operating-system   print the operating system
-  --version                output version information and exit`)
-}
-
-func printVersion() {
-	fmt.Printf("%s %s\n", os.Args[0], version)
+	w := flags.Output()
+	fmt.Fprintf(w, "Usage: %s [OPTION]...\n", cli.Name)
+	fmt.Fprintln(w, "\nPrint certain system information. With no OPTION, same as -s.")
+	fmt.Fprintln(w, "\nOptions:")
+	flags.PrintDefaults()
 }
 
 func decodeSwitches() int {
@@ -112,8 +99,7 @@ func decodeSwitches() int {
 func printSystemInfo(toprint int) {
 	var utsname syscall.Utsname
 	if err := syscall.Uname(&utsname); err != nil {
-		fmt.Fprintf(os.Stderr, "%s: cannot get system name: %v\n", os.Args[0], err)
-		os.Exit(1)
+		cli.Fatalf("cannot get system name: %v", err)
 	}
 
 	printed := false
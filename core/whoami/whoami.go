@@ -0,0 +1,48 @@
+package whoami
+
+import (
+    "fmt"
+    "os"
+    "syscall"
+
+    "github.com/ilnarildarovuch/CoreUtils-On-GO/internal/cli"
+    "github.com/ilnarildarovuch/CoreUtils-On-GO/internal/users"
+)
+
+const (
+    version = "1.0.0"
+)
+
+var flags = cli.NewFlagSet(version)
+
+func Main() {
+    flags.Usage = usage
+    flags.Parse(os.Args[1:])
+
+    if flags.NArg() > 0 {
+        flags.UsageError("extra operand '%s'", flags.Arg(0))
+    }
+
+    uid := syscall.Geteuid()
+    name, err := users.UserName(uid)
+    if err != nil {
+        cli.Fatalf("cannot find name for user ID %d: %v", uid, err)
+    }
+
+    fmt.Println(name)
+}
+
+func usage() {
+    w := flags.Output()
+    fmt.Fprintf(w, "Usage: %s [OPTION]...\n", cli.Name)
+    fmt.Fprintln(w, `
+Print the user name associated with the current effective user ID.
+Same as id -un.
+
+Options:`)
+    flags.PrintDefaults()
+    fmt.Fprintln(w, `
+Examples:
+  whoami
+  whoami --version`)
+}
\ No newline at end of file
@@ -0,0 +1,43 @@
+package yes
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ilnarildarovuch/CoreUtils-On-GO/internal/cli"
+)
+
+const version = "1.0.0"
+
+var flags = cli.NewFlagSet(version)
+
+func Main() {
+	flags.Usage = usage
+	flags.Parse(os.Args[1:])
+
+	args := flags.Args()
+	output := "y"
+	if len(args) > 0 {
+		output = strings.Join(args, " ")
+	}
+
+	buf := []byte(output + "\n")
+
+	for {
+		_, err := os.Stdout.Write(buf)
+		if err != nil {
+			cli.Fatalf("write error: %v", err)
+		}
+	}
+}
+
+func usage() {
+	w := flags.Output()
+	fmt.Fprintf(w, "Usage: %s [STRING]...\n", cli.Name)
+	fmt.Fprintf(w, "  or:  %s OPTION\n", cli.Name)
+	fmt.Fprintln(w, "\nRepeatedly output a line with all specified STRING(s), or 'y'.")
+	fmt.Fprintln(w, "\nOptions:")
+	flags.PrintDefaults()
+	fmt.Fprintf(w, "\nExamples:\n  %s\n  %s hello world\n", cli.Name, cli.Name)
+}
\ No newline at end of file
@@ -0,0 +1,3 @@
+module github.com/ilnarildarovuch/CoreUtils-On-GO
+
+go 1.22
@@ -0,0 +1,51 @@
+// Package applet is the registry behind the coreutils multi-call binary:
+// every utility registers its Main under the names it answers to, and the
+// binary runs the one its argv[0] names.
+package applet
+
+import (
+	"os"
+	"sort"
+
+	"github.com/ilnarildarovuch/CoreUtils-On-GO/internal/cli"
+)
+
+var applets = make(map[string]func())
+
+// Register adds main as the applet called name.
+func Register(name string, main func()) {
+	if _, dup := applets[name]; dup {
+		panic("applet " + name + " registered twice")
+	}
+	applets[name] = main
+}
+
+// Lookup reports whether an applet called name exists.
+func Lookup(name string) bool {
+	_, ok := applets[name]
+	return ok
+}
+
+// Names returns the applet names in order.
+func Names() []string {
+	names := make([]string, 0, len(applets))
+	for name := range applets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Run runs the applet called name as if it had been started as name with
+// args, and exits with its status when it returns.
+func Run(name string, args []string) {
+	main, ok := applets[name]
+	if !ok {
+		cli.Fatalf("%s: applet not found", name)
+	}
+
+	os.Args = append([]string{name}, args...)
+	cli.Name = name
+	main()
+	cli.Exit()
+}
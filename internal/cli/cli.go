@@ -0,0 +1,54 @@
+// Package cli holds what every utility shares: its name for messages,
+// error reporting, exit codes and GNU-style option parsing.
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Exit codes, as GNU coreutils uses them.
+const (
+	ExitSuccess = 0
+	ExitFailure = 1 // an operand failed, or bad usage
+	ExitTrouble = 2 // serious trouble, for tools that tell the two apart
+)
+
+var (
+	// Name is the utility name used in messages, the base name of argv[0].
+	Name = filepath.Base(os.Args[0])
+
+	// Status is the exit status Exit uses. Failf raises it.
+	Status = ExitSuccess
+)
+
+// Warnf prints "name: message" on stderr.
+func Warnf(format string, a ...interface{}) {
+	fmt.Fprintf(os.Stderr, "%s: %s\n", Name, fmt.Sprintf(format, a...))
+}
+
+// Failf reports an error that does not stop the utility, such as one
+// operand that could not be processed, and makes the exit status a failure.
+func Failf(format string, a ...interface{}) {
+	Warnf(format, a...)
+	if Status == ExitSuccess {
+		Status = ExitFailure
+	}
+}
+
+// Fatalf reports an error and exits with ExitFailure.
+func Fatalf(format string, a ...interface{}) {
+	Warnf(format, a...)
+	os.Exit(ExitFailure)
+}
+
+// Exit exits with Status.
+func Exit() {
+	os.Exit(Status)
+}
+
+// PrintVersion prints "name version" on stdout.
+func PrintVersion(version string) {
+	fmt.Printf("%s %s\n", Name, version)
+}
@@ -0,0 +1,488 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Value is the value behind a flag, as in package flag.
+type Value interface {
+	String() string
+	Set(string) error
+}
+
+// boolFlag is implemented by values that take no argument.
+type boolFlag interface {
+	IsBoolFlag() bool
+}
+
+// Flag is one option. Short is 0 for long-only options and Long is "" for
+// short-only ones.
+type Flag struct {
+	Short rune
+	Long  string
+	Usage string
+	Value Value
+
+	// Optional flags take an argument only when it is attached, as in
+	// "--color=always" or "-bVALUE"; without one, Value is set to NoArg.
+	Optional bool
+	NoArg    string
+
+	// NoAbbrev rejects abbreviations of Long, for options such as rm's
+	// --no-preserve-root that must be spelled out.
+	NoAbbrev bool
+}
+
+// FlagSet parses command lines the way getopt_long does: short options
+// bundled after one dash ("-la"), short option arguments attached or
+// separate ("-m755", "-m 755"), long options with "--name=value" or
+// "--name value" and abbreviated to any unique prefix, and "--" to end the
+// options. Options and operands may be mixed ("ls dir -l") unless
+// POSIXLY_CORRECT is set, in which case the first operand ends the options.
+//
+// --help and --version are handled unless the utility defines them.
+type FlagSet struct {
+	// Usage prints the help text to Output. It is called for --help; on a
+	// usage error only a pointer to --help is printed.
+	Usage func()
+
+	// Version is printed by --version, after the utility name.
+	Version string
+
+	// ErrorStatus is the exit status for usage errors.
+	ErrorStatus int
+
+	flags  []*Flag
+	short  map[rune]*Flag
+	long   map[string]*Flag
+	args   []string
+	output io.Writer
+}
+
+// NewFlagSet returns an empty FlagSet with the given version.
+func NewFlagSet(version string) *FlagSet {
+	return &FlagSet{
+		Version:     version,
+		ErrorStatus: ExitFailure,
+		short:       make(map[rune]*Flag),
+		long:        make(map[string]*Flag),
+	}
+}
+
+// Var defines a flag backed by value.
+func (fs *FlagSet) Var(value Value, short rune, long string, usage string) {
+	f := &Flag{Short: short, Long: long, Usage: usage, Value: value}
+	if short != 0 {
+		if _, dup := fs.short[short]; dup {
+			panic(fmt.Sprintf("flag -%c redefined", short))
+		}
+		fs.short[short] = f
+	}
+	if long != "" {
+		if _, dup := fs.long[long]; dup {
+			panic(fmt.Sprintf("flag --%s redefined", long))
+		}
+		fs.long[long] = f
+	}
+	fs.flags = append(fs.flags, f)
+}
+
+type boolValue bool
+
+func (b *boolValue) Set(s string) error {
+	v, err := strconv.ParseBool(s)
+	*b = boolValue(v)
+	return err
+}
+func (b *boolValue) String() string   { return strconv.FormatBool(bool(*b)) }
+func (b *boolValue) IsBoolFlag() bool { return true }
+
+// BoolVar defines a flag that takes no argument and sets *p. Several
+// flags may share p, which is how aliases such as --quiet and --silent
+// are defined.
+func (fs *FlagSet) BoolVar(p *bool, short rune, long string, usage string) {
+	fs.Var((*boolValue)(p), short, long, usage)
+}
+
+// Bool defines a flag that takes no argument.
+func (fs *FlagSet) Bool(short rune, long string, usage string) *bool {
+	p := new(bool)
+	fs.BoolVar(p, short, long, usage)
+	return p
+}
+
+type stringValue string
+
+func (s *stringValue) Set(v string) error { *s = stringValue(v); return nil }
+func (s *stringValue) String() string     { return string(*s) }
+
+// StringVar defines a flag with an argument, stored in *p. A name quoted
+// with back quotes in usage names the argument in the help text.
+func (fs *FlagSet) StringVar(p *string, short rune, long string, value string, usage string) {
+	*p = value
+	fs.Var((*stringValue)(p), short, long, usage)
+}
+
+// String defines a flag with an argument.
+func (fs *FlagSet) String(short rune, long string, value string, usage string) *string {
+	p := new(string)
+	fs.StringVar(p, short, long, value, usage)
+	return p
+}
+
+type intValue int
+
+func (i *intValue) Set(s string) error {
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return errors.New("not a number")
+	}
+	*i = intValue(v)
+	return nil
+}
+func (i *intValue) String() string { return strconv.Itoa(int(*i)) }
+
+// IntVar defines a flag with an integer argument, stored in *p.
+func (fs *FlagSet) IntVar(p *int, short rune, long string, value int, usage string) {
+	*p = value
+	fs.Var((*intValue)(p), short, long, usage)
+}
+
+// Int defines a flag with an integer argument.
+func (fs *FlagSet) Int(short rune, long string, value int, usage string) *int {
+	p := new(int)
+	fs.IntVar(p, short, long, value, usage)
+	return p
+}
+
+type funcValue func(string) error
+
+func (f funcValue) Set(s string) error { return f(s) }
+func (f funcValue) String() string     { return "" }
+
+// StringOptVar defines a flag whose argument is optional, stored in *p.
+// When the flag is given without an argument, *p is set to noArg.
+func (fs *FlagSet) StringOptVar(p *string, short rune, long string, value string, noArg string, usage string) {
+	fs.StringVar(p, short, long, value, usage)
+	f := fs.flags[len(fs.flags)-1]
+	f.Optional, f.NoArg = true, noArg
+}
+
+// StringOpt defines a flag whose argument is optional.
+func (fs *FlagSet) StringOpt(short rune, long string, value string, noArg string, usage string) *string {
+	p := new(string)
+	fs.StringOptVar(p, short, long, value, noArg, usage)
+	return p
+}
+
+// Func defines a flag with an argument that calls fn with it.
+func (fs *FlagSet) Func(short rune, long string, usage string, fn func(string) error) {
+	fs.Var(funcValue(fn), short, long, usage)
+}
+
+type boolFuncValue func()
+
+func (f boolFuncValue) Set(string) error { f(); return nil }
+func (f boolFuncValue) String() string   { return "" }
+func (f boolFuncValue) IsBoolFlag() bool { return true }
+
+// BoolFunc defines a flag without an argument that calls fn, for options
+// such as cat -A that stand for several others.
+func (fs *FlagSet) BoolFunc(short rune, long string, usage string, fn func()) {
+	fs.Var(boolFuncValue(fn), short, long, usage)
+}
+
+// Lookup returns the flag with the given long name, or nil.
+func (fs *FlagSet) Lookup(long string) *Flag {
+	return fs.long[long]
+}
+
+// Args returns the operands left after the options.
+func (fs *FlagSet) Args() []string { return fs.args }
+
+// NArg returns the number of operands.
+func (fs *FlagSet) NArg() int { return len(fs.args) }
+
+// Arg returns operand i, or "" if there is none.
+func (fs *FlagSet) Arg(i int) string {
+	if i < 0 || i >= len(fs.args) {
+		return ""
+	}
+	return fs.args[i]
+}
+
+// Output is where Usage and PrintDefaults write: stdout for --help,
+// stderr otherwise.
+func (fs *FlagSet) Output() io.Writer {
+	if fs.output == nil {
+		return os.Stderr
+	}
+	return fs.output
+}
+
+// SetOutput sets the destination of Usage and PrintDefaults.
+func (fs *FlagSet) SetOutput(w io.Writer) { fs.output = w }
+
+// errHelp and errVersion end parsing for the built-in options.
+var (
+	errHelp    = errors.New("help requested")
+	errVersion = errors.New("version requested")
+)
+
+// Parse parses args, which must not include the program name. On --help
+// and --version it prints and exits 0; on a usage error it reports it with
+// a pointer to --help and exits with ErrorStatus.
+func (fs *FlagSet) Parse(args []string) {
+	err := fs.parse(args)
+	switch {
+	case err == nil:
+		return
+	case err == errHelp:
+		fs.SetOutput(os.Stdout)
+		fs.usage()
+		os.Exit(ExitSuccess)
+	case err == errVersion:
+		PrintVersion(fs.Version)
+		os.Exit(ExitSuccess)
+	}
+
+	fs.UsageError("%v", err)
+}
+
+// UsageError reports a usage problem found after parsing, such as a
+// missing operand, with a pointer to --help, and exits with ErrorStatus.
+func (fs *FlagSet) UsageError(format string, a ...interface{}) {
+	Warnf(format, a...)
+	fmt.Fprintf(os.Stderr, "Try '%s --help' for more information.\n", Name)
+	os.Exit(fs.ErrorStatus)
+}
+
+func (fs *FlagSet) parse(args []string) error {
+	permute := os.Getenv("POSIXLY_CORRECT") == ""
+
+	var operands []string
+	for len(args) > 0 {
+		arg := args[0]
+		if arg == "--" {
+			args = args[1:]
+			break
+		}
+		if len(arg) < 2 || arg[0] != '-' {
+			if !permute {
+				break
+			}
+			operands = append(operands, arg)
+			args = args[1:]
+			continue
+		}
+		args = args[1:]
+
+		var err error
+		if strings.HasPrefix(arg, "--") {
+			args, err = fs.parseLong(arg[2:], args)
+		} else {
+			args, err = fs.parseShort(arg[1:], args)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	fs.args = append(operands, args...)
+	return nil
+}
+
+// lookupLong finds the flag named by name or by a unique prefix of it.
+// The built-in --help and --version take part, reported as errHelp and
+// errVersion.
+func (fs *FlagSet) lookupLong(name string) (*Flag, error) {
+	if f, ok := fs.long[name]; ok {
+		return f, nil
+	}
+
+	builtins := map[string]error{"help": errHelp}
+	if fs.Version != "" {
+		builtins["version"] = errVersion
+	}
+	if err, ok := builtins[name]; ok {
+		return nil, err
+	}
+
+	var matches []string
+	var match *Flag
+	var builtin error
+	for _, f := range fs.flags {
+		if f.Long != "" && strings.HasPrefix(f.Long, name) && f != match {
+			matches = append(matches, "'--"+f.Long+"'")
+			match = f
+		}
+	}
+	for long, err := range builtins {
+		if _, ok := fs.long[long]; !ok && strings.HasPrefix(long, name) {
+			matches = append(matches, "'--"+long+"'")
+			builtin = err
+		}
+	}
+
+	switch {
+	case len(matches) == 0:
+		return nil, fmt.Errorf("unrecognized option '--%s'", name)
+	case len(matches) > 1:
+		sort.Strings(matches)
+		return nil, fmt.Errorf("option '--%s' is ambiguous; possibilities: %s", name, strings.Join(matches, " "))
+	case builtin != nil:
+		return nil, builtin
+	case match.NoAbbrev:
+		return nil, fmt.Errorf("you may not abbreviate the --%s option", match.Long)
+	}
+	return match, nil
+}
+
+func (fs *FlagSet) parseLong(arg string, args []string) ([]string, error) {
+	name, value, hasValue := strings.Cut(arg, "=")
+	f, err := fs.lookupLong(name)
+	if err != nil {
+		return args, err
+	}
+	name = f.Long
+
+	if isBool(f) {
+		if hasValue {
+			return args, fmt.Errorf("option '--%s' doesn't allow an argument", name)
+		}
+		return args, f.Value.Set("true")
+	}
+
+	if !hasValue {
+		switch {
+		case f.Optional:
+			value = f.NoArg
+		case len(args) == 0:
+			return args, fmt.Errorf("option '--%s' requires an argument", name)
+		default:
+			value, args = args[0], args[1:]
+		}
+	}
+	if err := f.Value.Set(value); err != nil {
+		return args, fmt.Errorf("invalid argument '%s' for '--%s': %v", value, name, err)
+	}
+	return args, nil
+}
+
+func (fs *FlagSet) parseShort(cluster string, args []string) ([]string, error) {
+	for i, c := range cluster {
+		f, ok := fs.short[c]
+		if !ok {
+			return args, fmt.Errorf("invalid option -- '%c'", c)
+		}
+
+		if isBool(f) {
+			if err := f.Value.Set("true"); err != nil {
+				return args, err
+			}
+			continue
+		}
+
+		// The rest of the cluster, or else the next argument, is the value.
+		value := cluster[i+utf8.RuneLen(c):]
+		if value == "" {
+			switch {
+			case f.Optional:
+				value = f.NoArg
+			case len(args) == 0:
+				return args, fmt.Errorf("option requires an argument -- '%c'", c)
+			default:
+				value, args = args[0], args[1:]
+			}
+		}
+		if err := f.Value.Set(value); err != nil {
+			return args, fmt.Errorf("invalid argument '%s' for '-%c': %v", value, c, err)
+		}
+		return args, nil
+	}
+	return args, nil
+}
+
+func isBool(f *Flag) bool {
+	b, ok := f.Value.(boolFlag)
+	return ok && b.IsBoolFlag()
+}
+
+func (fs *FlagSet) usage() {
+	if fs.Usage != nil {
+		fs.Usage()
+		return
+	}
+	fmt.Fprintf(fs.Output(), "Usage: %s [OPTION]...\n\nOptions:\n", Name)
+	fs.PrintDefaults()
+}
+
+// helpColumn is where option descriptions start in PrintDefaults.
+const helpColumn = 30
+
+// PrintDefaults prints the options in the layout of GNU --help output:
+//
+//	-m, --mode=MODE            set file mode
+//	    --preserve-root        fail to operate recursively on '/'
+func (fs *FlagSet) PrintDefaults() {
+	w := fs.Output()
+	for _, f := range fs.flags {
+		argName, usage := unquoteUsage(f)
+
+		var left strings.Builder
+		left.WriteString("  ")
+		switch {
+		case f.Short != 0 && f.Long != "":
+			fmt.Fprintf(&left, "-%c, --%s", f.Short, f.Long)
+		case f.Short != 0:
+			fmt.Fprintf(&left, "-%c", f.Short)
+		default:
+			fmt.Fprintf(&left, "    --%s", f.Long)
+		}
+		switch {
+		case argName == "":
+		case f.Optional && f.Long != "":
+			left.WriteString("[=" + argName + "]")
+		case f.Optional:
+			left.WriteString("[" + argName + "]")
+		case f.Long != "":
+			left.WriteString("=" + argName)
+		default:
+			left.WriteString(" " + argName)
+		}
+
+		if left.Len() >= helpColumn-1 {
+			fmt.Fprintf(w, "%s\n%*s%s\n", left.String(), helpColumn, "", usage)
+		} else {
+			fmt.Fprintf(w, "%-*s%s\n", helpColumn, left.String(), usage)
+		}
+	}
+	if _, ok := fs.long["help"]; !ok {
+		fmt.Fprintf(w, "%-*s%s\n", helpColumn, "      --help", "display this help and exit")
+	}
+	if _, ok := fs.long["version"]; !ok && fs.Version != "" {
+		fmt.Fprintf(w, "%-*s%s\n", helpColumn, "      --version", "output version information and exit")
+	}
+}
+
+// unquoteUsage extracts a back-quoted argument name from the usage text,
+// as package flag does. Flags with an argument default to "VALUE".
+func unquoteUsage(f *Flag) (name string, usage string) {
+	usage = f.Usage
+	if i := strings.IndexByte(usage, '`'); i >= 0 {
+		if j := strings.IndexByte(usage[i+1:], '`'); j >= 0 {
+			name = usage[i+1 : i+1+j]
+			return name, usage[:i] + name + usage[i+1+j+1:]
+		}
+	}
+	if isBool(f) {
+		return "", usage
+	}
+	return "VALUE", usage
+}
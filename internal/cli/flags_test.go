@@ -0,0 +1,155 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+)
+
+type testFlags struct {
+	fs        *FlagSet
+	all, long bool
+	mode      string
+	width     int
+	color     string
+	noRoot    bool
+}
+
+func newTestFlags() *testFlags {
+	t := &testFlags{fs: NewFlagSet("1.0")}
+	t.fs.BoolVar(&t.all, 'a', "all", "do not ignore entries starting with .")
+	t.fs.BoolVar(&t.long, 'l', "", "use a long listing format")
+	t.fs.StringVar(&t.mode, 'm', "mode", "", "set file mode to `MODE`")
+	t.fs.IntVar(&t.width, 'w', "width", 0, "assume screen width `COLS`")
+	t.fs.StringOptVar(&t.color, 0, "color", "never", "always", "colorize the output; `WHEN` can be always or never")
+	t.fs.BoolVar(&t.noRoot, 0, "no-preserve-root", "do not treat '/' specially")
+	t.fs.Lookup("no-preserve-root").NoAbbrev = true
+	return t
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		args  string
+		all   bool
+		long  bool
+		mode  string
+		width int
+		rest  string
+	}{
+		{args: "-la x", all: true, long: true, rest: "x"},
+		{args: "-a -l", all: true, long: true},
+		{args: "-m755 d", mode: "755", rest: "d"},
+		{args: "-am 755 d", all: true, mode: "755", rest: "d"},
+		{args: "--mode=u+x --width 40 f", mode: "u+x", width: 40, rest: "f"},
+		{args: "--all -- -l", all: true, rest: "-l"},
+		{args: "- -a", all: true, rest: "-"},
+		{args: "x -a y -l", all: true, long: true, rest: "x y"},
+		{args: "x -- -a", rest: "x -a"},
+		{args: "--al --mo=644 --wid 5", all: true, mode: "644", width: 5},
+	}
+
+	for _, tt := range tests {
+		f := newTestFlags()
+		if err := f.fs.parse(strings.Fields(tt.args)); err != nil {
+			t.Errorf("%q: %v", tt.args, err)
+			continue
+		}
+		if f.all != tt.all || f.long != tt.long || f.mode != tt.mode || f.width != tt.width {
+			t.Errorf("%q: got all=%v long=%v mode=%q width=%d", tt.args, f.all, f.long, f.mode, f.width)
+		}
+		if rest := strings.Join(f.fs.Args(), " "); rest != tt.rest {
+			t.Errorf("%q: operands %q, want %q", tt.args, rest, tt.rest)
+		}
+	}
+}
+
+func TestParsePosixlyCorrect(t *testing.T) {
+	t.Setenv("POSIXLY_CORRECT", "1")
+	f := newTestFlags()
+	if err := f.fs.parse([]string{"-l", "x", "-a"}); err != nil {
+		t.Fatal(err)
+	}
+	if !f.long || f.all || strings.Join(f.fs.Args(), " ") != "x -a" {
+		t.Errorf("got long=%v all=%v operands %q", f.long, f.all, f.fs.Args())
+	}
+}
+
+func TestParseOptional(t *testing.T) {
+	tests := []struct {
+		args  string
+		color string
+		rest  string
+	}{
+		{args: "x", color: "never", rest: "x"},
+		{args: "--color x", color: "always", rest: "x"},
+		{args: "--color=never x", color: "never", rest: "x"},
+		{args: "--col", color: "always"},
+	}
+
+	for _, tt := range tests {
+		f := newTestFlags()
+		if err := f.fs.parse(strings.Fields(tt.args)); err != nil {
+			t.Errorf("%q: %v", tt.args, err)
+			continue
+		}
+		if f.color != tt.color || strings.Join(f.fs.Args(), " ") != tt.rest {
+			t.Errorf("%q: got color=%q operands %q", tt.args, f.color, f.fs.Args())
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		args string
+		err  string
+	}{
+		{"-z", "invalid option -- 'z'"},
+		{"-m", "option requires an argument -- 'm'"},
+		{"--bogus", "unrecognized option '--bogus'"},
+		{"--all=yes", "option '--all' doesn't allow an argument"},
+		{"--mode", "option '--mode' requires an argument"},
+		{"--width=abc", "invalid argument 'abc' for '--width': not a number"},
+		{"--no-preserve", "you may not abbreviate the --no-preserve-root option"},
+	}
+
+	for _, tt := range tests {
+		err := newTestFlags().fs.parse(strings.Fields(tt.args))
+		if err == nil || err.Error() != tt.err {
+			t.Errorf("%q: error %v, want %q", tt.args, err, tt.err)
+		}
+	}
+
+	if err := newTestFlags().fs.parse([]string{"--help"}); err != errHelp {
+		t.Errorf("--help: %v", err)
+	}
+	if err := newTestFlags().fs.parse([]string{"--ver"}); err != errVersion {
+		t.Errorf("--ver: %v", err)
+	}
+
+	fs := NewFlagSet("1.0")
+	fs.Bool(0, "verbose", "")
+	fs.Bool(0, "vertical", "")
+	want := "option '--ver' is ambiguous; possibilities: '--verbose' '--version' '--vertical'"
+	if err := fs.parse([]string{"--ver"}); err == nil || err.Error() != want {
+		t.Errorf("--ver: error %v, want %q", err, want)
+	}
+}
+
+func TestPrintDefaults(t *testing.T) {
+	var out strings.Builder
+	f := newTestFlags()
+	f.fs.SetOutput(&out)
+	f.fs.PrintDefaults()
+
+	want := `  -a, --all                   do not ignore entries starting with .
+  -l                          use a long listing format
+  -m, --mode=MODE             set file mode to MODE
+  -w, --width=COLS            assume screen width COLS
+      --color[=WHEN]          colorize the output; WHEN can be always or never
+      --no-preserve-root      do not treat '/' specially
+      --help                  display this help and exit
+      --version               output version information and exit
+`
+	if out.String() != want {
+		t.Errorf("PrintDefaults:\n%s\nwant:\n%s", out.String(), want)
+	}
+}
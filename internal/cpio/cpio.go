@@ -0,0 +1,309 @@
+// Package cpio reads and writes cpio archives in the "newc" format the
+// kernel unpacks into its initramfs.
+package cpio
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// File type bits of Header.Mode, in their st_mode layout.
+const (
+	TypeMask    = 0170000
+	TypeSocket  = 0140000
+	TypeSymlink = 0120000
+	TypeReg     = 0100000
+	TypeBlock   = 0060000
+	TypeDir     = 0040000
+	TypeChar    = 0020000
+	TypeFIFO    = 0010000
+)
+
+const (
+	magic      = "070701"
+	headerLen  = 110
+	trailer    = "TRAILER!!!"
+	blockSize  = 512
+	maxNameLen = 4096
+)
+
+// Header describes one archive member. For a symbolic link the target is
+// the member's data, kept in Linkname.
+type Header struct {
+	Name      string
+	Mode      uint32 // type and permission bits
+	Uid       int
+	Gid       int
+	Nlink     int
+	Mtime     int64
+	Size      int64
+	Ino       uint32
+	DevMajor  uint32
+	DevMinor  uint32
+	RDevMajor uint32 // device number of a block or character device
+	RDevMinor uint32
+	Linkname  string
+}
+
+// Type returns the file type bits of the mode.
+func (h *Header) Type() uint32 {
+	return h.Mode & TypeMask
+}
+
+// ErrWriteTooLong is returned when more data is written than the header
+// declared.
+var ErrWriteTooLong = errors.New("cpio: write too long")
+
+// Writer writes a newc archive.
+type Writer struct {
+	w       io.Writer
+	n       int64 // bytes written
+	remain  int64 // data still owed to the current member
+	pad     int64 // padding after the current member's data
+	ino     uint32
+	closed  bool
+	scratch [headerLen]byte
+}
+
+// NewWriter returns a Writer writing to w.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: w}
+}
+
+// WriteHeader starts a new member. Its data, Size bytes, follows with
+// Write; a symbolic link's target is written from Linkname. A zero Ino is
+// replaced by the next inode number, and a zero Nlink by 1, or 2 for a
+// directory.
+func (w *Writer) WriteHeader(h *Header) error {
+	if err := w.flush(); err != nil {
+		return err
+	}
+	if len(h.Name) == 0 || len(h.Name) >= maxNameLen {
+		return fmt.Errorf("cpio: bad name %q", h.Name)
+	}
+
+	hdr := *h
+	if hdr.Type() == TypeSymlink {
+		hdr.Size = int64(len(hdr.Linkname))
+	}
+	if hdr.Ino == 0 {
+		w.ino++
+		hdr.Ino = w.ino
+	}
+	if hdr.Nlink == 0 {
+		hdr.Nlink = 1
+		if hdr.Type() == TypeDir {
+			hdr.Nlink = 2
+		}
+	}
+	if err := w.writeHeader(&hdr); err != nil {
+		return err
+	}
+
+	w.remain = hdr.Size
+	w.pad = padding(hdr.Size)
+	if hdr.Type() == TypeSymlink {
+		_, err := w.Write([]byte(hdr.Linkname))
+		return err
+	}
+	return nil
+}
+
+func (w *Writer) writeHeader(h *Header) error {
+	fields := []uint64{
+		uint64(h.Ino), uint64(h.Mode), uint64(h.Uid), uint64(h.Gid),
+		uint64(h.Nlink), uint64(h.Mtime), uint64(h.Size),
+		uint64(h.DevMajor), uint64(h.DevMinor), uint64(h.RDevMajor), uint64(h.RDevMinor),
+		uint64(len(h.Name) + 1), 0,
+	}
+	b := w.scratch[:0]
+	b = append(b, magic...)
+	for _, f := range fields {
+		if f > 0xffffffff {
+			return fmt.Errorf("cpio: %s: header field out of range", h.Name)
+		}
+		b = append(b, fmt.Sprintf("%08x", f)...)
+	}
+	b = append(b, h.Name...)
+	b = append(b, 0)
+	b = append(b, make([]byte, padding(int64(len(b))))...)
+	return w.write(b)
+}
+
+// Write writes data of the current member.
+func (w *Writer) Write(p []byte) (int, error) {
+	if int64(len(p)) > w.remain {
+		return 0, ErrWriteTooLong
+	}
+	if err := w.write(p); err != nil {
+		return 0, err
+	}
+	w.remain -= int64(len(p))
+	return len(p), nil
+}
+
+// flush finishes the current member.
+func (w *Writer) flush() error {
+	if w.remain > 0 {
+		return fmt.Errorf("cpio: missed writing %d bytes", w.remain)
+	}
+	err := w.write(make([]byte, w.pad))
+	w.pad = 0
+	return err
+}
+
+func (w *Writer) write(p []byte) error {
+	n, err := w.w.Write(p)
+	w.n += int64(n)
+	return err
+}
+
+// Close writes the trailer and pads the archive to a whole block. It does
+// not close the underlying writer.
+func (w *Writer) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	if err := w.flush(); err != nil {
+		return err
+	}
+	if err := w.writeHeader(&Header{Name: trailer, Nlink: 1}); err != nil {
+		return err
+	}
+	if rem := w.n % blockSize; rem != 0 {
+		return w.write(make([]byte, blockSize-rem))
+	}
+	return nil
+}
+
+// Reader reads a newc archive.
+type Reader struct {
+	r      *bufio.Reader
+	n      int64 // bytes read
+	remain int64
+	pad    int64
+	done   bool
+}
+
+// NewReader returns a Reader reading from r.
+func NewReader(r io.Reader) *Reader {
+	return &Reader{r: bufio.NewReader(r)}
+}
+
+// Next advances to the next member and returns its header. It returns
+// io.EOF at the trailer, and io.ErrUnexpectedEOF if the archive ends
+// without one.
+func (r *Reader) Next() (*Header, error) {
+	if r.done {
+		return nil, io.EOF
+	}
+	if err := r.skip(r.remain + r.pad); err != nil {
+		return nil, unexpected(err)
+	}
+	r.remain, r.pad = 0, 0
+
+	var b [headerLen]byte
+	if err := r.read(b[:]); err != nil {
+		return nil, unexpected(err)
+	}
+	if string(b[:6]) != magic {
+		return nil, fmt.Errorf("cpio: bad magic %q at offset %d", b[:6], r.n-headerLen)
+	}
+	var f [13]uint32
+	for i := range f {
+		v, err := strconv.ParseUint(string(b[6+8*i:14+8*i]), 16, 32)
+		if err != nil {
+			return nil, fmt.Errorf("cpio: bad header at offset %d", r.n-headerLen)
+		}
+		f[i] = uint32(v)
+	}
+	if f[12] != 0 {
+		return nil, fmt.Errorf("cpio: nonzero checksum in newc header at offset %d", r.n-headerLen)
+	}
+
+	namesize := int64(f[11])
+	if namesize < 1 || namesize > maxNameLen {
+		return nil, fmt.Errorf("cpio: bad name size %d at offset %d", namesize, r.n-headerLen)
+	}
+	name := make([]byte, namesize+padding(headerLen+namesize))
+	if err := r.read(name); err != nil {
+		return nil, unexpected(err)
+	}
+	if name[namesize-1] != 0 {
+		return nil, fmt.Errorf("cpio: name not terminated at offset %d", r.n-int64(len(name)))
+	}
+
+	h := &Header{
+		Name:      string(name[:namesize-1]),
+		Ino:       f[0],
+		Mode:      f[1],
+		Uid:       int(f[2]),
+		Gid:       int(f[3]),
+		Nlink:     int(f[4]),
+		Mtime:     int64(f[5]),
+		Size:      int64(f[6]),
+		DevMajor:  f[7],
+		DevMinor:  f[8],
+		RDevMajor: f[9],
+		RDevMinor: f[10],
+	}
+	if h.Name == trailer {
+		r.done = true
+		return nil, io.EOF
+	}
+
+	r.remain, r.pad = h.Size, padding(h.Size)
+	if h.Type() == TypeSymlink {
+		target, err := io.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		h.Linkname = string(target)
+	}
+	return h, nil
+}
+
+// Read reads data of the current member.
+func (r *Reader) Read(p []byte) (int, error) {
+	if r.remain == 0 {
+		return 0, io.EOF
+	}
+	if int64(len(p)) > r.remain {
+		p = p[:r.remain]
+	}
+	n, err := r.r.Read(p)
+	r.n += int64(n)
+	r.remain -= int64(n)
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return n, err
+}
+
+func (r *Reader) read(p []byte) error {
+	n, err := io.ReadFull(r.r, p)
+	r.n += int64(n)
+	return err
+}
+
+func (r *Reader) skip(n int64) error {
+	m, err := r.r.Discard(int(n))
+	r.n += int64(m)
+	return err
+}
+
+func unexpected(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// padding returns the bytes needed to align n to four.
+func padding(n int64) int64 {
+	return (4 - n%4) % 4
+}
@@ -0,0 +1,117 @@
+package cpio
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestRoundTrip(t *testing.T) {
+	members := []struct {
+		hdr  Header
+		data string
+	}{
+		{hdr: Header{Name: "bin", Mode: TypeDir | 0755}},
+		{hdr: Header{Name: "bin/hello", Mode: TypeReg | 0755, Size: 6, Mtime: 1700000000}, data: "hello\n"},
+		{hdr: Header{Name: "bin/sh", Mode: TypeSymlink | 0777, Linkname: "hello"}},
+		{hdr: Header{Name: "dev/console", Mode: TypeChar | 0600, RDevMajor: 5, RDevMinor: 1}},
+		{hdr: Header{Name: "etc/shadow", Mode: TypeReg | 0600, Uid: 0, Gid: 42, Size: 1}, data: "x"},
+	}
+
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	for _, m := range members {
+		hdr := m.hdr
+		if err := w.WriteHeader(&hdr); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := io.WriteString(w, m.data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if buf.Len()%blockSize != 0 {
+		t.Errorf("archive is %d bytes, not a whole number of blocks", buf.Len())
+	}
+
+	r := NewReader(&buf)
+	for i, m := range members {
+		h, err := r.Next()
+		if err != nil {
+			t.Fatalf("member %d: %v", i, err)
+		}
+		data, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatalf("%s: %v", h.Name, err)
+		}
+		want := m.hdr
+		if h.Name != want.Name || h.Mode != want.Mode || h.Gid != want.Gid || h.Mtime != want.Mtime ||
+			h.RDevMajor != want.RDevMajor || h.RDevMinor != want.RDevMinor || h.Linkname != want.Linkname {
+			t.Errorf("member %d: got %+v, want %+v", i, *h, want)
+		}
+		if h.Ino != uint32(i+1) {
+			t.Errorf("%s: inode %d, want %d", h.Name, h.Ino, i+1)
+		}
+		if h.Type() != TypeSymlink && string(data) != m.data {
+			t.Errorf("%s: data %q, want %q", h.Name, data, m.data)
+		}
+	}
+	if _, err := r.Next(); err != io.EOF {
+		t.Errorf("after last member: %v, want EOF", err)
+	}
+}
+
+func TestHeaderLayout(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	w.WriteHeader(&Header{Name: "a", Mode: TypeReg | 0644, Size: 2})
+	w.Write([]byte("hi"))
+	w.Close()
+
+	want := "070701" + "00000001" + "000081a4" + "00000000" + "00000000" + "00000001" +
+		"00000000" + "00000002" + "00000000" + "00000000" + "00000000" + "00000000" +
+		"00000002" + "00000000" + "a\x00" + "hi\x00\x00" +
+		"070701" + strings.Repeat("00000000", 4) + "00000001" + strings.Repeat("00000000", 6) +
+		"0000000b" + "00000000" + "TRAILER!!!\x00\x00\x00\x00"
+	if got := buf.String(); got[:len(want)] != want {
+		t.Errorf("archive:\n%q\nwant prefix:\n%q", got[:len(want)], want)
+	}
+	if rest := strings.Trim(buf.String()[len(want):], "\x00"); rest != "" {
+		t.Errorf("trailing garbage %q", rest)
+	}
+}
+
+func TestWriteErrors(t *testing.T) {
+	w := NewWriter(io.Discard)
+	w.WriteHeader(&Header{Name: "f", Mode: TypeReg | 0644, Size: 1})
+	if _, err := w.Write([]byte("too long")); err != ErrWriteTooLong {
+		t.Errorf("overlong write: %v", err)
+	}
+	if err := w.WriteHeader(&Header{Name: "g", Mode: TypeReg | 0644}); err == nil {
+		t.Error("short member accepted")
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	w.WriteHeader(&Header{Name: "f", Mode: TypeReg | 0644, Size: 4})
+	w.Write([]byte("data"))
+	w.flush()
+	truncated := buf.Bytes()
+
+	r := NewReader(bytes.NewReader(truncated))
+	if _, err := r.Next(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Next(); err != io.ErrUnexpectedEOF {
+		t.Errorf("missing trailer: %v", err)
+	}
+
+	if _, err := NewReader(strings.NewReader("070707" + strings.Repeat("0", 200))).Next(); err == nil {
+		t.Error("old binary magic accepted")
+	}
+}
@@ -0,0 +1,118 @@
+// Package filevercmp compares file names that contain version numbers the
+// way GNU ls -v and sort -V do.
+package filevercmp
+
+// Compare returns a negative number, zero or a positive number as a sorts
+// before, the same as or after b.
+//
+// Runs of digits compare as numbers, letters sort before other characters
+// and ~ before everything, even the end of the name. Suffixes such as
+// ".tar.gz" are compared only when the names are otherwise equal. The
+// empty name comes first, then ".", "..", and other names starting with a
+// dot.
+func Compare(a, b string) int {
+	switch {
+	case a == "" || b == "":
+		return boolInt(a != "") - boolInt(b != "")
+	case a[0] == '.' && b[0] != '.':
+		return -1
+	case a[0] != '.' && b[0] == '.':
+		return 1
+	case a[0] == '.':
+		for _, special := range []string{".", ".."} {
+			if a == special || b == special {
+				return boolInt(a != special) - boolInt(b != special)
+			}
+		}
+	}
+
+	aPrefix, bPrefix := prefixLen(a), prefixLen(b)
+	if r := verrevcmp(a[:aPrefix], b[:bPrefix]); r != 0 || aPrefix == len(a) && bPrefix == len(b) {
+		return r
+	}
+	return verrevcmp(a, b)
+}
+
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// prefixLen returns the length of s without its longest suffix matching
+// (\.[A-Za-z~][A-Za-z0-9~]*)*$, which for a hidden file may be all of it.
+func prefixLen(s string) int {
+	prefix := 0
+	for i := 0; i < len(s); {
+		for i+1 < len(s) && s[i] == '.' && (isAlpha(s[i+1]) || s[i+1] == '~') {
+			for i += 2; i < len(s) && (isAlpha(s[i]) || isDigit(s[i]) || s[i] == '~'); i++ {
+			}
+		}
+		if i < len(s) {
+			i++
+			prefix = i
+		}
+	}
+	return prefix
+}
+
+// order gives the weight of the character at pos in s, or of its end.
+func order(s string, pos int) int {
+	if pos >= len(s) {
+		return -1
+	}
+	switch c := s[pos]; {
+	case isDigit(c):
+		return 0
+	case isAlpha(c):
+		return int(c)
+	case c == '~':
+		return -2
+	default:
+		return int(c) + 256
+	}
+}
+
+// verrevcmp is the comparison of Debian version numbers, which alternate
+// non-digit parts, compared by order, and numbers.
+func verrevcmp(a, b string) int {
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		for i < len(a) && !isDigit(a[i]) || j < len(b) && !isDigit(b[j]) {
+			if ac, bc := order(a, i), order(b, j); ac != bc {
+				return ac - bc
+			}
+			i++
+			j++
+		}
+		for i < len(a) && a[i] == '0' {
+			i++
+		}
+		for j < len(b) && b[j] == '0' {
+			j++
+		}
+		firstDiff := 0
+		for i < len(a) && j < len(b) && isDigit(a[i]) && isDigit(b[j]) {
+			if firstDiff == 0 {
+				firstDiff = int(a[i]) - int(b[j])
+			}
+			i++
+			j++
+		}
+		if i < len(a) && isDigit(a[i]) {
+			return 1
+		}
+		if j < len(b) && isDigit(b[j]) {
+			return -1
+		}
+		if firstDiff != 0 {
+			return firstDiff
+		}
+	}
+	return 0
+}
+
+func isDigit(c byte) bool { return c >= '0' && c <= '9' }
+
+func isAlpha(c byte) bool { return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' }
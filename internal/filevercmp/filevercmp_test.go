@@ -0,0 +1,103 @@
+package filevercmp
+
+import "testing"
+
+// The names are in the order GNU ls -v lists them.
+var sorted = []string{
+	"",
+	".",
+	"..",
+	".A",
+	".Z",
+	".a~",
+	".a",
+	".b~",
+	".b",
+	".z",
+	".zz~",
+	".zz",
+	".zz.~1~",
+	".0",
+	".9",
+	".zz.0",
+	"0",
+	"9",
+	"A",
+	"Z",
+	"a~",
+	"a",
+	"a.b~",
+	"a.b",
+	"a.bc~",
+	"a.bc",
+	"a+",
+	"a.",
+	"a..a",
+	"a.+",
+	"b~",
+	"b",
+	"gcc-c++-10.fc9.tar.gz",
+	"gcc-c++-10.fc9.tar.gz.~1~",
+	"gcc-c++-10.fc9.tar.gz.~2~",
+	"gcc-c++-10.8.12-0.7rc2.fc9.tar.bz2",
+	"gcc-c++-10.8.12-0.7rc2.fc9.tar.bz2.~1~",
+	"glibc-2-0.1.beta1.fc10.rpm",
+	"glibc-common-5-0.2.beta2.fc9.ebuild",
+	"glibc-common-5-0.2b.deb",
+	"glibc-common-11b.ebuild",
+	"glibc-common-11-0.6rc2.ebuild",
+	"libstdc++-0.5.8.11-0.7rc2.fc10.tar.gz",
+	"libstdc++-4a.0.8.11-0.7rc2.fc10.tar.gz",
+	"libstdc++-4.10.4.20040204svn.rpm",
+	"libstdc++-devel-3.fc8.ebuild",
+	"libstdc++-devel-3a.fc9.tar.gz",
+	"libstdc++-devel-8.fc8.deb",
+	"libstdc++-devel-8.6.2-0.4b.fc8",
+	"nss_ldap-1-0.2b.fc9.tar.bz2",
+	"nss_ldap-1-0.6rc2.fc8.tar.gz",
+	"nss_ldap-1.0-0.1a.tar.gz",
+	"nss_ldap-10beta1.fc8.tar.gz",
+	"nss_ldap-10.11.8.6.20040204cvs.fc10.ebuild",
+	"z",
+	"zz~",
+	"zz",
+	"zz.~1~",
+	"zz.0",
+	"zz.0.txt",
+	"#.b#",
+}
+
+func TestCompare(t *testing.T) {
+	for i, a := range sorted {
+		for j, b := range sorted {
+			got := Compare(a, b)
+			switch {
+			case i < j && got >= 0, i > j && got <= 0, i == j && got != 0:
+				t.Errorf("Compare(%q, %q) = %d", a, b, got)
+			}
+		}
+	}
+}
+
+func TestCompareNumbers(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"file2", "file10", -1},
+		{"file010", "file10", 0},
+		{"v1.2.10", "v1.2.9", 1},
+		{"foo-1.0~rc1", "foo-1.0", -1},
+	}
+	for _, tt := range tests {
+		got := Compare(tt.a, tt.b)
+		if got < 0 {
+			got = -1
+		} else if got > 0 {
+			got = 1
+		}
+		if got != tt.want {
+			t.Errorf("Compare(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,210 @@
+// Package human prints sizes the way GNU ls, du and df do: counted in
+// blocks of a chosen size, or scaled to "1.5K" and "234M", always rounding
+// up.
+package human
+
+import (
+	"errors"
+	"math/bits"
+	"strconv"
+	"strings"
+)
+
+// Options select how Readable prints a size.
+type Options int
+
+const (
+	// Autoscale picks the largest power of the base that keeps the number
+	// below the base, as -h does.
+	Autoscale Options = 1 << iota
+	// SI appends the letter of the power: k or K, M, G and so on.
+	SI
+	// Base1024 uses powers of 1024 rather than 1000.
+	Base1024
+	// B appends "B", or "iB" to powers of 1024.
+	B
+	// GroupDigits asks for thousands separators, which the C locale does
+	// not have.
+	GroupDigits
+)
+
+var (
+	ErrSyntax = errors.New("invalid block size")
+	ErrRange  = errors.New("block size too large")
+)
+
+const powerLetters = "KMGTPEZYRQ"
+
+// Readable returns n blocks of fromBlockSize bytes as a count of blocks of
+// toBlockSize bytes, rounded up.
+func Readable(n, fromBlockSize, toBlockSize uint64, opts Options) string {
+	base := uint64(1000)
+	if opts&Base1024 != 0 {
+		base = 1024
+	}
+
+	// amt is the whole number of blocks; tenths and rounding, which is 0
+	// for nothing, 1 for less than half, 2 for half and 3 for more than
+	// half a tenth, what is left over.
+	hi, lo := bits.Mul64(n, fromBlockSize)
+	if hi >= toBlockSize {
+		// Too large to count exactly; it cannot be asked for anyway.
+		hi, lo = 0, ^uint64(0)
+	}
+	amt, rem := bits.Div64(hi, lo, toBlockSize)
+	r10 := rem * 10
+	tenths := r10 / toBlockSize
+	r2 := r10 % toBlockSize * 2
+	rounding := 0
+	switch {
+	case r2 == 0:
+	case r2 < toBlockSize:
+		rounding = 1
+	case r2 == toBlockSize:
+		rounding = 2
+	default:
+		rounding = 3
+	}
+
+	exponent := -1
+	point := ""
+	if opts&Autoscale != 0 {
+		exponent = 0
+		if amt >= base {
+			for amt >= base && exponent < len(powerLetters) {
+				r10 := amt%base*10 + tenths
+				r2 := r10%base*2 + uint64(rounding>>1)
+				amt /= base
+				tenths = r10 / base
+				switch {
+				case r2 < base && r2+uint64(rounding) != 0:
+					rounding = 1
+				case r2 < base:
+					rounding = 0
+				case base < r2+uint64(rounding):
+					rounding = 3
+				default:
+					rounding = 2
+				}
+				exponent++
+			}
+			if amt < 10 {
+				if rounding > 0 {
+					tenths++
+					rounding = 0
+					if tenths == 10 {
+						amt++
+						tenths = 0
+					}
+				}
+				if amt < 10 {
+					point = "." + strconv.FormatUint(tenths, 10)
+					tenths = 0
+				}
+			}
+		}
+	}
+	if tenths+uint64(rounding) > 0 {
+		amt++
+		if opts&Autoscale != 0 && amt == base && exponent < len(powerLetters) {
+			exponent++
+			amt = 1
+			point = ".0"
+		}
+	}
+
+	s := strconv.FormatUint(amt, 10) + point
+	if opts&SI == 0 {
+		return s
+	}
+	if exponent < 0 {
+		exponent = 0
+		for power := uint64(1); power < toBlockSize && exponent < len(powerLetters); power *= base {
+			exponent++
+		}
+	}
+	switch {
+	case exponent == 1 && base == 1000:
+		s += "k"
+	case exponent > 0:
+		s += powerLetters[exponent-1 : exponent]
+	}
+	if opts&B != 0 {
+		if base == 1024 && exponent > 0 {
+			s += "i"
+		}
+		s += "B"
+	}
+	return s
+}
+
+// ParseBlockSize reads a --block-size argument: "human-readable" or "si",
+// or a number with an optional unit such as K, KB (1000) or KiB (1024). A
+// unit without a number also makes Readable print the unit after sizes,
+// and a leading ' asks for grouped digits.
+func ParseBlockSize(spec string) (uint64, Options, error) {
+	var opts Options
+	if strings.HasPrefix(spec, "'") {
+		opts |= GroupDigits
+		spec = spec[1:]
+	}
+	if spec != "" && strings.HasPrefix("human-readable", spec) {
+		return 1, opts | Autoscale | SI | Base1024, nil
+	}
+	if spec != "" && strings.HasPrefix("si", spec) {
+		return 1, opts | Autoscale | SI, nil
+	}
+
+	digits := 0
+	for digits < len(spec) && spec[digits] >= '0' && spec[digits] <= '9' {
+		digits++
+	}
+	size := uint64(1)
+	if digits > 0 {
+		n, err := strconv.ParseUint(spec[:digits], 10, 64)
+		if err != nil {
+			return 0, 0, ErrRange
+		}
+		size = n
+	} else if spec == "" {
+		return 0, 0, ErrSyntax
+	}
+
+	if unit := spec[digits:]; unit != "" {
+		exponent := strings.IndexByte(powerLetters, unit[0]) + 1
+		if exponent == 0 && strings.IndexByte("kmgtpe", unit[0]) >= 0 {
+			exponent = strings.IndexByte(powerLetters, unit[0]-'a'+'A') + 1
+		}
+		if exponent == 0 || exponent > 8 {
+			return 0, 0, ErrSyntax
+		}
+		base := uint64(1024)
+		switch unit[1:] {
+		case "", "iB":
+		case "B":
+			base = 1000
+		default:
+			return 0, 0, ErrSyntax
+		}
+		for ; exponent > 0; exponent-- {
+			hi, lo := bits.Mul64(size, base)
+			if hi != 0 {
+				return 0, 0, ErrRange
+			}
+			size = lo
+		}
+		if digits == 0 {
+			opts |= SI
+			if unit[len(unit)-1] == 'B' {
+				opts |= B
+			}
+			if unit[len(unit)-1] != 'B' || strings.HasSuffix(unit, "iB") {
+				opts |= Base1024
+			}
+		}
+	}
+	if size == 0 {
+		return 0, 0, ErrSyntax
+	}
+	return size, opts, nil
+}
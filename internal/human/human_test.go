@@ -0,0 +1,81 @@
+package human
+
+import "testing"
+
+// Expected results come from GNU ls -l and -s.
+func TestReadable(t *testing.T) {
+	const h = Autoscale | SI | Base1024
+	tests := []struct {
+		n, from, to uint64
+		opts        Options
+		want        string
+	}{
+		{0, 1, 1, 0, "0"},
+		{5000, 1, 1, 0, "5000"},
+		{8, 512, 1024, 0, "4"},
+		{3, 512, 1024, 0, "2"},
+		{5000, 1, 1024, 0, "5"},
+		{5000, 1, 1000, 0, "5"},
+		{5001, 1, 1000, 0, "6"},
+		{5000, 1, 1024, SI | Base1024, "5K"},
+		{5000, 1, 1000, SI | B, "5kB"},
+		{5000, 1, 1024, SI | Base1024 | B, "5KiB"},
+		{5000, 1, 1 << 20, SI | Base1024, "1M"},
+		{1023, 1, 1, h, "1023"},
+		{1024, 1, 1, h, "1.0K"},
+		{1025, 1, 1, h, "1.1K"},
+		{4096, 1, 1, h, "4.0K"},
+		{10 * 1024, 1, 1, h, "10K"},
+		{10*1024 + 1, 1, 1, h, "11K"},
+		{1<<20 - 1, 1, 1, h, "1.0M"},
+		{8, 512, 1, h, "4.0K"},
+		{3 << 30, 1, 1, h, "3.0G"},
+		{999, 1, 1, Autoscale | SI, "999"},
+		{1000, 1, 1, Autoscale | SI, "1.0k"},
+		{4096, 1, 1, Autoscale | SI, "4.1k"},
+		{123456789, 1, 1, Autoscale | SI, "124M"},
+	}
+	for _, tt := range tests {
+		if got := Readable(tt.n, tt.from, tt.to, tt.opts); got != tt.want {
+			t.Errorf("Readable(%d, %d, %d, %#x) = %q, want %q", tt.n, tt.from, tt.to, tt.opts, got, tt.want)
+		}
+	}
+}
+
+func TestParseBlockSize(t *testing.T) {
+	tests := []struct {
+		spec string
+		size uint64
+		opts Options
+		err  error
+	}{
+		{"1", 1, 0, nil},
+		{"512", 512, 0, nil},
+		{"1K", 1024, 0, nil},
+		{"1k", 1024, 0, nil},
+		{"2M", 2 << 20, 0, nil},
+		{"1KB", 1000, 0, nil},
+		{"1KiB", 1024, 0, nil},
+		{"K", 1024, SI | Base1024, nil},
+		{"KB", 1000, SI | B, nil},
+		{"KiB", 1024, SI | Base1024 | B, nil},
+		{"G", 1 << 30, SI | Base1024, nil},
+		{"'1", 1, GroupDigits, nil},
+		{"human-readable", 1, Autoscale | SI | Base1024, nil},
+		{"si", 1, Autoscale | SI, nil},
+		{"", 0, 0, ErrSyntax},
+		{"0", 0, 0, ErrSyntax},
+		{"x", 0, 0, ErrSyntax},
+		{"1X", 0, 0, ErrSyntax},
+		{"1KX", 0, 0, ErrSyntax},
+		{"1R", 0, 0, ErrSyntax},
+		{"99999999999999999999", 0, 0, ErrRange},
+		{"100E", 0, 0, ErrRange},
+	}
+	for _, tt := range tests {
+		size, opts, err := ParseBlockSize(tt.spec)
+		if size != tt.size || opts != tt.opts || err != tt.err {
+			t.Errorf("ParseBlockSize(%q) = %d, %#x, %v, want %d, %#x, %v", tt.spec, size, opts, err, tt.size, tt.opts, tt.err)
+		}
+	}
+}
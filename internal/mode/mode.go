@@ -0,0 +1,272 @@
+// Package mode parses chmod(1) style modes, octal or symbolic, and applies
+// them to existing file modes.
+package mode
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// Permission bits in their Unix layout.
+const (
+	setUID = 04000
+	setGID = 02000
+	sticky = 01000
+	allRWX = 0777
+	all    = 07777
+
+	readBits  = 0444
+	writeBits = 0222
+	execBits  = 0111
+)
+
+const (
+	flagOrdinary = iota
+	flagCopy     // u, g or o as the permission: copy those bits
+	flagXIfAnyX  // X: execute only for directories or if some x is set
+)
+
+type change struct {
+	op        byte   // '=', '+' or '-'
+	flag      int    // flagOrdinary, flagCopy or flagXIfAnyX
+	affected  uint32 // bits named by who; 0 when who was omitted
+	value     uint32 // bits to set or clear
+	mentioned uint32 // bits the change is explicit about
+}
+
+// Mode is a compiled mode, a list of changes applied in order.
+type Mode struct {
+	changes []change
+}
+
+// Parse compiles MODE as chmod accepts it: an octal number, or a comma
+// separated list of [ugoa]*([-+=]([rwxXst]*|[ugo]))+ clauses.
+func Parse(spec string) (*Mode, error) {
+	if spec == "" {
+		return nil, fmt.Errorf("invalid mode: '%s'", spec)
+	}
+
+	if spec[0] >= '0' && spec[0] <= '7' {
+		v, err := strconv.ParseUint(spec, 8, 32)
+		if err != nil || v > all {
+			return nil, fmt.Errorf("invalid mode: '%s'", spec)
+		}
+		// Like GNU, an octal mode of up to four digits leaves the set-id
+		// bits of directories alone.
+		mentioned := uint32(all)
+		if len(spec) < 5 {
+			mentioned = uint32(v) | allRWX | sticky
+		}
+		return &Mode{changes: []change{{op: '=', affected: all, value: uint32(v), mentioned: mentioned}}}, nil
+	}
+
+	m := &Mode{}
+	for _, clause := range strings.Split(spec, ",") {
+		var affected uint32
+		i := 0
+	who:
+		for ; i < len(clause); i++ {
+			switch clause[i] {
+			case 'u':
+				affected |= setUID | 0700
+			case 'g':
+				affected |= setGID | 0070
+			case 'o':
+				affected |= sticky | 0007
+			case 'a':
+				affected |= all
+			default:
+				break who
+			}
+		}
+
+		if i == len(clause) {
+			return nil, fmt.Errorf("invalid mode: '%s'", spec)
+		}
+		for i < len(clause) {
+			op := clause[i]
+			if op != '=' && op != '+' && op != '-' {
+				return nil, fmt.Errorf("invalid mode: '%s'", spec)
+			}
+			i++
+
+			c := change{op: op, affected: affected}
+			switch {
+			case i < len(clause) && strings.IndexByte("ugo", clause[i]) >= 0:
+				c.flag = flagCopy
+				c.value = map[byte]uint32{'u': 0700, 'g': 0070, 'o': 0007}[clause[i]]
+				i++
+			default:
+			perms:
+				for ; i < len(clause); i++ {
+					switch clause[i] {
+					case 'r':
+						c.value |= readBits
+					case 'w':
+						c.value |= writeBits
+					case 'x':
+						c.value |= execBits
+					case 'X':
+						c.flag = flagXIfAnyX
+					case 's':
+						c.value |= setUID | setGID
+					case 't':
+						c.value |= sticky
+					default:
+						break perms
+					}
+				}
+			}
+
+			if affected != 0 {
+				c.mentioned = affected & c.value
+			} else {
+				c.mentioned = c.value
+			}
+			m.changes = append(m.changes, c)
+		}
+	}
+	return m, nil
+}
+
+// Apply returns old with the mode applied. Clauses without a who part
+// leave the bits set in umask alone, as chmod does. Only the permission
+// and set-id/sticky bits of the result are meaningful.
+func (m *Mode) Apply(old os.FileMode, umask uint32) os.FileMode {
+	mode := FromFileMode(old)
+	isDir := old.IsDir()
+
+	for _, c := range m.changes {
+		value := c.value
+		var omit uint32
+		if isDir {
+			omit = (setUID | setGID) &^ c.mentioned
+		}
+
+		switch c.flag {
+		case flagCopy:
+			value &= mode
+			var copied uint32
+			if value&readBits != 0 {
+				copied |= readBits
+			}
+			if value&writeBits != 0 {
+				copied |= writeBits
+			}
+			if value&execBits != 0 {
+				copied |= execBits
+			}
+			value |= copied
+		case flagXIfAnyX:
+			if mode&execBits != 0 || isDir {
+				value |= execBits
+			}
+		}
+
+		if c.affected != 0 {
+			value &= c.affected &^ omit
+		} else {
+			value &= ^umask &^ omit
+		}
+
+		switch c.op {
+		case '=':
+			// Without who, = clears everything, even the umask bits it
+			// does not set.
+			preserved := omit
+			if c.affected != 0 {
+				preserved |= ^c.affected
+			}
+			mode = mode&preserved | value
+		case '+':
+			mode |= value
+		case '-':
+			mode &^= value
+		}
+	}
+	return ToFileMode(mode & all)
+}
+
+// FromFileMode returns the permission, set-id and sticky bits of m in
+// their Unix layout.
+func FromFileMode(m os.FileMode) uint32 {
+	bits := uint32(m.Perm())
+	if m&os.ModeSetuid != 0 {
+		bits |= setUID
+	}
+	if m&os.ModeSetgid != 0 {
+		bits |= setGID
+	}
+	if m&os.ModeSticky != 0 {
+		bits |= sticky
+	}
+	return bits
+}
+
+// ToFileMode is the inverse of FromFileMode.
+func ToFileMode(bits uint32) os.FileMode {
+	m := os.FileMode(bits & allRWX)
+	if bits&setUID != 0 {
+		m |= os.ModeSetuid
+	}
+	if bits&setGID != 0 {
+		m |= os.ModeSetgid
+	}
+	if bits&sticky != 0 {
+		m |= os.ModeSticky
+	}
+	return m
+}
+
+// String returns m the way ls -l shows it: the file type letter, then
+// rwx for owner, group and others, with s, S, t or T for the set-id and
+// sticky bits, such as "drwxr-xr-x" or "-rwsr-xr-x".
+func String(m os.FileMode) string {
+	b := []byte("?rwxrwxrwx")
+	switch {
+	case m.IsRegular():
+		b[0] = '-'
+	case m.IsDir():
+		b[0] = 'd'
+	case m&os.ModeSymlink != 0:
+		b[0] = 'l'
+	case m&os.ModeNamedPipe != 0:
+		b[0] = 'p'
+	case m&os.ModeSocket != 0:
+		b[0] = 's'
+	case m&os.ModeCharDevice != 0:
+		b[0] = 'c'
+	case m&os.ModeDevice != 0:
+		b[0] = 'b'
+	}
+	for i := 0; i < 9; i++ {
+		if m&(1<<(8-i)) == 0 {
+			b[i+1] = '-'
+		}
+	}
+	special := []struct {
+		bit os.FileMode
+		pos int
+		set byte
+	}{{os.ModeSetuid, 3, 's'}, {os.ModeSetgid, 6, 's'}, {os.ModeSticky, 9, 't'}}
+	for _, s := range special {
+		if m&s.bit != 0 {
+			if b[s.pos] == '-' {
+				b[s.pos] = s.set - 'a' + 'A'
+			} else {
+				b[s.pos] = s.set
+			}
+		}
+	}
+	return string(b)
+}
+
+// Umask returns the process umask.
+func Umask() uint32 {
+	old := syscall.Umask(0)
+	syscall.Umask(old)
+	return uint32(old)
+}
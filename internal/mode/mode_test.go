@@ -0,0 +1,81 @@
+package mode
+
+import (
+	"os"
+	"testing"
+)
+
+// Expected results come from GNU chmod with umask 022.
+func TestApply(t *testing.T) {
+	tests := []struct {
+		old       uint32
+		spec      string
+		file, dir uint32
+	}{
+		{0644, "u+x", 0744, 0744},
+		{0644, "+x", 0755, 0755},
+		{0600, "go=u", 0666, 0666},
+		{0755, "a-x", 0644, 0644},
+		{0644, "=rw", 0644, 0644},
+		{0666, "=r", 0444, 0444},
+		{0640, "o+X", 0640, 0641},
+		{0750, "g-w,o=g", 0755, 0755},
+		{0644, "u=rwx,g+s", 02744, 02744},
+		{04755, "u-s", 0755, 0755},
+		{0644, "1777", 01777, 01777},
+		{02755, "755", 0755, 02755},
+		{0700, "+t", 01700, 01700},
+		{0644, "a=X", 0, 0111},
+		{0711, "og+X", 0711, 0711},
+		{0644, "u+s,g+s", 06644, 06644},
+		{0777, "o-rwx", 0770, 0770},
+		{0640, "go=u-w", 0644, 0644},
+	}
+
+	for _, tt := range tests {
+		m, err := Parse(tt.spec)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.spec, err)
+			continue
+		}
+		old := ToFileMode(tt.old)
+		if got := FromFileMode(m.Apply(old, 022)); got != tt.file {
+			t.Errorf("%04o %s on a file = %04o, want %04o", tt.old, tt.spec, got, tt.file)
+		}
+		if got := FromFileMode(m.Apply(old|os.ModeDir, 022)); got != tt.dir {
+			t.Errorf("%04o %s on a directory = %04o, want %04o", tt.old, tt.spec, got, tt.dir)
+		}
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, spec := range []string{"", "8", "17777", "u", "u+r,", "z+x", "u+q", "+rw x"} {
+		if _, err := Parse(spec); err == nil {
+			t.Errorf("Parse(%q) succeeded", spec)
+		}
+	}
+}
+
+func TestString(t *testing.T) {
+	tests := []struct {
+		m    os.FileMode
+		want string
+	}{
+		{0644, "-rw-r--r--"},
+		{os.ModeDir | 0755, "drwxr-xr-x"},
+		{os.ModeSymlink | 0777, "lrwxrwxrwx"},
+		{os.ModeDevice | os.ModeCharDevice | 0620, "crw--w----"},
+		{os.ModeDevice | 0660, "brw-rw----"},
+		{os.ModeNamedPipe | 0600, "prw-------"},
+		{os.ModeSocket | 0755, "srwxr-xr-x"},
+		{os.ModeSetuid | 0755, "-rwsr-xr-x"},
+		{os.ModeSetgid | 0640, "-rw-r-S---"},
+		{os.ModeDir | os.ModeSticky | 0777, "drwxrwxrwt"},
+		{os.ModeDir | os.ModeSticky | 0770, "drwxrwx--T"},
+	}
+	for _, tt := range tests {
+		if got := String(tt.m); got != tt.want {
+			t.Errorf("String(%v) = %q, want %q", tt.m, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,319 @@
+// Package quotearg quotes file names for output the way GNU's quotearg
+// does, in its quoting styles. Multibyte characters are taken as UTF-8,
+// and the locale styles use ASCII quotes, as in a C locale with UTF-8
+// characters.
+package quotearg
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Style is a way of quoting.
+type Style int
+
+const (
+	// Literal leaves names as they are.
+	Literal Style = iota
+	// Shell quotes names with single quotes where a shell needs them.
+	Shell
+	// ShellAlways always uses single quotes.
+	ShellAlways
+	// ShellEscape is Shell, with $'...' for characters that cannot be
+	// printed.
+	ShellEscape
+	// ShellEscapeAlways is ShellAlways with $'...'.
+	ShellEscapeAlways
+	// C puts names in double quotes with C escapes.
+	C
+	// CMaybe is C, without the quotes when nothing needs escaping.
+	CMaybe
+	// Escape uses C escapes without the quotes.
+	Escape
+	// Locale uses C escapes inside the locale's quotes.
+	Locale
+	// CLocale is Locale with double quotes.
+	CLocale
+)
+
+// Names are the names of the styles, as --quoting-style accepts them.
+var Names = []string{
+	Literal:           "literal",
+	Shell:             "shell",
+	ShellAlways:       "shell-always",
+	ShellEscape:       "shell-escape",
+	ShellEscapeAlways: "shell-escape-always",
+	C:                 "c",
+	CMaybe:            "c-maybe",
+	Escape:            "escape",
+	Locale:            "locale",
+	CLocale:           "clocale",
+}
+
+// ParseStyle returns the style called name.
+func ParseStyle(name string) (Style, bool) {
+	for style, n := range Names {
+		if n == name {
+			return Style(style), true
+		}
+	}
+	return 0, false
+}
+
+// Quote returns s quoted in style. The characters in also are escaped, or
+// make the name quoted, in the styles that can do either.
+func Quote(s string, style Style, also string) string {
+	return quote(s, style, false, also)
+}
+
+// IsPrint reports whether r prints as a character of its own.
+func IsPrint(r rune) bool {
+	if r < utf8.RuneSelf {
+		return r >= ' ' && r < 0x7f
+	}
+	return r != utf8.RuneError && unicode.IsGraphic(r)
+}
+
+// quote follows gnulib's quotearg_buffer_restyled. The shell styles start
+// out as shell-always with the outer quotes elided; anything that needs
+// quoting starts over with them.
+func quote(s string, style Style, elide bool, also string) string {
+	var b strings.Builder
+	backslashEscapes := false
+	quoteString := ""
+
+	switch style {
+	case CMaybe:
+		style = C
+		elide = true
+		fallthrough
+	case C:
+		if !elide {
+			b.WriteByte('"')
+		}
+		backslashEscapes = true
+		quoteString = `"`
+	case Escape:
+		backslashEscapes = true
+		elide = false
+	case Locale, CLocale:
+		left, right := "'", "'"
+		if style == CLocale {
+			left, right = `"`, `"`
+		}
+		if !elide {
+			b.WriteString(left)
+		}
+		backslashEscapes = true
+		quoteString = right
+	case ShellEscape:
+		backslashEscapes = true
+		fallthrough
+	case Shell:
+		elide = true
+		fallthrough
+	case ShellEscapeAlways:
+		if !elide {
+			backslashEscapes = true
+		}
+		fallthrough
+	case ShellAlways:
+		style = ShellAlways
+		if !elide {
+			b.WriteByte('\'')
+		}
+		quoteString = "'"
+	case Literal:
+		elide = false
+	}
+
+	// force starts over with the outer quotes.
+	force := func() string {
+		if style == ShellAlways && backslashEscapes {
+			style = ShellEscapeAlways
+		}
+		return quote(s, style, false, "")
+	}
+
+	encounteredSingleQuote := false
+	allCompatible := true // with both C and shell quotes
+	pendingShellEscapeEnd := false
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		isRightQuote := false
+		escaping := false
+		compatible := false
+
+		// startEscape writes a backslash, inside $'...' for the shell; it
+		// fails when the outer quotes are needed.
+		startEscape := func() bool {
+			if elide {
+				return false
+			}
+			escaping = true
+			if style == ShellAlways && !pendingShellEscapeEnd {
+				b.WriteString("'$'")
+				pendingShellEscapeEnd = true
+			}
+			b.WriteByte('\\')
+			return true
+		}
+
+		if backslashEscapes && style != ShellAlways && quoteString != "" && strings.HasPrefix(s[i:], quoteString) {
+			if elide {
+				return force()
+			}
+			isRightQuote = true
+		}
+
+		const (
+			check = iota
+			storeEscape
+			storeC
+		)
+		next := check
+
+		var esc byte
+		switch c {
+		case 0:
+			if backslashEscapes {
+				if !startEscape() {
+					return force()
+				}
+				if style != ShellAlways && i+1 < len(s) && s[i+1] >= '0' && s[i+1] <= '9' {
+					b.WriteString("00")
+				}
+				c = '0'
+			}
+		case '?':
+			if style == ShellAlways && elide {
+				return force()
+			}
+		case '\a', '\b', '\f', '\v', '\n', '\r', '\t', '\\':
+			esc = map[byte]byte{'\a': 'a', '\b': 'b', '\f': 'f', '\v': 'v', '\n': 'n', '\r': 'r', '\t': 't', '\\': '\\'}[c]
+			if c == '\\' {
+				// The shell needs no escape for a backslash.
+				if style == ShellAlways {
+					if elide {
+						return force()
+					}
+					next = storeC
+					break
+				}
+				if backslashEscapes && elide && quoteString != "" {
+					next = storeC
+					break
+				}
+			}
+			if c == '\n' || c == '\r' || c == '\t' || c == '\\' {
+				if style == ShellAlways && elide {
+					return force()
+				}
+			}
+			if backslashEscapes {
+				c = esc
+				next = storeEscape
+			}
+		case '{', '}', '#', '~', ' ',
+			'!', '"', '$', '&', '(', ')', '*', ';', '<', '=', '>', '[', '^', '`', '|':
+			// Braces alone, and # and ~ at the start, are special, as are
+			// the rest anywhere.
+			if (c == '{' || c == '}') && len(s) != 1 {
+				break
+			}
+			if (c == '{' || c == '}' || c == '#' || c == '~') && i != 0 {
+				break
+			}
+			if c == '{' || c == '}' || c == '#' || c == '~' || c == ' ' {
+				compatible = true
+			}
+			if style == ShellAlways && elide {
+				return force()
+			}
+		case '\'':
+			encounteredSingleQuote = true
+			compatible = true
+			if style == ShellAlways {
+				if elide {
+					return force()
+				}
+				b.WriteString(`'\'`)
+				pendingShellEscapeEnd = false
+			}
+		default:
+			if c == '%' || c == '+' || c == ',' || c == '-' || c == '.' || c == '/' || c == ':' ||
+				c == ']' || c == '_' || c >= '0' && c <= '9' || c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' {
+				compatible = true
+				break
+			}
+
+			r, m := utf8.DecodeRuneInString(s[i:])
+			printable := IsPrint(r)
+			if r == utf8.RuneError {
+				m = 1
+			}
+			compatible = printable
+			if m > 1 || backslashEscapes && !printable {
+				// A multibyte character, or an unprintable one escaped
+				// byte by byte in octal.
+				end := i + m
+				for {
+					if backslashEscapes && !printable {
+						if !startEscape() {
+							return force()
+						}
+						b.WriteByte('0' + c>>6)
+						b.WriteByte('0' + c>>3&7)
+						c = '0' + c&7
+					} else if isRightQuote {
+						b.WriteByte('\\')
+						isRightQuote = false
+					}
+					if end <= i+1 {
+						break
+					}
+					if pendingShellEscapeEnd && !escaping {
+						b.WriteString("''")
+						pendingShellEscapeEnd = false
+					}
+					b.WriteByte(c)
+					i++
+					c = s[i]
+				}
+				next = storeC
+			}
+		}
+
+		if next == check {
+			next = storeC
+			if ((backslashEscapes && style != ShellAlways) || elide) && strings.IndexByte(also, c) >= 0 || isRightQuote {
+				next = storeEscape
+			}
+		}
+		if next == storeEscape && !startEscape() {
+			return force()
+		}
+		if pendingShellEscapeEnd && !escaping {
+			b.WriteString("''")
+			pendingShellEscapeEnd = false
+		}
+		b.WriteByte(c)
+		if !compatible {
+			allCompatible = false
+		}
+	}
+
+	if b.Len() == 0 && style == ShellAlways && elide {
+		return force()
+	}
+	// A single quote used as an apostrophe reads better in double quotes,
+	// when nothing else needs the shell's quoting.
+	if style == ShellAlways && !elide && encounteredSingleQuote && allCompatible {
+		return quote(s, C, false, also)
+	}
+	if quoteString != "" && !elide {
+		b.WriteString(quoteString)
+	}
+	return b.String()
+}
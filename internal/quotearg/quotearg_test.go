@@ -0,0 +1,66 @@
+package quotearg
+
+import "testing"
+
+// Expected results come from GNU ls --quoting-style.
+func TestQuote(t *testing.T) {
+	tests := []struct {
+		s     string
+		style Style
+		also  string
+		want  string
+	}{
+		{"a", Literal, "", "a"},
+		{"a\nb", Literal, "", "a\nb"},
+		{"a", Shell, "", "a"},
+		{"a b", Shell, "", "'a b'"},
+		{"a", ShellAlways, "", "'a'"},
+		{"it's", Shell, "", `"it's"`},
+		{`x"y'z`, Shell, "", `'x"y'\''z'`},
+		{"a\nb", Shell, "", "'a\nb'"},
+		{"a\nb", ShellEscape, "", `'a'$'\n''b'`},
+		{"\na", ShellEscape, "", `''$'\n''a'`},
+		{"\x01", ShellEscape, "", `''$'\001'`},
+		{"bad\xffname", ShellEscape, "", `'bad'$'\377''name'`},
+		{"tab\tx", ShellEscapeAlways, "", `'tab'$'\t''x'`},
+		{"a", ShellEscapeAlways, "", "'a'"},
+		{`a\b`, ShellEscape, "", `'a\b'`},
+		{"~x", Shell, "", "'~x'"},
+		{"x~", Shell, "", "x~"},
+		{"{", Shell, "", "'{'"},
+		{"a{", Shell, "", "a{"},
+		{"$x", ShellEscape, "", "'$x'"},
+		{"a?b", Shell, "", "'a?b'"},
+		{"é", ShellEscape, "", "é"},
+		{"a:b", ShellEscape, ":", "'a:b'"},
+		{"a", C, "", `"a"`},
+		{"a\nb", C, "", `"a\nb"`},
+		{`a"b`, C, "", `"a\"b"`},
+		{`a\b`, C, "", `"a\\b"`},
+		{"\x01", C, "", `"\001"`},
+		{"a b", CMaybe, "", "a b"},
+		{"a\nb", CMaybe, "", `"a\nb"`},
+		{"a b", Escape, "", "a b"},
+		{"a b", Escape, " ", `a\ b`},
+		{"a:b", Escape, ":", `a\:b`},
+		{"a\tb", Escape, "", `a\tb`},
+		{"it's", Locale, "", `'it\'s'`},
+		{"a\nb", CLocale, "", `"a\nb"`},
+	}
+	for _, tt := range tests {
+		if got := Quote(tt.s, tt.style, tt.also); got != tt.want {
+			t.Errorf("Quote(%q, %s, %q) = %s, want %s", tt.s, Names[tt.style], tt.also, got, tt.want)
+		}
+	}
+}
+
+func TestParseStyle(t *testing.T) {
+	for i, name := range Names {
+		if style, ok := ParseStyle(name); !ok || style != Style(i) {
+			t.Errorf("ParseStyle(%q) = %d, %v", name, style, ok)
+		}
+	}
+	if _, ok := ParseStyle("bogus"); ok {
+		t.Errorf("ParseStyle(%q) succeeded", "bogus")
+	}
+}
@@ -0,0 +1,253 @@
+// Package strftime formats times with the conversions of C strftime, as
+// GNU date and ls accept them, in the C locale.
+//
+// Between % and the conversion character there may be flags and a field
+// width, as in GNU: '-' leaves the field unpadded, '_' pads it with spaces,
+// '0' with zeros, '^' converts it to upper case and '#' swaps its case.
+// The E and O modifiers are accepted and ignored.
+package strftime
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Format returns t formatted according to layout.
+func Format(layout string, t time.Time) string {
+	var b strings.Builder
+	for i := 0; i < len(layout); i++ {
+		c := layout[i]
+		if c != '%' {
+			b.WriteByte(c)
+			continue
+		}
+
+		start := i
+		i++
+		var pad byte
+		var upper, swap bool
+	flags:
+		for ; i < len(layout); i++ {
+			switch layout[i] {
+			case '-', '_', '0':
+				pad = layout[i]
+			case '^':
+				upper = true
+			case '#':
+				swap = true
+			default:
+				break flags
+			}
+		}
+		width := -1
+		for ; i < len(layout) && layout[i] >= '0' && layout[i] <= '9'; i++ {
+			if width < 0 {
+				width = 0
+			}
+			width = width*10 + int(layout[i]-'0')
+		}
+		colons := 0
+		for ; i < len(layout) && layout[i] == ':'; i++ {
+			colons++
+		}
+		for i < len(layout) && (layout[i] == 'E' || layout[i] == 'O') {
+			i++
+		}
+		if i >= len(layout) {
+			b.WriteString(layout[start:])
+			break
+		}
+
+		s, ok := convert(layout[i], colons, width, t)
+		if !ok {
+			// Unknown conversions are copied as they are.
+			b.WriteString(layout[start : i+1])
+			continue
+		}
+		b.WriteString(field(s, pad, width, upper, swap))
+	}
+	return b.String()
+}
+
+// A value is a converted field: numbers carry their default padding so
+// the flags can change it.
+type value struct {
+	s       string
+	number  bool
+	padChar byte // '0' or ' '
+	width   int  // default width of a number
+}
+
+func num(n, width int) value {
+	return value{s: strconv.Itoa(n), number: true, padChar: '0', width: width}
+}
+
+func spaced(n, width int) value {
+	return value{s: strconv.Itoa(n), number: true, padChar: ' ', width: width}
+}
+
+func text(s string) value {
+	return value{s: s}
+}
+
+func convert(c byte, colons, width int, t time.Time) (value, bool) {
+	_, isoWeek := t.ISOWeek()
+	isoYear, _ := t.ISOWeek()
+	yday := t.YearDay() - 1
+	wday := int(t.Weekday())
+	hour12 := t.Hour() % 12
+	if hour12 == 0 {
+		hour12 = 12
+	}
+
+	switch c {
+	case '%':
+		return text("%"), true
+	case 'n':
+		return text("\n"), true
+	case 't':
+		return text("\t"), true
+	case 'a':
+		return text(t.Format("Mon")), true
+	case 'A':
+		return text(t.Format("Monday")), true
+	case 'b', 'h':
+		return text(t.Format("Jan")), true
+	case 'B':
+		return text(t.Format("January")), true
+	case 'c':
+		return text(Format("%a %b %e %H:%M:%S %Y", t)), true
+	case 'C':
+		return num(t.Year()/100, 2), true
+	case 'd':
+		return num(t.Day(), 2), true
+	case 'D', 'x':
+		return text(Format("%m/%d/%y", t)), true
+	case 'e':
+		return spaced(t.Day(), 2), true
+	case 'F':
+		return text(Format("%Y-%m-%d", t)), true
+	case 'g':
+		return num(isoYear%100, 2), true
+	case 'G':
+		return num(isoYear, 4), true
+	case 'H':
+		return num(t.Hour(), 2), true
+	case 'I':
+		return num(hour12, 2), true
+	case 'j':
+		return num(yday+1, 3), true
+	case 'k':
+		return spaced(t.Hour(), 2), true
+	case 'l':
+		return spaced(hour12, 2), true
+	case 'm':
+		return num(int(t.Month()), 2), true
+	case 'M':
+		return num(t.Minute(), 2), true
+	case 'N':
+		// Nanoseconds; a width keeps that many leading digits.
+		ns := strconv.Itoa(t.Nanosecond())
+		ns = strings.Repeat("0", 9-len(ns)) + ns
+		if width > 0 && width < 9 {
+			ns = ns[:width]
+		}
+		return text(ns), true
+	case 'p':
+		return text(t.Format("PM")), true
+	case 'P':
+		return text(strings.ToLower(t.Format("PM"))), true
+	case 'r':
+		return text(Format("%I:%M:%S %p", t)), true
+	case 'R':
+		return text(Format("%H:%M", t)), true
+	case 's':
+		return value{s: strconv.FormatInt(t.Unix(), 10), number: true, padChar: '0'}, true
+	case 'S':
+		return num(t.Second(), 2), true
+	case 'T', 'X':
+		return text(Format("%H:%M:%S", t)), true
+	case 'u':
+		return num((wday+6)%7+1, 1), true
+	case 'U':
+		return num((yday+7-wday)/7, 2), true
+	case 'V':
+		return num(isoWeek, 2), true
+	case 'w':
+		return num(wday, 1), true
+	case 'W':
+		return num((yday+7-(wday+6)%7)/7, 2), true
+	case 'y':
+		return num(t.Year()%100, 2), true
+	case 'Y':
+		return num(t.Year(), 1), true
+	case 'z':
+		return text(zone(t, colons)), true
+	case 'Z':
+		name, _ := t.Zone()
+		return text(name), true
+	}
+	return value{}, false
+}
+
+// zone formats the UTC offset as %z, %:z or %::z do.
+func zone(t time.Time, colons int) string {
+	_, offset := t.Zone()
+	sign := '+'
+	if offset < 0 {
+		sign = '-'
+		offset = -offset
+	}
+	h, m, s := offset/3600, offset/60%60, offset%60
+	two := func(n int) string { return string(rune('0'+n/10)) + string(rune('0'+n%10)) }
+	switch colons {
+	case 0:
+		return string(sign) + two(h) + two(m)
+	case 1:
+		return string(sign) + two(h) + ":" + two(m)
+	default:
+		return string(sign) + two(h) + ":" + two(m) + ":" + two(s)
+	}
+}
+
+func field(v value, pad byte, width int, upper, swap bool) string {
+	s := v.s
+	if upper {
+		s = strings.ToUpper(s)
+	} else if swap {
+		// GNU's '#' swaps case; for names that means upper case, and
+		// lower case for the AM/PM of %p.
+		if s == strings.ToUpper(s) {
+			s = strings.ToLower(s)
+		} else {
+			s = strings.ToUpper(s)
+		}
+	}
+
+	padChar := v.padChar
+	if !v.number {
+		padChar = ' '
+	}
+	switch pad {
+	case '-':
+		return s
+	case '_':
+		padChar = ' '
+	case '0':
+		padChar = '0'
+	}
+	if width < 0 {
+		width = v.width
+		if !v.number {
+			width = 0
+		}
+	}
+	if n := len(s); n < width {
+		if padChar == '0' && strings.HasPrefix(s, "-") {
+			return "-" + strings.Repeat("0", width-n) + s[1:]
+		}
+		return strings.Repeat(string(padChar), width-n) + s
+	}
+	return s
+}
@@ -0,0 +1,36 @@
+package strftime
+
+import (
+	"testing"
+	"time"
+)
+
+// Expected results come from GNU date in the C locale.
+func TestFormat(t *testing.T) {
+	tm := time.Date(2024, time.March, 5, 7, 8, 9, 12345678, time.FixedZone("XYZ", -(3*3600+30*60)))
+	tests := []struct {
+		layout, want string
+	}{
+		{"%Y-%m-%d %H:%M:%S", "2024-03-05 07:08:09"},
+		{"%F %T.%N %z", "2024-03-05 07:08:09.012345678 -0330"},
+		{"%:z %::z %Z", "-03:30 -03:30:00 XYZ"},
+		{"%b %e %H:%M", "Mar  5 07:08"},
+		{"%a %A %B %h", "Tue Tuesday March Mar"},
+		{"%-d|%_m|%e|%0e|%-e", "5| 3| 5|05|5"},
+		{"%3N %6N", "012 012345"},
+		{"%I %l %p %P %r", "07  7 AM am 07:08:09 AM"},
+		{"%j %u %w %U %W %V %G %g", "065 2 2 09 10 10 2024 24"},
+		{"%^a %^B %#p %#Z", "TUE MARCH am xyz"},
+		{"%10Y|%-10d|%_5H", "0000002024|5|    7"},
+		{"%c", "Tue Mar  5 07:08:09 2024"},
+		{"%D %x %R %C %y", "03/05/24 03/05/24 07:08 20 24"},
+		{"100%% %n%t", "100% \n\t"},
+		{"%q %", "%q %"},
+		{"%s", "1709635089"},
+	}
+	for _, tt := range tests {
+		if got := Format(tt.layout, tm); got != tt.want {
+			t.Errorf("Format(%q) = %q, want %q", tt.layout, got, tt.want)
+		}
+	}
+}
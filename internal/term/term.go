@@ -0,0 +1,27 @@
+// Package term answers questions about the terminal a utility writes to.
+package term
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// IsTerminal reports whether f is a terminal. Unlike checking for a
+// character device, /dev/null and other devices are not terminals.
+func IsTerminal(f *os.File) bool {
+	var t syscall.Termios
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, f.Fd(), syscall.TCGETS, uintptr(unsafe.Pointer(&t)))
+	return errno == 0
+}
+
+// Width returns the number of columns of the terminal f is, and false if
+// f is not a terminal or the terminal does not know its size.
+func Width(f *os.File) (int, bool) {
+	var ws struct{ row, col, xpixel, ypixel uint16 }
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, f.Fd(), syscall.TIOCGWINSZ, uintptr(unsafe.Pointer(&ws)))
+	if errno != 0 || ws.col == 0 {
+		return 0, false
+	}
+	return int(ws.col), true
+}
@@ -0,0 +1,29 @@
+package term
+
+import "testing"
+
+func TestStringWidth(t *testing.T) {
+	tests := []struct {
+		s    string
+		want int
+	}{
+		{"", 0},
+		{"file.txt", 8},
+		{"naïve", 5},
+		{"nai\u0308ve", 5}, // combining diaeresis
+		{"日本語", 6},
+		{"ｆｕｌｌ", 8},
+		{"한글", 4},
+		{"🚀", 2},
+		{"a🎉b", 4},
+		{"♥", 1},
+		{"a\u200bb", 2},
+		{"tab\there", 7},
+		{"\xff", 1},
+	}
+	for _, tt := range tests {
+		if got := StringWidth(tt.s); got != tt.want {
+			t.Errorf("StringWidth(%q) = %d, want %d", tt.s, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package users
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// The account files read by LookupAccount, ShadowPassword and GroupIDs.
+var (
+	PasswdFile = "/etc/passwd"
+	ShadowFile = "/etc/shadow"
+	GroupFile  = "/etc/group"
+)
+
+// Account is an entry of the passwd file.
+type Account struct {
+	Name     string
+	Password string
+	UID      int
+	GID      int
+	Gecos    string
+	Home     string
+	Shell    string
+}
+
+// LookupAccount reads the passwd entry of a user name or, failing that, a
+// numeric uid. Unlike LookupUser it gives the password and shell fields,
+// which login, crond and init need to start a session.
+func LookupAccount(spec string) (*Account, error) {
+	fields, err := findEntry(PasswdFile, 7, func(fields []string) bool { return fields[0] == spec })
+	if err != nil {
+		fields, err = findEntry(PasswdFile, 7, func(fields []string) bool { return fields[2] == spec })
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: no entry for %s", PasswdFile, spec)
+	}
+
+	uid, err := strconv.Atoi(fields[2])
+	if err != nil {
+		return nil, fmt.Errorf("%s: bad uid for %s", PasswdFile, spec)
+	}
+	gid, err := strconv.Atoi(fields[3])
+	if err != nil {
+		return nil, fmt.Errorf("%s: bad gid for %s", PasswdFile, spec)
+	}
+	return &Account{
+		Name:     fields[0],
+		Password: fields[1],
+		UID:      uid,
+		GID:      gid,
+		Gecos:    fields[4],
+		Home:     fields[5],
+		Shell:    fields[6],
+	}, nil
+}
+
+// ShadowPassword returns the password hash of name from the shadow file.
+func ShadowPassword(name string) (string, error) {
+	fields, err := findEntry(ShadowFile, 2, func(fields []string) bool { return fields[0] == name })
+	if err != nil {
+		return "", fmt.Errorf("%s: no entry for %s", ShadowFile, name)
+	}
+	return fields[1], nil
+}
+
+// GroupIDs returns the groups a session of name belongs to, as
+// initgroups(3) sets them: primary first, then every group whose member
+// list includes name.
+func GroupIDs(name string, primary int) []int {
+	groups := []int{primary}
+	findEntry(GroupFile, 4, func(fields []string) bool {
+		gid, err := strconv.Atoi(fields[2])
+		if err != nil || gid == primary {
+			return false
+		}
+		for _, member := range strings.Split(fields[3], ",") {
+			if member == name {
+				groups = append(groups, gid)
+				break
+			}
+		}
+		return false
+	})
+	return groups
+}
+
+// findEntry returns the first line of a colon-separated account file with
+// at least n fields that match accepts.
+func findEntry(path string, n int, match func([]string) bool) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Split(scanner.Text(), ":")
+		if len(fields) >= n && match(fields) {
+			return fields, nil
+		}
+	}
+	return nil, fmt.Errorf("%s: no entry", path)
+}
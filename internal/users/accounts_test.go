@@ -0,0 +1,82 @@
+package users
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func writeFiles(t *testing.T) {
+	dir := t.TempDir()
+	files := map[*string]string{
+		&PasswdFile: "root:x:0:0:root:/root:/bin/gsh\n" +
+			"short:x:5:5\n" +
+			"bad:x:uid:1::/:/bin/gsh\n" +
+			"alice:x:1000:100:Alice:/home/alice:/bin/gsh\n" +
+			"1000:x:1001:100::/:/bin/gsh\n",
+		&ShadowFile: "root:$5$salt$hash:19000::::::\nalice:!:19000::::::\n",
+		&GroupFile:  "root:x:0:\nusers:x:100:alice\ntty:x:5:bob,alice\naudio:x:29:bob\nbroken:x:\n",
+	}
+	for file, data := range files {
+		saved := *file
+		t.Cleanup(func() { *file = saved })
+		*file = filepath.Join(dir, filepath.Base(saved))
+		if err := os.WriteFile(*file, []byte(data), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestLookupAccount(t *testing.T) {
+	writeFiles(t)
+
+	acct, err := LookupAccount("alice")
+	want := &Account{"alice", "x", 1000, 100, "Alice", "/home/alice", "/bin/gsh"}
+	if err != nil || !reflect.DeepEqual(acct, want) {
+		t.Errorf("LookupAccount(alice) = %+v, %v", acct, err)
+	}
+	// Names win over uids; a uid is only tried when no name matches.
+	if acct, err := LookupAccount("1000"); err != nil || acct.Name != "1000" {
+		t.Errorf("LookupAccount(1000) = %+v, %v", acct, err)
+	}
+	if acct, err := LookupAccount("0"); err != nil || acct.Name != "root" {
+		t.Errorf("LookupAccount(0) = %+v, %v", acct, err)
+	}
+	for _, spec := range []string{"short", "bad", "nobody"} {
+		if acct, err := LookupAccount(spec); err == nil {
+			t.Errorf("LookupAccount(%s) = %+v", spec, acct)
+		}
+	}
+}
+
+func TestShadowPassword(t *testing.T) {
+	writeFiles(t)
+
+	if hash, err := ShadowPassword("root"); err != nil || hash != "$5$salt$hash" {
+		t.Errorf("ShadowPassword(root) = %q, %v", hash, err)
+	}
+	if hash, err := ShadowPassword("nobody"); err == nil {
+		t.Errorf("ShadowPassword(nobody) = %q", hash)
+	}
+}
+
+func TestGroupIDs(t *testing.T) {
+	writeFiles(t)
+
+	tests := []struct {
+		name    string
+		primary int
+		want    []int
+	}{
+		{"alice", 100, []int{100, 5}},
+		{"bob", 100, []int{100, 5, 29}},
+		{"bob", 29, []int{29, 5}},
+		{"root", 0, []int{0}},
+	}
+	for _, tt := range tests {
+		if got := GroupIDs(tt.name, tt.primary); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("GroupIDs(%s, %d) = %v, want %v", tt.name, tt.primary, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+// Package users maps user and group names to ids and back, for utilities
+// that accept either and print names where they can.
+package users
+
+import (
+	"fmt"
+	"os/user"
+	"strconv"
+	"sync"
+)
+
+var (
+	mu         sync.Mutex
+	userNames  = make(map[int]string)
+	groupNames = make(map[int]string)
+)
+
+// LookupUser resolves a user name or numeric uid. A number that has no
+// passwd entry is still a valid uid and comes back with an empty name.
+func LookupUser(spec string) (uid int, name string, err error) {
+	if u, err := user.Lookup(spec); err == nil {
+		uid, _ := strconv.Atoi(u.Uid)
+		return uid, u.Username, nil
+	}
+	uid, err = strconv.Atoi(spec)
+	if err != nil || uid < 0 {
+		return -1, "", fmt.Errorf("invalid user: '%s'", spec)
+	}
+	if u, err := user.LookupId(spec); err == nil {
+		name = u.Username
+	}
+	return uid, name, nil
+}
+
+// LookupGroup resolves a group name or numeric gid, like LookupUser.
+func LookupGroup(spec string) (gid int, name string, err error) {
+	if g, err := user.LookupGroup(spec); err == nil {
+		gid, _ := strconv.Atoi(g.Gid)
+		return gid, g.Name, nil
+	}
+	gid, err = strconv.Atoi(spec)
+	if err != nil || gid < 0 {
+		return -1, "", fmt.Errorf("invalid group: '%s'", spec)
+	}
+	if g, err := user.LookupGroupId(spec); err == nil {
+		name = g.Name
+	}
+	return gid, name, nil
+}
+
+// UserName returns the name of uid, or an error if it has none.
+func UserName(uid int) (string, error) {
+	mu.Lock()
+	defer mu.Unlock()
+	if name, ok := userNames[uid]; ok {
+		return name, nil
+	}
+	u, err := user.LookupId(strconv.Itoa(uid))
+	if err != nil {
+		return "", err
+	}
+	userNames[uid] = u.Username
+	return u.Username, nil
+}
+
+// GroupName returns the name of gid, or an error if it has none.
+func GroupName(gid int) (string, error) {
+	mu.Lock()
+	defer mu.Unlock()
+	if name, ok := groupNames[gid]; ok {
+		return name, nil
+	}
+	g, err := user.LookupGroupId(strconv.Itoa(gid))
+	if err != nil {
+		return "", err
+	}
+	groupNames[gid] = g.Name
+	return g.Name, nil
+}
+
+// UserString is the name of uid, or the number when it has no name, as
+// ls and chown print owners.
+func UserString(uid int) string {
+	if name, err := UserName(uid); err == nil {
+		return name
+	}
+	return strconv.Itoa(uid)
+}
+
+// GroupString is the name of gid, or the number when it has no name.
+func GroupString(gid int) string {
+	if name, err := GroupName(gid); err == nil {
+		return name
+	}
+	return strconv.Itoa(gid)
+}
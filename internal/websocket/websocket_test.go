@@ -0,0 +1,171 @@
+package websocket
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAcceptKey(t *testing.T) {
+	// The example of RFC 6455, section 1.3.
+	if got := AcceptKey("dGhlIHNhbXBsZSBub25jZQ=="); got != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Errorf("AcceptKey = %q", got)
+	}
+}
+
+// client is the browser end of a test connection.
+type client struct {
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+func dial(t *testing.T, url, origin string) (*client, *http.Response) {
+	conn, err := net.Dial("tcp", strings.TrimPrefix(url, "http://"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	req := "GET / HTTP/1.1\r\nHost: " + strings.TrimPrefix(url, "http://") + "\r\n" +
+		"Upgrade: websocket\r\nConnection: keep-alive, Upgrade\r\n" +
+		"Sec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\nSec-WebSocket-Version: 13\r\n"
+	if origin != "" {
+		req += "Origin: " + origin + "\r\n"
+	}
+	io.WriteString(conn, req+"\r\n")
+
+	r := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(r, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &client{conn, r}, resp
+}
+
+// send writes one masked frame.
+func (c *client) send(fin bool, op int, payload []byte) {
+	head := []byte{byte(op), 0x80}
+	if fin {
+		head[0] |= 0x80
+	}
+	switch {
+	case len(payload) < 126:
+		head[1] |= byte(len(payload))
+	default:
+		head[1] |= 126
+		head = binary.BigEndian.AppendUint16(head, uint16(len(payload)))
+	}
+	mask := []byte{1, 2, 3, 4}
+	masked := make([]byte, len(payload))
+	for i := range payload {
+		masked[i] = payload[i] ^ mask[i%4]
+	}
+	c.conn.Write(append(append(head, mask...), masked...))
+}
+
+// recv reads one unmasked frame.
+func (c *client) recv(t *testing.T) (int, []byte) {
+	var head [2]byte
+	if _, err := io.ReadFull(c.r, head[:]); err != nil {
+		t.Fatal(err)
+	}
+	if head[1]&0x80 != 0 {
+		t.Fatal("server frame is masked")
+	}
+	n := int(head[1] & 0x7f)
+	switch n {
+	case 126:
+		var b [2]byte
+		io.ReadFull(c.r, b[:])
+		n = int(binary.BigEndian.Uint16(b[:]))
+	case 127:
+		var b [8]byte
+		io.ReadFull(c.r, b[:])
+		n = int(binary.BigEndian.Uint64(b[:]))
+	}
+	payload := make([]byte, n)
+	if _, err := io.ReadFull(c.r, payload); err != nil {
+		t.Fatal(err)
+	}
+	return int(head[0] & 0x0f), payload
+}
+
+// echoServer sends every message back with its type.
+func echoServer(t *testing.T) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := Upgrade(w, r)
+		if err != nil {
+			return
+		}
+		defer c.Close()
+		for {
+			typ, msg, err := c.ReadMessage()
+			if err != nil {
+				return
+			}
+			c.WriteMessage(typ, msg)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestEcho(t *testing.T) {
+	srv := echoServer(t)
+	c, resp := dial(t, srv.URL, srv.URL)
+	if resp.StatusCode != http.StatusSwitchingProtocols || resp.Header.Get("Sec-WebSocket-Accept") != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Fatalf("handshake: %s %v", resp.Status, resp.Header)
+	}
+
+	c.send(true, TextMessage, []byte("hello"))
+	if op, msg := c.recv(t); op != TextMessage || string(msg) != "hello" {
+		t.Errorf("echo: op %d %q", op, msg)
+	}
+
+	// A fragmented message with a ping in the middle.
+	big := bytes.Repeat([]byte("x"), 300)
+	c.send(false, BinaryMessage, big[:100])
+	c.send(true, opPing, []byte("p"))
+	c.send(true, opContinuation, big[100:])
+	if op, msg := c.recv(t); op != opPong || string(msg) != "p" {
+		t.Errorf("pong: op %d %q", op, msg)
+	}
+	if op, msg := c.recv(t); op != BinaryMessage || !bytes.Equal(msg, big) {
+		t.Errorf("fragmented echo: op %d, %d bytes", op, len(msg))
+	}
+
+	c.send(true, opClose, []byte{3, 232})
+	if op, _ := c.recv(t); op != opClose {
+		t.Errorf("close answered with op %d", op)
+	}
+}
+
+func TestProtocolErrors(t *testing.T) {
+	srv := echoServer(t)
+
+	c, _ := dial(t, srv.URL, "")
+	c.send(true, opContinuation, []byte("stray"))
+	if op, msg := c.recv(t); op != opClose || binary.BigEndian.Uint16(msg) != 1002 {
+		t.Errorf("stray continuation: op %d %v", op, msg)
+	}
+
+	c, _ = dial(t, srv.URL, "")
+	c.conn.Write([]byte{0x81, 0x01, 'x'}) // unmasked
+	if op, msg := c.recv(t); op != opClose || binary.BigEndian.Uint16(msg) != 1002 {
+		t.Errorf("unmasked frame: op %d %v", op, msg)
+	}
+}
+
+func TestCrossOrigin(t *testing.T) {
+	srv := echoServer(t)
+	_, resp := dial(t, srv.URL, "http://evil.example")
+	if resp.StatusCode != http.StatusForbidden {
+		t.Errorf("cross-origin handshake: %s", resp.Status)
+	}
+}
@@ -1,13 +1,14 @@
-package main
+package cowsay
 
 import (
 	"bufio"
-	"flag"
 	"fmt"
 	"os"
 	"path/filepath"
 	"sort"
 	"strings"
+
+	"github.com/ilnarildarovuch/CoreUtils-On-GO/internal/cli"
 )
 
 const (
@@ -17,57 +18,38 @@ const (
 	pathSep    = ":"
 )
 
+var flags = cli.NewFlagSet(version)
+
 var (
-	borg       = flag.Bool("b", false, "borg mode")
-	borgLong   = flag.Bool("borg", false, "equivalent to -b")
-	dead       = flag.Bool("d", false, "dead mode")
-	deadLong   = flag.Bool("dead", false, "equivalent to -d")
-	greedy     = flag.Bool("g", false, "greedy mode")
-	greedyLong = flag.Bool("greedy", false, "equivalent to -g")
-	paranoid   = flag.Bool("p", false, "paranoid mode")
-	paranoidLong = flag.Bool("paranoid", false, "equivalent to -p")
-	stoned     = flag.Bool("s", false, "stoned mode")
-	stonedLong = flag.Bool("stoned", false, "equivalent to -s")
-	tired      = flag.Bool("t", false, "tired mode")
-	tiredLong  = flag.Bool("tired", false, "equivalent to -t")
-	wired      = flag.Bool("w", false, "wired mode")
-	wiredLong  = flag.Bool("wired", false, "equivalent to -w")
-	young      = flag.Bool("y", false, "young mode")
-	youngLong  = flag.Bool("young", false, "equivalent to -y")
-
-	eyes       = flag.String("e", "oo", "eye string")
-	eyesLong   = flag.String("eyes", "oo", "equivalent to -e")
-	cowfile    = flag.String("f", "default.cow", "cow file")
-	cowfileLong = flag.String("file", "default.cow", "equivalent to -f")
-	tongue     = flag.String("T", "  ", "tongue string")
-	tongueLong = flag.String("tongue", "  ", "equivalent to -T")
-	wrapCol    = flag.Int("W", 40, "wrap column")
-	wrapColLong = flag.Int("wrap", 40, "equivalent to -W")
-
-	expandTabs = flag.Bool("n", false, "expand tabs to spaces")
-	noExpandTabsLong = flag.Bool("no-expand-tabs", false, "equivalent to -n")
-	help       = flag.Bool("h", false, "show help")
-	helpLong   = flag.Bool("help", false, "equivalent to -h")
-	list       = flag.Bool("l", false, "list cowfiles")
-	listLong   = flag.Bool("list", false, "equivalent to -l")
-	showVersion = flag.Bool("version", false, "output version information")
+	borg     = flags.Bool('b', "borg", "borg mode")
+	dead     = flags.Bool('d', "dead", "dead mode")
+	greedy   = flags.Bool('g', "greedy", "greedy mode")
+	paranoid = flags.Bool('p', "paranoid", "paranoid mode")
+	stoned   = flags.Bool('s', "stoned", "stoned mode")
+	tired    = flags.Bool('t', "tired", "tired mode")
+	wired    = flags.Bool('w', "wired", "wired mode")
+	young    = flags.Bool('y', "young", "young mode")
+
+	eyes    = flags.String('e', "eyes", "oo", "use `EYES` as the eye string")
+	cowfile = flags.String('f', "file", "default.cow", "use the cow in `COWFILE`")
+	tongue  = flags.String('T', "tongue", "  ", "use `TONGUE` as the tongue string")
+	wrapCol = flags.Int('W', "wrap", 40, "wrap the message at `COLUMN`")
+
+	expandTabs = flags.Bool('n', "no-expand-tabs", "expand tabs to spaces")
+	help       = flags.Bool('h', "", "show help")
+	list       = flags.Bool('l', "list", "list cowfiles")
 )
 
-func main() {
-	flag.Usage = usage
-	flag.Parse()
-	handleCombinedFlags()
+func Main() {
+	flags.Usage = usage
+	flags.Parse(os.Args[1:])
 
 	if *help {
+		flags.SetOutput(os.Stdout)
 		usage()
 		os.Exit(0)
 	}
 
-	if *showVersion {
-		printVersion()
-		os.Exit(0)
-	}
-
 	if *list {
 		listCowfiles(getCowpath())
 		os.Exit(0)
@@ -79,63 +61,24 @@ func main() {
 
 	cowPath, err := findCowFile(*cowfile, getCowpath())
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "%s: %v\n", os.Args[0], err)
-		os.Exit(1)
+		cli.Fatalf("%v", err)
 	}
 
 	cowContent, err := generateCow(cowPath, eyesVal, tongueVal, getThoughts())
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "%s: %v\n", os.Args[0], err)
-		os.Exit(1)
+		cli.Fatalf("%v", err)
 	}
 
 	printOutput(balloonLines, cowContent)
 }
 
-func handleCombinedFlags() {
-	*borg = *borg || *borgLong
-	*dead = *dead || *deadLong
-	*greedy = *greedy || *greedyLong
-	*paranoid = *paranoid || *paranoidLong
-	*stoned = *stoned || *stonedLong
-	*tired = *tired || *tiredLong
-	*wired = *wired || *wiredLong
-	*young = *young || *youngLong
-	*eyes = firstNonDefault(*eyes, *eyesLong, "oo")
-	*cowfile = firstNonDefault(*cowfile, *cowfileLong, "default.cow")
-	*tongue = firstNonDefault(*tongue, *tongueLong, "  ")
-	*wrapCol = firstNonZero(*wrapCol, *wrapColLong)
-	*expandTabs = *expandTabs || *noExpandTabsLong
-	*help = *help || *helpLong
-	*list = *list || *listLong
-}
-
-func firstNonDefault(a, b, def string) string {
-	if a != def {
-		return a
-	}
-	return b
-}
-
-func firstNonZero(a, b int) int {
-	if a != 0 {
-		return a
-	}
-	return b
-}
-
 func usage() {
-	fmt.Fprintf(os.Stderr, "cowsay %s\n", version)
-	fmt.Fprintf(os.Stderr, "Usage: %s [OPTION]... [MESSAGE]\n", os.Args[0])
-	fmt.Fprintln(os.Stderr, "\nOptions:")
-	flag.PrintDefaults()
-	fmt.Fprintf(os.Stderr, "\nExamples:\n  %s -e ^^ Hello!\n  %s -f tux \"Linux rocks\"\n", 
-		os.Args[0], os.Args[0])
-	os.Exit(0)
-}
-
-func printVersion() {
-	fmt.Printf("%s %s\n", os.Args[0], version)
+	w := flags.Output()
+	fmt.Fprintf(w, "Usage: %s [OPTION]... [MESSAGE]\n", cli.Name)
+	fmt.Fprintln(w, "\nOptions:")
+	flags.PrintDefaults()
+	fmt.Fprintf(w, "\nExamples:\n  %s -e ^^ Hello!\n  %s -f tux \"Linux rocks\"\n",
+		cli.Name, cli.Name)
 }
 
 func getCowpath() string {
@@ -150,12 +93,12 @@ func readMessage() []string {
 	scanner.Buffer(make([]byte, 4096), maxLineLen)
 
 	var lines []string
-	if flag.NArg() == 0 {
+	if flags.NArg() == 0 {
 		for scanner.Scan() {
 			lines = append(lines, processLine(scanner.Text())...)
 		}
 	} else {
-		lines = processLine(strings.Join(flag.Args(), " "))
+		lines = processLine(strings.Join(flags.Args(), " "))
 	}
 
 	if !*expandTabs {
@@ -258,7 +201,7 @@ func generateCow(path, eyes, tongue, thoughts string) (string, error) {
 }
 
 func isThinkMode() bool {
-	return strings.Contains(strings.ToLower(os.Args[0]), "think")
+	return strings.Contains(strings.ToLower(cli.Name), "think")
 }
 
 func getThoughts() string {
@@ -319,7 +262,7 @@ func listCowfiles(cowpath string) {
 	}
 
 	if len(found) == 0 {
-		fmt.Fprintf(os.Stderr, "%s: no cowfiles found in %s\n", os.Args[0], cowpath)
+		cli.Warnf("no cowfiles found in %s", cowpath)
 	}
 }
 
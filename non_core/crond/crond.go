@@ -0,0 +1,490 @@
+package crond
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/ilnarildarovuch/CoreUtils-On-GO/internal/cli"
+	"github.com/ilnarildarovuch/CoreUtils-On-GO/internal/users"
+)
+
+const (
+	version   = "1.0.0"
+	shellPath = "/bin/gsh"
+)
+
+var flags = cli.NewFlagSet(version)
+
+var (
+	systemDir  = flags.String('S', "", "/etc/crontabs", "`DIR` of system crontabs (with a user field)")
+	spoolDir   = flags.String('c', "", "/var/spool/cron/crontabs", "`DIR` of per-user crontabs")
+	foreground = flags.Bool('f', "", "run in foreground (accepted for compatibility)")
+	logStderr  = flags.Bool('d', "", "log to stderr instead of syslog")
+)
+
+var monthNames = map[string]int{
+	"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+	"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+}
+
+var dayNames = map[string]int{
+	"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+}
+
+var shortcuts = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// Schedule holds one bit per allowed value of each field.
+type Schedule struct {
+	Minute, Hour, Dom, Month, Dow uint64
+	DomStar, DowStar              bool
+}
+
+type Job struct {
+	Schedule
+	Reboot  bool
+	User    string
+	Command string
+	Env     []string
+	Source  string
+}
+
+var (
+	jobs    []Job
+	jobsMu  sync.Mutex
+	running = make(map[string]bool)
+	runMu   sync.Mutex
+)
+
+func Main() {
+	flags.Usage = usage
+	flags.Parse(os.Args[1:])
+
+	os.MkdirAll(*spoolDir, 0755)
+	loadJobs()
+	logf(6, "crond %s started, %d job(s)", version, len(jobs))
+
+	for _, job := range snapshotJobs() {
+		if job.Reboot {
+			go runJob(job)
+		}
+	}
+
+	for {
+		now := time.Now()
+		next := now.Truncate(time.Minute).Add(time.Minute)
+		time.Sleep(next.Sub(now))
+
+		loadJobs()
+		for _, job := range snapshotJobs() {
+			if !job.Reboot && job.Matches(next) {
+				go runJob(job)
+			}
+		}
+	}
+}
+
+func usage() {
+	w := flags.Output()
+	fmt.Fprintf(w, "Usage: %s [OPTION]...\n", cli.Name)
+	fmt.Fprintln(w, "\nRun scheduled commands from the system and per-user crontabs.")
+	fmt.Fprintln(w, "\nOptions:")
+	flags.PrintDefaults()
+	fmt.Fprintf(w, "\nExamples:\n  %s -f\n  %s -f -d -c /tmp/crontabs\n", cli.Name, cli.Name)
+}
+
+// Crontabs
+
+func snapshotJobs() []Job {
+	jobsMu.Lock()
+	defer jobsMu.Unlock()
+	return append([]Job(nil), jobs...)
+}
+
+// loadJobs rereads every crontab. System crontabs name the user in the
+// sixth field; a per-user crontab belongs to the user it is named after.
+func loadJobs() {
+	var loaded []Job
+
+	files, _ := filepath.Glob(filepath.Join(*systemDir, "*"))
+	for _, path := range files {
+		loaded = append(loaded, loadCrontab(path, "")...)
+	}
+
+	files, _ = filepath.Glob(filepath.Join(*spoolDir, "*"))
+	for _, path := range files {
+		loaded = append(loaded, loadCrontab(path, filepath.Base(path))...)
+	}
+
+	jobsMu.Lock()
+	jobs = loaded
+	jobsMu.Unlock()
+}
+
+func loadCrontab(path, owner string) []Job {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	if info, err := file.Stat(); err != nil || !info.Mode().IsRegular() {
+		return nil
+	}
+
+	var loaded []Job
+	var env []string
+	scanner := bufio.NewScanner(file)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if isEnvLine(line) {
+			env = append(env, line)
+			continue
+		}
+
+		job, err := parseJob(line, owner)
+		if err != nil {
+			logf(3, "%s:%d: %v", path, lineNo, err)
+			continue
+		}
+		job.Env = append([]string(nil), env...)
+		job.Source = fmt.Sprintf("%s:%d", path, lineNo)
+		loaded = append(loaded, job)
+	}
+	return loaded
+}
+
+// isEnvLine recognizes "NAME=value" lines, which set the environment
+// of the jobs that follow them.
+func isEnvLine(line string) bool {
+	eq := strings.Index(line, "=")
+	if eq <= 0 {
+		return false
+	}
+	name := strings.TrimSpace(line[:eq])
+	return !strings.ContainsAny(name, " \t*/,@") && !strings.ContainsAny(name[:1], "0123456789")
+}
+
+func parseJob(line, owner string) (Job, error) {
+	var job Job
+	fields := strings.Fields(line)
+	consumed := 5
+
+	if strings.HasPrefix(fields[0], "@") {
+		consumed = 1
+		if fields[0] == "@reboot" {
+			job.Reboot = true
+			fields = fields[1:]
+		} else {
+			spec, ok := shortcuts[fields[0]]
+			if !ok {
+				return Job{}, fmt.Errorf("unknown schedule %s", fields[0])
+			}
+			fields = append(strings.Fields(spec), fields[1:]...)
+		}
+	}
+
+	if !job.Reboot {
+		if len(fields) < 5 {
+			return Job{}, fmt.Errorf("expected five time fields")
+		}
+		sched, err := parseSchedule(fields[:5])
+		if err != nil {
+			return Job{}, err
+		}
+		job.Schedule = sched
+		fields = fields[5:]
+	}
+
+	job.User = owner
+	if owner == "" {
+		if len(fields) < 1 {
+			return Job{}, fmt.Errorf("missing user")
+		}
+		job.User = fields[0]
+		fields = fields[1:]
+		consumed++
+	}
+
+	if len(fields) == 0 {
+		return Job{}, fmt.Errorf("missing command")
+	}
+	job.Command = skipFields(line, consumed)
+	return job, nil
+}
+
+// skipFields drops the first n whitespace-separated fields of line,
+// keeping the spacing of the rest intact.
+func skipFields(line string, n int) string {
+	for ; n > 0; n-- {
+		line = strings.TrimLeft(line, " \t")
+		if i := strings.IndexAny(line, " \t"); i >= 0 {
+			line = line[i:]
+		} else {
+			line = ""
+		}
+	}
+	return strings.TrimSpace(line)
+}
+
+func parseSchedule(fields []string) (Schedule, error) {
+	var s Schedule
+	var err error
+
+	if s.Minute, err = parseField(fields[0], 0, 59, nil); err != nil {
+		return s, fmt.Errorf("minute: %v", err)
+	}
+	if s.Hour, err = parseField(fields[1], 0, 23, nil); err != nil {
+		return s, fmt.Errorf("hour: %v", err)
+	}
+	if s.Dom, err = parseField(fields[2], 1, 31, nil); err != nil {
+		return s, fmt.Errorf("day of month: %v", err)
+	}
+	if s.Month, err = parseField(fields[3], 1, 12, monthNames); err != nil {
+		return s, fmt.Errorf("month: %v", err)
+	}
+	if s.Dow, err = parseField(fields[4], 0, 7, dayNames); err != nil {
+		return s, fmt.Errorf("day of week: %v", err)
+	}
+
+	// 7 is another name for Sunday
+	if s.Dow&(1<<7) != 0 {
+		s.Dow |= 1
+	}
+	s.DomStar = strings.HasPrefix(fields[2], "*")
+	s.DowStar = strings.HasPrefix(fields[4], "*")
+	return s, nil
+}
+
+// parseField handles lists of "*", "N", "N-M" and names, each with an
+// optional "/STEP".
+func parseField(field string, low, high int, names map[string]int) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepPart)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("bad step %q", stepPart)
+			}
+			step = n
+		}
+
+		start, end := low, high
+		if rangePart != "*" {
+			from, to, isRange := strings.Cut(rangePart, "-")
+			var err error
+			if start, err = parseValue(from, low, high, names); err != nil {
+				return 0, err
+			}
+			end = start
+			if isRange {
+				if end, err = parseValue(to, low, high, names); err != nil {
+					return 0, err
+				}
+			} else if hasStep {
+				end = high
+			}
+			if end < start {
+				return 0, fmt.Errorf("bad range %q", rangePart)
+			}
+		}
+
+		for v := start; v <= end; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+func parseValue(s string, low, high int, names map[string]int) (int, error) {
+	if v, ok := names[strings.ToLower(s)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil || v < low || v > high {
+		return 0, fmt.Errorf("bad value %q", s)
+	}
+	return v, nil
+}
+
+// Matches applies the usual cron rule: when both day fields are
+// restricted, either one matching is enough.
+func (s Schedule) Matches(t time.Time) bool {
+	if s.Minute&(1<<uint(t.Minute())) == 0 || s.Hour&(1<<uint(t.Hour())) == 0 ||
+		s.Month&(1<<uint(t.Month())) == 0 {
+		return false
+	}
+
+	domMatch := s.Dom&(1<<uint(t.Day())) != 0
+	dowMatch := s.Dow&(1<<uint(t.Weekday())) != 0
+	if s.DomStar || s.DowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
+
+// Jobs
+
+// runJob runs the command through gsh as its owner. A job still running
+// from the previous minute is not started a second time.
+func runJob(job Job) {
+	runMu.Lock()
+	if running[job.Source] {
+		runMu.Unlock()
+		logf(4, "(%s) SKIP %s: previous run still active", job.User, job.Command)
+		return
+	}
+	running[job.Source] = true
+	runMu.Unlock()
+
+	defer func() {
+		runMu.Lock()
+		delete(running, job.Source)
+		runMu.Unlock()
+	}()
+
+	acct, err := lookupAccount(job.User)
+	if err != nil {
+		logf(3, "(%s) ERROR %s: %v", job.User, job.Command, err)
+		return
+	}
+
+	cmd := exec.Command(shellPath, "-c", job.Command)
+	cmd.Dir = acct.Home
+	cmd.Env = jobEnv(acct, job.Env)
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	if acct.UID != os.Geteuid() {
+		cmd.SysProcAttr.Credential = &syscall.Credential{
+			Uid:    uint32(acct.UID),
+			Gid:    uint32(acct.GID),
+			Groups: groupIDs(acct),
+		}
+	}
+
+	output := &outputLogger{user: job.User}
+	cmd.Stdout = output
+	cmd.Stderr = output
+
+	logf(6, "(%s) CMD %s", job.User, job.Command)
+	start := time.Now()
+	err = cmd.Run()
+	duration := time.Since(start).Round(time.Millisecond)
+	output.flush()
+
+	switch {
+	case err == nil:
+		logf(6, "(%s) END %s: exit 0 in %v", job.User, job.Command, duration)
+	case cmd.ProcessState != nil:
+		logf(4, "(%s) END %s: exit %d in %v", job.User, job.Command, cmd.ProcessState.ExitCode(), duration)
+	default:
+		logf(3, "(%s) ERROR %s: %v", job.User, job.Command, err)
+	}
+}
+
+// maxOutputLine bounds the memory a job's output takes in crond; longer
+// lines are cut.
+const maxOutputLine = 1024
+
+// outputLogger logs a job's output a line at a time as it arrives.
+type outputLogger struct {
+	user string
+	line []byte
+}
+
+func (l *outputLogger) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		i := bytes.IndexByte(p, '\n')
+		if i < 0 {
+			l.add(p)
+			break
+		}
+		l.add(p[:i])
+		l.flush()
+		p = p[i+1:]
+	}
+	return n, nil
+}
+
+func (l *outputLogger) add(p []byte) {
+	if room := maxOutputLine - len(l.line); room > 0 {
+		l.line = append(l.line, p[:min(len(p), room)]...)
+	}
+}
+
+func (l *outputLogger) flush() {
+	if len(l.line) > 0 {
+		logf(6, "(%s) OUTPUT %s", l.user, l.line)
+	}
+	l.line = l.line[:0]
+}
+
+func jobEnv(acct *users.Account, extra []string) []string {
+	env := []string{
+		"HOME=" + acct.Home,
+		"USER=" + acct.Name,
+		"LOGNAME=" + acct.Name,
+		"SHELL=" + shellPath,
+		"PATH=/bin:/sbin:/usr/bin:/usr/sbin",
+	}
+	return append(env, extra...)
+}
+
+func lookupAccount(name string) (*users.Account, error) {
+	acct, err := users.LookupAccount(name)
+	if err != nil {
+		return nil, err
+	}
+	if acct.Home == "" {
+		acct.Home = "/"
+	}
+	return acct, nil
+}
+
+func groupIDs(acct *users.Account) []uint32 {
+	var groups []uint32
+	for _, gid := range users.GroupIDs(acct.Name, acct.GID) {
+		groups = append(groups, uint32(gid))
+	}
+	return groups
+}
+
+// logf sends a cron facility message to syslogd, or to stderr with -d or
+// while syslogd is not running.
+func logf(severity int, format string, a ...interface{}) {
+	msg := fmt.Sprintf(format, a...)
+
+	if !*logStderr {
+		if conn, err := net.Dial("unixgram", "/dev/log"); err == nil {
+			defer conn.Close()
+			fmt.Fprintf(conn, "<%d>%s crond[%d]: %s", 9<<3|severity, time.Now().Format(time.Stamp), os.Getpid(), msg)
+			return
+		}
+	}
+	fmt.Fprintf(os.Stderr, "%s crond: %s\n", time.Now().Format(time.Stamp), msg)
+}
@@ -0,0 +1,120 @@
+package crond
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMatches(t *testing.T) {
+	// October 2026 starts on a Thursday; the 18th is a Sunday.
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2026, time.October, day, hour, minute, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		spec string
+		t    time.Time
+		want bool
+	}{
+		{"* * * * *", at(18, 12, 34), true},
+		{"*/15 * * * *", at(18, 10, 30), true},
+		{"*/15 * * * *", at(18, 10, 31), false},
+		{"0 9-17/2 * * *", at(18, 11, 0), true},
+		{"0 9-17/2 * * *", at(18, 12, 0), false},
+		{"0 9-17/2 * * *", at(18, 19, 0), false},
+		{"1,2,40-42 * * * *", at(18, 0, 41), true},
+		{"1,2,40-42 * * * *", at(18, 0, 3), false},
+		// N/step runs from N to the end of the range.
+		{"0 0 5/10 * *", at(15, 0, 0), true},
+		{"0 0 5/10 * *", at(10, 0, 0), false},
+		{"0 0 */10 * *", at(11, 0, 0), true},
+		{"0 0 */10 * *", at(10, 0, 0), false},
+		// With both days restricted either one is enough: the 13th, a
+		// Tuesday, and Friday the 16th.
+		{"0 0 13 * 5", at(13, 0, 0), true},
+		{"0 0 13 * 5", at(16, 0, 0), true},
+		{"0 0 13 * 5", at(14, 0, 0), false},
+		// With one of them *, both must match.
+		{"0 0 13 * *", at(16, 0, 0), false},
+		{"0 0 * * 5", at(13, 0, 0), false},
+		{"0 0 */2 * 5", at(16, 0, 0), false},
+		{"0 0 */2 * 5", at(13, 0, 0), false},
+		{"0 0 */2 * 5", at(23, 0, 0), true},
+		// 7 and 0 are both Sunday.
+		{"0 0 * * 7", at(18, 0, 0), true},
+		{"0 0 * * 0", at(18, 0, 0), true},
+		{"0 0 * * 5-7", at(18, 0, 0), true},
+		{"0 0 * * sun", at(18, 0, 0), true},
+		{"0 0 * * Mon-Fri", at(18, 0, 0), false},
+		{"0 0 * * mon-fri", at(16, 0, 0), true},
+		{"5 4 * jan,oct *", at(18, 4, 5), true},
+		{"5 4 * nov *", at(18, 4, 5), false},
+		{"5 4 * 10 *", at(18, 4, 5), true},
+	}
+	for _, tt := range tests {
+		job, err := parseJob(tt.spec+" true", "root")
+		if err != nil {
+			t.Errorf("parseJob(%q): %v", tt.spec, err)
+			continue
+		}
+		if got := job.Matches(tt.t); got != tt.want {
+			t.Errorf("%q matches %s = %v, want %v", tt.spec, tt.t.Format("Mon Jan 2 15:04"), got, tt.want)
+		}
+	}
+}
+
+func TestParseJob(t *testing.T) {
+	tests := []struct {
+		line, owner     string
+		user, command   string
+		reboot          bool
+		matchesMidnight bool
+	}{
+		{"* * * * * echo  hi", "alice", "alice", "echo  hi", false, true},
+		{"0 5 * * * root run-parts /etc/daily", "", "root", "run-parts /etc/daily", false, false},
+		{"@daily  rotate --all", "bob", "bob", "rotate --all", false, true},
+		{"@hourly root sync", "", "root", "sync", false, true},
+		{"@reboot root mount -a", "", "root", "mount -a", true, false},
+	}
+	midnight := time.Date(2026, time.October, 18, 0, 0, 0, 0, time.UTC)
+	for _, tt := range tests {
+		job, err := parseJob(tt.line, tt.owner)
+		if err != nil {
+			t.Errorf("parseJob(%q): %v", tt.line, err)
+			continue
+		}
+		if job.User != tt.user || job.Command != tt.command || job.Reboot != tt.reboot {
+			t.Errorf("parseJob(%q) = user %q, command %q, reboot %v", tt.line, job.User, job.Command, job.Reboot)
+		}
+		if !job.Reboot && job.Matches(midnight) != tt.matchesMidnight {
+			t.Errorf("parseJob(%q) matches midnight = %v", tt.line, !tt.matchesMidnight)
+		}
+	}
+}
+
+func TestParseJobErrors(t *testing.T) {
+	for _, line := range []string{
+		"60 * * * * true",
+		"* 24 * * * true",
+		"* * 0 * * true",
+		"* * 32 * * true",
+		"* * * 13 * true",
+		"* * * * 8 true",
+		"*/0 * * * * true",
+		"*/x * * * * true",
+		"5-1 * * * * true",
+		"* * * foo * true",
+		"* * * * funday true",
+		"1,,2 * * * * true",
+		"* * * * true",
+		"* * * * *",
+		"@weekly",
+		"@fortnightly true",
+	} {
+		if job, err := parseJob(line, "root"); err == nil {
+			t.Errorf("parseJob(%q) = %+v, want an error", line, job)
+		}
+	}
+	if _, err := parseJob("* * * * * true", ""); err == nil {
+		t.Errorf("system crontab line without a user accepted")
+	}
+}
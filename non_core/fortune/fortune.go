@@ -1,16 +1,17 @@
-package main
+package fortune
 
 import (
 	"bufio"
 	"bytes"
 	"encoding/binary"
-	"flag"
 	"fmt"
 	"math/rand"
 	"os"
 	"path/filepath"
 	"strings"
 	"time"
+
+	"github.com/ilnarildarovuch/CoreUtils-On-GO/internal/cli"
 )
 
 const (
@@ -21,17 +22,18 @@ const (
 	headerSize   = 24
 )
 
+var flags = cli.NewFlagSet(version)
+
 var (
-	offensive      = flag.Bool("o", false, "offensive fortunes only")
-	allForts       = flag.Bool("a", false, "any fortune allowed")
-	shortOnly      = flag.Bool("s", false, "short fortunes only")
-	longOnly       = flag.Bool("l", false, "long fortunes only")
-	showVersion    = flag.Bool("version", false, "output version information")
-	equalProb      = flag.Bool("e", false, "equal probability distribution")
-	findFiles      = flag.Bool("f", false, "find fortune files")
-	wait           = flag.Bool("w", false, "wait after display")
-	showFilename   = flag.Bool("c", false, "show filename")
-	locale         = os.Getenv("LANG")
+	offensive    = flags.Bool('o', "", "offensive fortunes only")
+	allForts     = flags.Bool('a', "", "any fortune allowed")
+	shortOnly    = flags.Bool('s', "", "short fortunes only")
+	longOnly     = flags.Bool('l', "", "long fortunes only")
+	equalProb    = flags.Bool('e', "", "equal probability distribution")
+	findFiles    = flags.Bool('f', "", "find fortune files")
+	wait         = flags.Bool('w', "", "wait after display")
+	showFilename = flags.Bool('c', "", "show filename")
+	locale       = os.Getenv("LANG")
 )
 
 type fortuneFile struct {
@@ -40,21 +42,15 @@ type fortuneFile struct {
 	count   uint32
 }
 
-func main() {
-	flag.Usage = usage
-	flag.Parse()
-
-	if *showVersion {
-		printVersion()
-		return
-	}
+func Main() {
+	flags.Usage = usage
+	flags.Parse(os.Args[1:])
 
 	rand.Seed(time.Now().UnixNano())
 
 	files, err := collectFortuneFiles()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error collecting files: %v\n", err)
-		os.Exit(1)
+		cli.Fatalf("error collecting files: %v", err)
 	}
 
 	if *findFiles {
@@ -65,14 +61,12 @@ func main() {
 	}
 
 	if len(files) == 0 {
-		fmt.Fprintln(os.Stderr, "No fortune files found")
-		os.Exit(1)
+		cli.Fatalf("no fortune files found")
 	}
 
 	selected := selectFortune(files)
 	if selected == nil {
-		fmt.Fprintln(os.Stderr, "No matching fortune found")
-		os.Exit(1)
+		cli.Fatalf("no matching fortune found")
 	}
 
 	fortune, err := readFortune(selected)
@@ -95,14 +89,10 @@ func main() {
 }
 
 func usage() {
-	fmt.Fprintf(os.Stderr, "fortune %s\n", version)
-	fmt.Fprintf(os.Stderr, "Usage: %s [OPTION]... [FILE]...\n", os.Args[0])
-	fmt.Fprintln(os.Stderr, "\nOptions:")
-	flag.PrintDefaults()
-}
-
-func printVersion() {
-	fmt.Printf("%s %s\n", os.Args[0], version)
+	w := flags.Output()
+	fmt.Fprintf(w, "Usage: %s [OPTION]... [FILE]...\n", cli.Name)
+	fmt.Fprintln(w, "\nOptions:")
+	flags.PrintDefaults()
 }
 
 func collectFortuneFiles() ([]fortuneFile, error) {
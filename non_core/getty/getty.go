@@ -0,0 +1,262 @@
+package getty
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+	"unsafe"
+
+	"github.com/ilnarildarovuch/CoreUtils-On-GO/internal/cli"
+)
+
+const (
+	version = "1.0.0"
+	CBAUD   = 0010017 // termios speed mask, missing from package syscall
+)
+
+var flags = cli.NewFlagSet(version)
+
+var (
+	localLine  = flags.Bool('L', "local-line", "do not require carrier detect (serial lines)")
+	noHostname = flags.Bool('H', "nohostname", "do not print the hostname in the login prompt")
+	noIssue    = flags.Bool('i', "noissue", "do not display /etc/issue")
+	issueFile  = flags.String('f', "issue-file", "/etc/issue", "display `FILE` instead of /etc/issue")
+	loginProg  = flags.String('l', "login-program", "/bin/login", "invoke `PROGRAM` instead of /bin/login")
+)
+
+var baudRates = map[int]uint32{
+	1200: syscall.B1200, 2400: syscall.B2400, 4800: syscall.B4800,
+	9600: syscall.B9600, 19200: syscall.B19200, 38400: syscall.B38400,
+	57600: syscall.B57600, 115200: syscall.B115200, 230400: syscall.B230400,
+}
+
+func Main() {
+	flags.Usage = usage
+	flags.Parse(os.Args[1:])
+
+	tty, speed, term, err := parseArgs(flags.Args())
+	if err != nil {
+		flags.UsageError("%v", err)
+	}
+
+	if err := openTTY(tty); err != nil {
+		cli.Fatalf("%s: %v", tty, err)
+	}
+
+	if err := setupLine(speed); err != nil {
+		cli.Fatalf("%s: %v", tty, err)
+	}
+
+	if term != "" {
+		os.Setenv("TERM", term)
+	}
+
+	if !*noIssue {
+		printIssue(*issueFile, tty)
+	}
+
+	username := promptLogin()
+	args := []string{*loginProg, "--", username}
+	if err := syscall.Exec(*loginProg, args, os.Environ()); err != nil {
+		cli.Fatalf("%s: %v", *loginProg, err)
+	}
+}
+
+func usage() {
+	w := flags.Output()
+	fmt.Fprintf(w, "Usage: %s [OPTION]... BAUD_RATE,... LINE [TERMTYPE]\n", cli.Name)
+	fmt.Fprintf(w, "  or:  %s [OPTION]... LINE BAUD_RATE,... [TERMTYPE]\n", cli.Name)
+	fmt.Fprintln(w, "\nOpen a terminal line, print /etc/issue and prompt for a login name.")
+	fmt.Fprintln(w, "\nOptions:")
+	flags.PrintDefaults()
+	fmt.Fprintf(w, "\nExamples:\n  %s 38400 tty1 linux\n  %s -L ttyS0 115200 vt100\n", cli.Name, cli.Name)
+}
+
+// parseArgs accepts the line and baud rate in either order, like agetty.
+func parseArgs(args []string) (tty string, speed uint32, term string, err error) {
+	if len(args) < 2 {
+		return "", 0, "", fmt.Errorf("missing operand")
+	}
+
+	baud := args[0]
+	tty = args[1]
+	if _, err := strconv.Atoi(strings.Split(args[0], ",")[0]); err != nil {
+		baud, tty = args[1], args[0]
+	}
+
+	// Only the first rate is used; there is no break-key rate cycling.
+	rate, err := strconv.Atoi(strings.Split(baud, ",")[0])
+	if err != nil {
+		return "", 0, "", fmt.Errorf("bad speed: %s", baud)
+	}
+	speed, ok := baudRates[rate]
+	if !ok {
+		return "", 0, "", fmt.Errorf("bad speed: %s", baud)
+	}
+
+	if len(args) > 2 {
+		term = args[2]
+	}
+	return strings.TrimPrefix(tty, "/dev/"), speed, term, nil
+}
+
+// openTTY makes the line our controlling terminal and stdin/stdout/stderr.
+func openTTY(tty string) error {
+	syscall.Setsid()
+
+	fd, err := syscall.Open("/dev/"+tty, syscall.O_RDWR|syscall.O_NOCTTY, 0)
+	if err != nil {
+		return err
+	}
+
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), uintptr(syscall.TIOCSCTTY), 1); errno != 0 {
+		syscall.Close(fd)
+		return errno
+	}
+
+	for i := 0; i < 3; i++ {
+		if i == fd {
+			continue
+		}
+		// dup3, since arm64 and the newer ports have no dup2.
+		if err := syscall.Dup3(fd, i, 0); err != nil {
+			return err
+		}
+	}
+	if fd > 2 {
+		syscall.Close(fd)
+	}
+
+	syscall.Fchown(0, 0, 0)
+	syscall.Fchmod(0, 0620)
+	return nil
+}
+
+// setupLine puts the line into a sane cooked mode at the requested speed.
+func setupLine(speed uint32) error {
+	var t syscall.Termios
+	if err := ioctl(0, syscall.TCGETS, unsafe.Pointer(&t)); err != nil {
+		return err
+	}
+
+	t.Cflag &^= CBAUD | syscall.CSIZE | syscall.PARENB
+	t.Cflag |= speed | syscall.CS8 | syscall.CREAD | syscall.HUPCL
+	if *localLine {
+		t.Cflag |= syscall.CLOCAL
+	}
+	t.Ispeed = speed
+	t.Ospeed = speed
+
+	t.Iflag = syscall.ICRNL | syscall.IXON | syscall.BRKINT
+	t.Oflag = syscall.OPOST | syscall.ONLCR
+	t.Lflag = syscall.ICANON | syscall.ISIG | syscall.ECHO | syscall.ECHOE | syscall.ECHOK | syscall.IEXTEN
+	t.Line = 0 // N_TTY
+
+	t.Cc[syscall.VINTR] = 3    // ^C
+	t.Cc[syscall.VQUIT] = 28   // ^\
+	t.Cc[syscall.VERASE] = 127 // DEL
+	t.Cc[syscall.VKILL] = 21   // ^U
+	t.Cc[syscall.VEOF] = 4     // ^D
+	t.Cc[syscall.VSUSP] = 26   // ^Z
+	t.Cc[syscall.VMIN] = 1
+	t.Cc[syscall.VTIME] = 0
+
+	return ioctl(0, syscall.TCSETS, unsafe.Pointer(&t))
+}
+
+func ioctl(fd int, req uint, arg unsafe.Pointer) error {
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), uintptr(req), uintptr(arg)); errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+// printIssue prints the issue file, expanding agetty's backslash escapes.
+func printIssue(path string, tty string) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+
+	var uts syscall.Utsname
+	syscall.Uname(&uts)
+	now := time.Now()
+
+	var out strings.Builder
+	text := string(data)
+	for i := 0; i < len(text); i++ {
+		if text[i] != '\\' || i+1 == len(text) {
+			out.WriteByte(text[i])
+			continue
+		}
+		i++
+		switch text[i] {
+		case 'n':
+			out.WriteString(utsString(uts.Nodename[:]))
+		case 's':
+			out.WriteString(utsString(uts.Sysname[:]))
+		case 'r':
+			out.WriteString(utsString(uts.Release[:]))
+		case 'v':
+			out.WriteString(utsString(uts.Version[:]))
+		case 'm':
+			out.WriteString(utsString(uts.Machine[:]))
+		case 'o':
+			out.WriteString(utsString(uts.Domainname[:]))
+		case 'l':
+			out.WriteString(tty)
+		case 'd':
+			out.WriteString(now.Format("Mon Jan _2 2006"))
+		case 't':
+			out.WriteString(now.Format("15:04:05"))
+		case '\\':
+			out.WriteByte('\\')
+		default:
+			out.WriteByte('\\')
+			out.WriteByte(text[i])
+		}
+	}
+
+	fmt.Print("\r\n", out.String())
+}
+
+// utsString reads a NUL-terminated uname field, of int8 or uint8
+// depending on the architecture.
+func utsString[T int8 | uint8](field []T) string {
+	b := make([]byte, 0, len(field))
+	for _, c := range field {
+		if c == 0 {
+			break
+		}
+		b = append(b, byte(c))
+	}
+	return string(b)
+}
+
+func promptLogin() string {
+	host, _ := os.Hostname()
+	reader := bufio.NewReader(os.Stdin)
+
+	for {
+		if *noHostname || host == "" {
+			fmt.Print("login: ")
+		} else {
+			fmt.Printf("%s login: ", host)
+		}
+
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			// Hangup or EOF: let init respawn us on a fresh line.
+			os.Exit(0)
+		}
+
+		name := strings.TrimSpace(line)
+		if name != "" && !strings.HasPrefix(name, "-") {
+			return name
+		}
+	}
+}
@@ -0,0 +1,524 @@
+package init
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Early boot
+
+type Mount struct {
+	Source string
+	Target string
+	FSType string
+	Flags  uintptr
+	Data   string
+	Mode   os.FileMode
+}
+
+var earlyMounts = []Mount{
+	{"proc", "/proc", "proc", syscall.MS_NOSUID | syscall.MS_NODEV | syscall.MS_NOEXEC, "", 0555},
+	{"sysfs", "/sys", "sysfs", syscall.MS_NOSUID | syscall.MS_NODEV | syscall.MS_NOEXEC, "", 0555},
+	{"udev", "/dev", "devtmpfs", syscall.MS_NOSUID, "mode=0755", 0755},
+	{"devpts", "/dev/pts", "devpts", syscall.MS_NOSUID | syscall.MS_NOEXEC, "gid=5,mode=620,ptmxmode=666", 0755},
+	{"shm", "/dev/shm", "tmpfs", syscall.MS_NOSUID | syscall.MS_NODEV, "mode=1777", 01777},
+	{"run", "/run", "tmpfs", syscall.MS_NOSUID | syscall.MS_NODEV, "mode=0755", 0755},
+	{"tmp", "/tmp", "tmpfs", syscall.MS_NOSUID | syscall.MS_NODEV, "mode=1777", 01777},
+}
+
+var devLinks = map[string]string{
+	"/dev/fd":     "/proc/self/fd",
+	"/dev/stdin":  "/proc/self/fd/0",
+	"/dev/stdout": "/proc/self/fd/1",
+	"/dev/stderr": "/proc/self/fd/2",
+	"/dev/core":   "/proc/kcore",
+}
+
+var mountOptions = map[string]struct {
+	flag  uintptr
+	clear bool
+}{
+	"ro":          {syscall.MS_RDONLY, false},
+	"rw":          {syscall.MS_RDONLY, true},
+	"nosuid":      {syscall.MS_NOSUID, false},
+	"suid":        {syscall.MS_NOSUID, true},
+	"nodev":       {syscall.MS_NODEV, false},
+	"dev":         {syscall.MS_NODEV, true},
+	"noexec":      {syscall.MS_NOEXEC, false},
+	"exec":        {syscall.MS_NOEXEC, true},
+	"sync":        {syscall.MS_SYNCHRONOUS, false},
+	"async":       {syscall.MS_SYNCHRONOUS, true},
+	"noatime":     {syscall.MS_NOATIME, false},
+	"atime":       {syscall.MS_NOATIME, true},
+	"nodiratime":  {syscall.MS_NODIRATIME, false},
+	"relatime":    {syscall.MS_RELATIME, false},
+	"norelatime":  {syscall.MS_RELATIME, true},
+	"strictatime": {syscall.MS_STRICTATIME, false},
+	"dirsync":     {syscall.MS_DIRSYNC, false},
+	"mand":        {syscall.MS_MANDLOCK, false},
+	"nomand":      {syscall.MS_MANDLOCK, true},
+	"bind":        {syscall.MS_BIND, false},
+	"rbind":       {syscall.MS_BIND | syscall.MS_REC, false},
+	"remount":     {syscall.MS_REMOUNT, false},
+}
+
+// bootFailures collects what went wrong during early boot so the user
+// can fix it from the emergency shell before the boot continues.
+var bootFailures []string
+
+func bootFailure(format string, a ...interface{}) {
+	msg := fmt.Sprintf(format, a...)
+	debug("FAILED %s", msg)
+	fmt.Printf("[\033[31m!\033[0m] \033[31mFAILED\033[0m %s\n", msg)
+	bootFailures = append(bootFailures, msg)
+}
+
+func setupFilesystems() {
+	PrintLn("Mounting filesystems")
+	mountVirtualFS()
+	createDevLinks()
+	mountFstab()
+	setHostname()
+
+	if len(bootFailures) > 0 {
+		recoverBootFailures()
+	}
+}
+
+func mountVirtualFS() {
+	for _, m := range earlyMounts {
+		if err := mountOne(m); err != nil {
+			bootFailure("mount %s on %s: %v", m.FSType, m.Target, err)
+		}
+	}
+}
+
+// mountOne creates the mount point if needed and mounts m, treating an
+// already mounted target as success.
+func mountOne(m Mount) error {
+	mode := m.Mode
+	if mode == 0 {
+		mode = 0755
+	}
+	if err := os.MkdirAll(m.Target, mode); err != nil {
+		return err
+	}
+	if m.Flags&syscall.MS_REMOUNT == 0 && isMounted(m.Target) {
+		return nil
+	}
+
+	err := syscall.Mount(m.Source, m.Target, m.FSType, m.Flags, m.Data)
+	if err == syscall.EBUSY {
+		return nil
+	}
+	return err
+}
+
+func isMounted(target string) bool {
+	data, err := os.ReadFile("/proc/mounts")
+	if err != nil {
+		return false
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) > 1 && fields[1] == target {
+			return true
+		}
+	}
+	return false
+}
+
+func createDevLinks() {
+	for link, target := range devLinks {
+		if _, err := os.Lstat(link); err == nil {
+			continue
+		}
+		if err := os.Symlink(target, link); err != nil {
+			bootFailure("symlink %s -> %s: %v", link, target, err)
+		}
+	}
+}
+
+// mountFstab mounts every /etc/fstab entry that is not noauto, swap or
+// already mounted, checking it first when its pass number asks for it.
+func mountFstab() {
+	data, err := os.ReadFile(FSTAB_PATH)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			bootFailure("read %s: %v", FSTAB_PATH, err)
+		}
+		return
+	}
+
+	for n, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) < 4 {
+			bootFailure("%s:%d: expected at least 4 fields", FSTAB_PATH, n+1)
+			continue
+		}
+		for len(fields) < 6 {
+			fields = append(fields, "0")
+		}
+
+		source := resolveSource(fields[0])
+		target := fields[1]
+		fstype := fields[2]
+		flags, extra, noauto := parseMountOptions(fields[3])
+
+		if noauto || fstype == "swap" || target == "none" {
+			continue
+		}
+		if isMounted(target) {
+			continue
+		}
+
+		if pass, _ := strconv.Atoi(fields[5]); pass > 0 {
+			if err := runFsck(source, fstype); err != nil {
+				bootFailure("fsck %s: %v", source, err)
+				continue
+			}
+		}
+
+		Printf("Mounting %s on %s\n", source, target)
+		if err := mountOne(Mount{source, target, fstype, flags, extra, 0755}); err != nil {
+			bootFailure("mount %s on %s: %v", source, target, err)
+		}
+	}
+}
+
+// parseMountOptions splits an fstab option list into mount(2) flags and the
+// filesystem-specific data string.
+func parseMountOptions(opts string) (flags uintptr, data string, noauto bool) {
+	var extra []string
+	for _, opt := range strings.Split(opts, ",") {
+		switch opt {
+		case "", "defaults", "auto", "nofail", "user", "nouser", "users", "_netdev":
+			continue
+		case "noauto":
+			noauto = true
+			continue
+		}
+		if mo, ok := mountOptions[opt]; ok {
+			if mo.clear {
+				flags &^= mo.flag
+			} else {
+				flags |= mo.flag
+			}
+			continue
+		}
+		extra = append(extra, opt)
+	}
+	return flags, strings.Join(extra, ","), noauto
+}
+
+// resolveSource turns UUID=, LABEL=, PARTUUID= and PARTLABEL= specs into
+// device paths through the /dev/disk symlinks.
+func resolveSource(spec string) string {
+	for prefix, dir := range map[string]string{
+		"UUID=":      "/dev/disk/by-uuid/",
+		"LABEL=":     "/dev/disk/by-label/",
+		"PARTUUID=":  "/dev/disk/by-partuuid/",
+		"PARTLABEL=": "/dev/disk/by-partlabel/",
+	} {
+		if strings.HasPrefix(spec, prefix) {
+			path := dir + strings.TrimPrefix(spec, prefix)
+			if dev, err := filepath.EvalSymlinks(path); err == nil {
+				return dev
+			}
+			return path
+		}
+	}
+	return spec
+}
+
+// runFsck is the fsck hook: it runs fsck.TYPE (or a generic fsck) in
+// automatic repair mode when one is installed and skips the check otherwise.
+func runFsck(device, fstype string) error {
+	var checker string
+	for _, path := range []string{
+		"/sbin/fsck." + fstype, "/bin/fsck." + fstype,
+		"/sbin/fsck", "/bin/fsck",
+	} {
+		if info, err := os.Stat(path); err == nil && info.Mode()&0111 != 0 {
+			checker = path
+			break
+		}
+	}
+	if checker == "" {
+		debug("no fsck for %s, skipping check of %s", fstype, device)
+		return nil
+	}
+
+	Printf("Checking %s\n", device)
+	cmd := exec.Command(checker, "-a", device)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	err := cmd.Run()
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		// 1: errors corrected, 2: corrected and reboot advised, 4+: left uncorrected
+		if code := exitErr.ExitCode(); code > 0 && code < 4 {
+			return nil
+		}
+		return fmt.Errorf("%s exited with status %d", checker, exitErr.ExitCode())
+	}
+	return err
+}
+
+func setHostname() {
+	data, err := os.ReadFile(HOSTNAME_PATH)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			bootFailure("read %s: %v", HOSTNAME_PATH, err)
+		}
+		return
+	}
+
+	name := strings.TrimSpace(strings.SplitN(string(data), "\n", 2)[0])
+	if name == "" {
+		return
+	}
+	if err := syscall.Sethostname([]byte(name)); err != nil {
+		bootFailure("set hostname %s: %v", name, err)
+	}
+}
+
+// Switch root
+
+const (
+	NEWROOT      = "/newroot"
+	RAMFS_MAGIC  = 0x858458f6
+	TMPFS_MAGIC  = 0x01021994
+	ROOT_TIMEOUT = 10 * time.Second
+)
+
+var realInits = []string{"/sbin/init", "/etc/init", "/bin/init"}
+
+func inInitramfs() bool {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs("/", &st); err != nil {
+		return false
+	}
+	// f_type is signed on 32-bit architectures.
+	return uint32(st.Type) == RAMFS_MAGIC || uint32(st.Type) == TMPFS_MAGIC
+}
+
+// switchRoot mounts the root= device, moves the API filesystems into it,
+// frees the initramfs and execs the real init. It only returns on failure,
+// after the user has had a chance to look around in the emergency shell.
+func switchRoot(root string) {
+	Printf("Switching root to %s\n", root)
+
+	if err := doSwitchRoot(root); err != nil {
+		syscall.Unmount(NEWROOT, syscall.MNT_DETACH)
+		bootFailure("switch root to %s: %v", root, err)
+		PrintLn("Continuing from the initramfs")
+		recoverBootFailures()
+	}
+}
+
+// doSwitchRoot returns errors only while the initramfs is intact, with the
+// API filesystems back in place. Once it has started deleting the initramfs
+// there is no shell left to fall back to, and a failure ends init.
+func doSwitchRoot(root string) error {
+	device, err := waitForDevice(root)
+	if err != nil {
+		return err
+	}
+
+	if err := mountRoot(device); err != nil {
+		return err
+	}
+
+	initPath, err := findRealInit()
+	if err != nil {
+		return err
+	}
+
+	var moved []string
+	restore := func() {
+		for i := len(moved) - 1; i >= 0; i-- {
+			dir := moved[i]
+			if err := syscall.Mount(NEWROOT+dir, dir, "", syscall.MS_MOVE, ""); err != nil {
+				Printf("Failed to move %s back: %v\n", dir, err)
+			}
+		}
+	}
+	for _, dir := range []string{"/dev", "/proc", "/sys", "/run"} {
+		target := NEWROOT + dir
+		os.MkdirAll(target, 0755)
+		if err := syscall.Mount(dir, target, "", syscall.MS_MOVE, ""); err != nil {
+			restore()
+			return fmt.Errorf("move %s: %v", dir, err)
+		}
+		moved = append(moved, dir)
+	}
+
+	if err := os.Chdir(NEWROOT); err != nil {
+		restore()
+		return err
+	}
+
+	// Past this point the initramfs is being torn down; there is nothing
+	// left to fall back to.
+	syscall.Unmount("/tmp", syscall.MNT_DETACH)
+	var rootStat syscall.Stat_t
+	syscall.Stat("/", &rootStat)
+	removeInitramfs("/", uint64(rootStat.Dev))
+
+	if err := syscall.Mount(".", "/", "", syscall.MS_MOVE, ""); err != nil {
+		switchRootFailed("move %s to /: %v", NEWROOT, err)
+	}
+	if err := syscall.Chroot("."); err != nil {
+		switchRootFailed("chroot: %v", err)
+	}
+	os.Chdir("/")
+
+	Printf("Executing %s\n", initPath)
+	if syslogConn != nil {
+		syslogConn.Close()
+	}
+	err = syscall.Exec(initPath, []string{initPath}, os.Environ())
+	switchRootFailed("exec %s: %v", initPath, err)
+	return nil
+}
+
+// switchRootFailed reports a failure after the initramfs is gone and exits,
+// leaving the kernel to report the death of init.
+func switchRootFailed(format string, args ...interface{}) {
+	msg := fmt.Sprintf(format, args...)
+	debug("FAILED %s", msg)
+	fmt.Printf("[\033[31m!\033[0m] \033[31mFAILED\033[0m switch root with the initramfs removed: %s\n", msg)
+	os.Exit(1)
+}
+
+// waitForDevice polls for the root device, which may show up late for
+// USB or other asynchronously probed disks. "rootwait" waits forever,
+// "rootdelay=N" waits N seconds instead of ROOT_TIMEOUT.
+func waitForDevice(root string) (string, error) {
+	timeout := ROOT_TIMEOUT
+	if delay, ok := kernelParam("rootdelay"); ok {
+		if n, err := strconv.Atoi(delay); err == nil {
+			timeout = time.Duration(n) * time.Second
+		}
+	}
+	forever := kernelFlag("rootwait")
+
+	deadline := time.Now().Add(timeout)
+	announced := false
+	for {
+		device := resolveSource(root)
+		if _, err := os.Stat(device); err == nil {
+			return device, nil
+		}
+		if !forever && time.Now().After(deadline) {
+			return "", fmt.Errorf("%s did not appear within %v", root, timeout)
+		}
+		if !announced {
+			Printf("Waiting for %s\n", root)
+			announced = true
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+// mountRoot mounts device on NEWROOT, read-only unless "rw" was given,
+// trying every block filesystem the kernel knows if rootfstype is unset.
+func mountRoot(device string) error {
+	if err := os.MkdirAll(NEWROOT, 0755); err != nil {
+		return err
+	}
+
+	var flags uintptr
+	var data string
+	if opts, ok := kernelParam("rootflags"); ok {
+		flags, data, _ = parseMountOptions(opts)
+	}
+	if !kernelFlag("rw") {
+		flags |= syscall.MS_RDONLY
+	}
+
+	types := blockFilesystems()
+	if fstype, ok := kernelParam("rootfstype"); ok {
+		types = strings.Split(fstype, ",")
+	}
+
+	var lastErr error = fmt.Errorf("no filesystem types to try")
+	for _, fstype := range types {
+		lastErr = syscall.Mount(device, NEWROOT, fstype, flags, data)
+		if lastErr == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("mount %s: %v", device, lastErr)
+}
+
+func blockFilesystems() []string {
+	data, err := os.ReadFile("/proc/filesystems")
+	if err != nil {
+		return nil
+	}
+	var types []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if line == "" || strings.HasPrefix(line, "nodev") {
+			continue
+		}
+		types = append(types, strings.TrimSpace(line))
+	}
+	return types
+}
+
+func findRealInit() (string, error) {
+	candidates := realInits
+	if path, ok := kernelParam("init"); ok {
+		candidates = []string{path}
+	}
+	for _, path := range candidates {
+		if info, err := os.Stat(NEWROOT + path); err == nil && info.Mode().IsRegular() && info.Mode()&0111 != 0 {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("no init found on the new root (tried %s)", strings.Join(candidates, ", "))
+}
+
+// removeInitramfs deletes everything under dir that lives on the
+// initramfs itself, never descending into other mounts such as NEWROOT.
+func removeInitramfs(dir string, rootDev uint64) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		var st syscall.Stat_t
+		if syscall.Lstat(path, &st) != nil || uint64(st.Dev) != rootDev {
+			continue
+		}
+		if entry.IsDir() {
+			removeInitramfs(path, rootDev)
+			syscall.Rmdir(path)
+		} else {
+			syscall.Unlink(path)
+		}
+	}
+}
+
+func recoverBootFailures() {
+	Printf("%d early boot step(s) failed:\n", len(bootFailures))
+	for _, msg := range bootFailures {
+		Printf("  %s\n", msg)
+	}
+	PrintLn("Fix the problem and exit the shell to continue booting")
+	bootFailures = nil
+	startEmergencyShell()
+}
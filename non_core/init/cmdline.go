@@ -0,0 +1,114 @@
+package init
+
+import (
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Kernel command line
+
+type CmdlineParam struct {
+	Key   string
+	Value string
+}
+
+// cmdline holds every value given for each parameter, in order, so that
+// repeatable options like init.disable= keep all of theirs.
+var cmdline = make(map[string][]string)
+
+// loadCmdline parses /proc/cmdline and applies the init.* options. It runs
+// before anything is printed, so /proc is mounted here if need be.
+func loadCmdline() {
+	data, err := ioutil.ReadFile("/proc/cmdline")
+	if err != nil {
+		mountOne(earlyMounts[0])
+		data, _ = ioutil.ReadFile("/proc/cmdline")
+	}
+
+	for _, p := range parseCmdline(string(data)) {
+		cmdline[p.Key] = append(cmdline[p.Key], p.Value)
+	}
+
+	quiet = kernelFlag("quiet")
+	debugMode = kernelFlag("init.debug")
+	if shell, ok := kernelParam("init.shell"); ok && shell != "" {
+		shellPath = shell
+	}
+	if secs, ok := kernelParam("init.timeout"); ok {
+		if n, err := strconv.Atoi(secs); err == nil && n > 0 {
+			waitTimeout = time.Duration(n) * time.Second
+		}
+	}
+	for _, list := range cmdline["init.disable"] {
+		for _, id := range strings.Split(list, ",") {
+			disabled[id] = true
+		}
+	}
+
+	if level, ok := kernelParam("init.runlevel"); ok && len(level) == 1 && strings.Contains("0123456S", level) {
+		currentRunlevel = level
+	}
+	for _, word := range []string{"single", "S", "s", "1"} {
+		if kernelFlag(word) {
+			currentRunlevel = "1"
+		}
+	}
+}
+
+// parseCmdline splits a kernel command line into key=value tokens the way
+// the kernel does: whitespace separates tokens unless inside double quotes,
+// which may wrap the whole token or just the value and are removed.
+// Tokens after a bare "--" are arguments for init, not parameters.
+func parseCmdline(line string) []CmdlineParam {
+	var params []CmdlineParam
+	var tok strings.Builder
+	inQuote, quoted := false, false
+
+	flush := func() bool {
+		if tok.Len() == 0 && !quoted {
+			return true
+		}
+		word := tok.String()
+		tok.Reset()
+		quoted = false
+		if word == "--" {
+			return false
+		}
+		key, value, _ := strings.Cut(word, "=")
+		params = append(params, CmdlineParam{Key: key, Value: value})
+		return true
+	}
+
+	for _, r := range line {
+		switch {
+		case r == '"':
+			inQuote = !inQuote
+			quoted = true
+		case !inQuote && (r == ' ' || r == '\t' || r == '\n'):
+			if !flush() {
+				return params
+			}
+		default:
+			tok.WriteRune(r)
+		}
+	}
+	flush()
+	return params
+}
+
+// kernelFlag reports whether a parameter such as "rw" or "quiet" was given.
+func kernelFlag(name string) bool {
+	_, ok := cmdline[name]
+	return ok
+}
+
+// kernelParam returns the last value given for a name=value parameter.
+func kernelParam(name string) (string, bool) {
+	values := cmdline[name]
+	if len(values) == 0 {
+		return "", false
+	}
+	return values[len(values)-1], true
+}
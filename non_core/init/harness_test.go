@@ -0,0 +1,293 @@
+package init
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// The boot harness runs the real init binary as PID 1 of a new PID and
+// mount namespace, chrooted into a scratch root filesystem. It needs root
+// and a Go toolchain; it is skipped otherwise and with -short.
+//
+// The test binary re-executes itself as the namespace's first process
+// (HARNESS_ROOT set), makes its mounts private, prepares /proc and /dev so
+// that init leaves the host alone, chroots and execs /bin/init.
+
+const HARNESS_ROOT = "INIT_HARNESS_ROOT"
+
+// harnessInittab covers the start order, respawn and shutdown paths. svc
+// appends its name to a log file and, when given "forever", waits for
+// SIGTERM and logs that too.
+const harnessInittab = `si::sysinit:/bin/svc /order.log sysinit
+w0:2:wait:/bin/svc /order.log wait
+on:2:once:/bin/svc /order.log once
+dm:2:respawn:/bin/svc /order.log daemon forever
+rs:2:respawn:/bin/svc /respawn.log respawn
+x3:3:respawn:/bin/svc /order.log level3 forever
+sd::shutdown:/bin/svc /order.log shutdown
+`
+
+const svcSource = `package main
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+func main() {
+	log := func(msg string) {
+		f, err := os.OpenFile(os.Args[1], os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err == nil {
+			f.WriteString(msg + "\n")
+			f.Close()
+		}
+	}
+	if len(os.Args) > 3 {
+		ch := make(chan os.Signal, 1)
+		signal.Notify(ch, syscall.SIGTERM)
+		log(os.Args[2])
+		<-ch
+		log(os.Args[2] + " stopped")
+		return
+	}
+	log(os.Args[2])
+}
+`
+
+func TestMain(m *testing.M) {
+	if root := os.Getenv(HARNESS_ROOT); root != "" {
+		if err := harnessChild(root); err != nil {
+			fmt.Fprintln(os.Stderr, "harness:", err)
+		}
+		os.Exit(1)
+	}
+	os.Exit(m.Run())
+}
+
+// harnessChild runs as PID 1 of the new namespaces and becomes init.
+func harnessChild(root string) error {
+	if err := syscall.Mount("", "/", "", syscall.MS_REC|syscall.MS_PRIVATE, ""); err != nil {
+		return fmt.Errorf("make mounts private: %v", err)
+	}
+
+	// A /proc for the new PID namespace, with the kernel command line and
+	// printk level replaced by files from the scratch root.
+	proc := filepath.Join(root, "proc")
+	if err := syscall.Mount("proc", proc, "proc", 0, ""); err != nil {
+		return fmt.Errorf("mount proc: %v", err)
+	}
+	binds := map[string]string{
+		filepath.Join(root, "harness/cmdline"): filepath.Join(proc, "cmdline"),
+		filepath.Join(root, "harness/printk"):  filepath.Join(proc, "sys/kernel/printk"),
+	}
+	for src, dst := range binds {
+		if err := syscall.Mount(src, dst, "", syscall.MS_BIND, ""); err != nil {
+			return fmt.Errorf("bind %s: %v", dst, err)
+		}
+	}
+
+	// A private /dev instead of the host's devtmpfs.
+	dev := filepath.Join(root, "dev")
+	if err := syscall.Mount("tmpfs", dev, "tmpfs", 0, "mode=0755"); err != nil {
+		return fmt.Errorf("mount dev: %v", err)
+	}
+	if err := syscall.Mknod(filepath.Join(dev, "null"), syscall.S_IFCHR|0666, 1<<8|3); err != nil {
+		return fmt.Errorf("mknod null: %v", err)
+	}
+
+	if err := syscall.Chroot(root); err != nil {
+		return err
+	}
+	if err := syscall.Chdir("/"); err != nil {
+		return err
+	}
+	return syscall.Exec("/bin/init", []string{"init"}, []string{"PATH=/bin"})
+}
+
+// harnessBuild builds the binaries the scratch root needs: the multi-call
+// binary, linked as init and gsh, and the svc test service.
+func harnessBuild(t *testing.T, root string) {
+	svc := filepath.Join(t.TempDir(), "svc.go")
+	if err := os.WriteFile(svc, []byte(svcSource), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	builds := map[string]string{
+		"coreutils": "../../cmd/coreutils",
+		"svc":       svc,
+	}
+	for name, src := range builds {
+		cmd := exec.Command("go", "build", "-o", filepath.Join(root, "bin", name), src)
+		cmd.Env = append(os.Environ(), "CGO_ENABLED=0")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("go build %s: %v\n%s", name, err, out)
+		}
+	}
+	for _, name := range []string{"init", "gsh"} {
+		if err := os.Symlink("coreutils", filepath.Join(root, "bin", name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func harnessRoot(t *testing.T) string {
+	root := t.TempDir()
+	for _, dir := range []string{"bin", "etc", "proc", "sys", "dev", "run", "tmp", "var/log", "harness"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	files := map[string]string{
+		"etc/inittab":     harnessInittab,
+		"harness/cmdline": "init.watchdog=0 init.timeout=2\n",
+		"harness/printk":  "",
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func readLines(path string) []string {
+	data, _ := os.ReadFile(path)
+	return strings.Fields(strings.ReplaceAll(string(data), " stopped", "-stopped"))
+}
+
+// waitFor polls cond until it holds or timeout passes.
+func waitFor(timeout time.Duration, cond func() bool) bool {
+	for deadline := time.Now().Add(timeout); time.Now().Before(deadline); {
+		if cond() {
+			return true
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	return cond()
+}
+
+func sendInitRequest(pid int, cmd int32, runlevel rune) error {
+	req := InitRequest{Cmd: cmd, Runlevel: int32(runlevel)}
+	copy(req.Magic[:], INITCTL_MAGIC)
+
+	// The FIFO lives on init's private /run; reach it through its root.
+	fifo := fmt.Sprintf("/proc/%d/root%s", pid, INITCTL_FIFO)
+	f, err := os.OpenFile(fifo, os.O_WRONLY, 0)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return binary.Write(f, binary.LittleEndian, req)
+}
+
+func TestBootInNamespace(t *testing.T) {
+	if testing.Short() {
+		t.Skip("boot harness skipped in short mode")
+	}
+	if os.Getuid() != 0 {
+		t.Skip("boot harness needs root")
+	}
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("boot harness needs the go tool")
+	}
+
+	root := harnessRoot(t)
+	harnessBuild(t, root)
+
+	var console bytes.Buffer
+	cmd := exec.Command(os.Args[0], "-test.run=^$")
+	cmd.Env = append(os.Environ(), HARNESS_ROOT+"="+root)
+	cmd.Stdout = &console
+	cmd.Stderr = &console
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Cloneflags: syscall.CLONE_NEWPID | syscall.CLONE_NEWNS | syscall.CLONE_NEWUTS | syscall.CLONE_NEWIPC,
+	}
+	if err := cmd.Start(); err != nil {
+		if errors.Is(err, syscall.EPERM) || errors.Is(err, syscall.EINVAL) {
+			t.Skipf("cannot create namespaces: %v", err)
+		}
+		t.Fatal(err)
+	}
+
+	exited := make(chan error, 1)
+	go func() { exited <- cmd.Wait() }()
+	defer func() {
+		cmd.Process.Kill()
+		if t.Failed() {
+			t.Logf("console:\n%s", console.String())
+		}
+	}()
+
+	orderLog := filepath.Join(root, "order.log")
+	respawnLog := filepath.Join(root, "respawn.log")
+
+	// Start order: sysinit, then wait, then the once and respawn entries.
+	if !waitFor(10*time.Second, func() bool { return len(readLines(orderLog)) >= 4 }) {
+		t.Fatalf("services did not start, order.log: %q", readLines(orderLog))
+	}
+	order := readLines(orderLog)
+	if order[0] != "sysinit" || order[1] != "wait" {
+		t.Errorf("start order = %q, want sysinit then wait first", order)
+	}
+	later := strings.Join(order[2:], " ")
+	if !strings.Contains(later, "once") || !strings.Contains(later, "daemon") {
+		t.Errorf("start order = %q, want once and daemon after wait", order)
+	}
+
+	// Respawn: the entry that exits at once keeps being restarted.
+	if !waitFor(10*time.Second, func() bool { return len(readLines(respawnLog)) >= 3 }) {
+		t.Errorf("respawn entry started %d times, want at least 3", len(readLines(respawnLog)))
+	}
+
+	// Runlevel 3 starts x3 and stops the daemon, which is not part of it.
+	if err := sendInitRequest(cmd.Process.Pid, INITCTL_RUNLEVEL, '3'); err != nil {
+		t.Fatalf("initctl: %v", err)
+	}
+	if !waitFor(10*time.Second, func() bool {
+		order := strings.Join(readLines(orderLog), " ")
+		return strings.Contains(order, "daemon-stopped") && strings.Contains(order, "level3")
+	}) {
+		t.Errorf("runlevel 3: order.log = %q", readLines(orderLog))
+	}
+
+	// Shutdown: x3 is stopped before the shutdown entry runs, then init
+	// powers off, which ends a PID namespace's init with SIGINT.
+	if err := sendInitRequest(cmd.Process.Pid, INITCTL_SHUTDOWN, 0); err != nil {
+		t.Fatalf("initctl: %v", err)
+	}
+	select {
+	case err := <-exited:
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			t.Fatalf("init exited with %v", err)
+		}
+		status := exitErr.Sys().(syscall.WaitStatus)
+		if !status.Signaled() || status.Signal() != syscall.SIGINT {
+			t.Errorf("init ended with %v, want killed by SIGINT from power off", err)
+		}
+	case <-time.After(30 * time.Second):
+		t.Fatalf("init did not power off")
+	}
+
+	order = readLines(orderLog)
+	if n := len(order); n < 2 || order[n-2] != "level3-stopped" || order[n-1] != "shutdown" {
+		t.Errorf("shutdown order = %q, want level3-stopped then shutdown last", order)
+	}
+
+	count := len(readLines(respawnLog))
+	time.Sleep(2 * time.Second)
+	if len(readLines(respawnLog)) != count {
+		t.Errorf("respawn entry still running after shutdown")
+	}
+}
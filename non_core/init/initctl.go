@@ -0,0 +1,101 @@
+package init
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"syscall"
+)
+
+// Initctl
+
+// InitRequest is one message on INITCTL_FIFO: the magic "INIT", a command
+// and, for INITCTL_RUNLEVEL, the runlevel as a character, all little
+// endian. Twelve bytes is well under PIPE_BUF, so requests from several
+// writers never interleave.
+type InitRequest struct {
+	Magic    [4]byte
+	Cmd      int32
+	Runlevel int32
+}
+
+const (
+	INITCTL_MAGIC    = "INIT"
+	INITCTL_RUNLEVEL = 0
+	INITCTL_SHUTDOWN = 1
+)
+
+// RUNLEVELS are the levels a runlevel request may ask for.
+const RUNLEVELS = "0123456Ss"
+
+// readInitRequest reads and validates one request.
+func readInitRequest(r io.Reader) (InitRequest, error) {
+	var req InitRequest
+	if err := binary.Read(r, binary.LittleEndian, &req); err != nil {
+		return req, err
+	}
+	if string(req.Magic[:]) != INITCTL_MAGIC {
+		return req, fmt.Errorf("bad magic %q", req.Magic[:])
+	}
+
+	switch req.Cmd {
+	case INITCTL_RUNLEVEL:
+		if req.Runlevel <= 0 || req.Runlevel > 0x7f || !strings.ContainsRune(RUNLEVELS, rune(req.Runlevel)) {
+			return req, fmt.Errorf("bad runlevel %d", req.Runlevel)
+		}
+	case INITCTL_SHUTDOWN:
+	default:
+		return req, fmt.Errorf("unknown command %d", req.Cmd)
+	}
+	return req, nil
+}
+
+// level returns the runlevel a request asks for, with S meaning 1.
+func (req InitRequest) level() string {
+	level := string(rune(req.Runlevel))
+	if level == "S" || level == "s" {
+		return "1"
+	}
+	return level
+}
+
+func startInitctlServer() {
+	PrintLn("Starting initctl server")
+	os.Remove(INITCTL_FIFO)
+	syscall.Mkfifo(INITCTL_FIFO, 0600)
+
+	go func() {
+		// Opened read-write so that there is always a writer and the last
+		// client closing its end does not leave us reading EOF forever.
+		f, err := os.OpenFile(INITCTL_FIFO, os.O_RDWR, 0)
+		if err != nil {
+			Printf("Error opening %s: %v\n", INITCTL_FIFO, err)
+			return
+		}
+		defer f.Close()
+
+		for {
+			req, err := readInitRequest(f)
+			if err != nil {
+				Printf("Bad initctl request: %v\n", err)
+				continue
+			}
+			handleInitRequest(req)
+		}
+	}()
+}
+
+func handleInitRequest(req InitRequest) {
+	switch req.Cmd {
+	case INITCTL_RUNLEVEL:
+		if req.level() == "0" {
+			shutdown()
+			return
+		}
+		changeRunlevel(req.level())
+	case INITCTL_SHUTDOWN:
+		shutdown()
+	}
+}
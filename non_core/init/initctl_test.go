@@ -0,0 +1,63 @@
+package init
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+func encodeInitRequest(magic string, cmd int32, runlevel rune) []byte {
+	req := InitRequest{Cmd: cmd, Runlevel: int32(runlevel)}
+	copy(req.Magic[:], magic)
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, req)
+	return buf.Bytes()
+}
+
+func TestReadInitRequest(t *testing.T) {
+	tests := []struct {
+		name  string
+		data  []byte
+		ok    bool
+		level string
+	}{
+		{"runlevel 3", encodeInitRequest("INIT", INITCTL_RUNLEVEL, '3'), true, "3"},
+		{"single user", encodeInitRequest("INIT", INITCTL_RUNLEVEL, 'S'), true, "1"},
+		{"shutdown", encodeInitRequest("INIT", INITCTL_SHUTDOWN, 0), true, "\x00"},
+		{"bad magic", encodeInitRequest("TINI", INITCTL_RUNLEVEL, '3'), false, ""},
+		{"bad runlevel", encodeInitRequest("INIT", INITCTL_RUNLEVEL, '9'), false, ""},
+		{"unknown command", encodeInitRequest("INIT", 7, 0), false, ""},
+		{"short", []byte("INIT\x00\x00"), false, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := readInitRequest(bytes.NewReader(tt.data))
+			if (err == nil) != tt.ok {
+				t.Fatalf("err = %v, want ok %v", err, tt.ok)
+			}
+			if tt.ok && req.Cmd == INITCTL_RUNLEVEL && req.level() != tt.level {
+				t.Errorf("level = %q, want %q", req.level(), tt.level)
+			}
+		})
+	}
+}
+
+func TestReadInitRequestStream(t *testing.T) {
+	var stream []byte
+	stream = append(stream, encodeInitRequest("INIT", INITCTL_RUNLEVEL, '5')...)
+	stream = append(stream, encodeInitRequest("INIT", INITCTL_SHUTDOWN, 0)...)
+	r := bytes.NewReader(stream)
+
+	first, err := readInitRequest(r)
+	if err != nil || first.level() != "5" {
+		t.Fatalf("first = %+v, %v", first, err)
+	}
+	second, err := readInitRequest(r)
+	if err != nil || second.Cmd != INITCTL_SHUTDOWN {
+		t.Fatalf("second = %+v, %v", second, err)
+	}
+	if _, err := readInitRequest(r); err == nil {
+		t.Fatalf("read past the end of the stream")
+	}
+}
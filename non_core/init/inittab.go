@@ -0,0 +1,480 @@
+package init
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Inittab
+
+// BOOT_RUNLEVEL is the "previous" runlevel while booting, as runlevel(8)
+// reports it.
+const BOOT_RUNLEVEL = "N"
+
+// STOP_TIMEOUT is how long an entry gets between SIGTERM and SIGKILL when
+// it leaves the runlevel.
+const STOP_TIMEOUT = 5 * time.Second
+
+var inittabActions = map[string]bool{
+	"sysinit":  true,
+	"wait":     true,
+	"once":     true,
+	"respawn":  true,
+	"shutdown": true,
+	"off":      true,
+}
+
+// loadInittab merges INITTAB_PATH into the entry list.
+func loadInittab() {
+	entries, ok := readInittab()
+	if !ok {
+		return
+	}
+	stateMu.Lock()
+	processes = mergeInittab(processes, entries)
+	stateMu.Unlock()
+}
+
+// readInittab reads INITTAB_PATH and logs its bad lines. It is false if
+// the file cannot be read. It does not take stateMu.
+func readInittab() ([]*Process, bool) {
+	PrintLn("Loading inittab")
+	file, err := os.Open(INITTAB_PATH)
+	if err != nil {
+		Printf("Error loading %s: %v\n", INITTAB_PATH, err)
+		return nil, false
+	}
+	defer file.Close()
+
+	entries, errs := parseInittab(file)
+	for _, err := range errs {
+		Printf("%s: %v\n", INITTAB_PATH, err)
+	}
+	return entries, true
+}
+
+// parseInittab reads "id:runlevels:action:command" entries. Bad lines are
+// reported and skipped so that one typo cannot stop the boot.
+func parseInittab(r io.Reader) ([]*Process, []error) {
+	var entries []*Process
+	var errs []error
+	seen := make(map[string]bool)
+
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		proc, err := parseInittabLine(scanner.Text())
+		if err != nil {
+			errs = append(errs, fmt.Errorf("line %d: %v", n, err))
+			continue
+		}
+		if proc == nil {
+			continue
+		}
+		if seen[proc.ID] {
+			errs = append(errs, fmt.Errorf("line %d: duplicate id %q", n, proc.ID))
+			continue
+		}
+		seen[proc.ID] = true
+		entries = append(entries, proc)
+	}
+	if err := scanner.Err(); err != nil {
+		errs = append(errs, err)
+	}
+	return entries, errs
+}
+
+// parseInittabLine returns nil for blank lines and comments. The command
+// is everything after the third colon, so it may contain colons itself.
+func parseInittabLine(line string) (*Process, error) {
+	if strings.HasPrefix(line, "#") || strings.TrimSpace(line) == "" {
+		return nil, nil
+	}
+
+	parts := strings.SplitN(line, ":", 4)
+	if len(parts) < 4 {
+		return nil, fmt.Errorf("expected id:runlevels:action:command")
+	}
+	if parts[0] == "" {
+		return nil, fmt.Errorf("missing id")
+	}
+	if !inittabActions[parts[2]] {
+		return nil, fmt.Errorf("unknown action %q", parts[2])
+	}
+	if strings.TrimSpace(parts[3]) == "" {
+		return nil, fmt.Errorf("missing command for %s", parts[0])
+	}
+
+	return &Process{
+		ID:        parts[0],
+		Runlevels: parts[1],
+		Action:    parts[2],
+		Command:   parts[3],
+	}, nil
+}
+
+// mergeInittab replaces the entry list on reload. An entry whose id is
+// still present keeps its running process, so monitors holding the old
+// pointer carry on with the new settings.
+func mergeInittab(old, entries []*Process) []*Process {
+	byID := make(map[string]*Process)
+	for _, proc := range old {
+		byID[proc.ID] = proc
+	}
+
+	merged := make([]*Process, 0, len(entries))
+	for _, entry := range entries {
+		if proc, ok := byID[entry.ID]; ok {
+			proc.Runlevels = entry.Runlevels
+			proc.Action = entry.Action
+			proc.Command = entry.Command
+			entry = proc
+		}
+		merged = append(merged, entry)
+	}
+	return merged
+}
+
+// runInittab starts every entry with the given action and waits for it,
+// as inittab requires for sysinit, wait and the shutdown actions.
+func runInittab(action string, runlevel string) {
+	var procs []*Process
+	stateMu.Lock()
+	for _, proc := range processes {
+		if proc.Action != action {
+			continue
+		}
+		if runlevel != "" && !inRunlevel(proc, runlevel) {
+			continue
+		}
+		procs = append(procs, proc)
+	}
+	stateMu.Unlock()
+
+	for _, proc := range procs {
+		stateMu.Lock()
+		claimed := claimProcess(proc)
+		stateMu.Unlock()
+		if !claimed {
+			continue
+		}
+		if cmd := startProcess(proc); cmd != nil {
+			waitProcess(proc, cmd, waitTimeout)
+		}
+	}
+}
+
+// waitProcess waits for cmd, killing its session if it outlives timeout
+// so that one stuck entry cannot hold up the boot.
+func waitProcess(proc *Process, cmd *exec.Cmd, timeout time.Duration) {
+	done := make(chan struct{})
+	go func() {
+		cmd.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(timeout):
+		Printf("Process %s did not finish within %v, killing it\n", proc.ID, timeout)
+		syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+		<-done
+	}
+	stateMu.Lock()
+	proc.PID = 0
+	proc.Status = "exited"
+	stateMu.Unlock()
+	Debugf("process %s exited with status %d", proc.ID, cmd.ProcessState.ExitCode())
+}
+
+// runlevelChanges works out a switch from one runlevel to another, with
+// stateMu held. Running
+// entries that are not part of the new level are stopped. Respawn entries
+// of the new level are started if they are not running; once and wait
+// entries only when the level is newly entered, with wait entries first
+// because they must finish before anything else starts.
+func runlevelChanges(procs []*Process, from, to string) (stop, start []*Process) {
+	var waits, others []*Process
+	for _, proc := range procs {
+		in := inRunlevel(proc, to)
+		if proc.PID != 0 {
+			if !in {
+				stop = append(stop, proc)
+			}
+			continue
+		}
+		if !in {
+			continue
+		}
+
+		entered := from == BOOT_RUNLEVEL || !inRunlevel(proc, from)
+		switch proc.Action {
+		case "respawn":
+			others = append(others, proc)
+		case "once":
+			if entered {
+				others = append(others, proc)
+			}
+		case "wait":
+			if entered {
+				waits = append(waits, proc)
+			}
+		}
+	}
+	return stop, append(waits, others...)
+}
+
+// changeRunlevel stops what the new level does not run, runs its rc
+// scripts and then starts its entries.
+func changeRunlevel(level string) {
+	transitionMu.Lock()
+	defer transitionMu.Unlock()
+
+	Printf("Changing to runlevel %s\n", level)
+	stateMu.Lock()
+	from := currentRunlevel
+	currentRunlevel = level
+	stop, start := runlevelChanges(processes, from, level)
+	stateMu.Unlock()
+
+	stopProcesses(stop)
+	manageServices(level)
+	startProcesses(start)
+}
+
+func startProcesses(procs []*Process) {
+	for _, proc := range procs {
+		stateMu.Lock()
+		claimed := claimProcess(proc) // not already respawned
+		stateMu.Unlock()
+		if !claimed {
+			continue
+		}
+		cmd := startProcess(proc)
+		if cmd == nil {
+			continue
+		}
+		if proc.Action == "wait" {
+			waitProcess(proc, cmd, waitTimeout)
+		} else {
+			monitorProcess(proc, cmd)
+		}
+	}
+}
+
+// stopProcesses sends SIGTERM to the sessions of procs and SIGKILL to
+// whatever is left after STOP_TIMEOUT. The caller must already have made
+// shouldRespawn false for them.
+func stopProcesses(procs []*Process) {
+	var ids []string
+	var pids []int
+	stateMu.Lock()
+	for _, proc := range procs {
+		if proc.PID != 0 {
+			ids = append(ids, proc.ID)
+			pids = append(pids, proc.PID)
+		}
+	}
+	stateMu.Unlock()
+
+	for i, pid := range pids {
+		Printf("Stopping process %s\n", ids[i])
+		syscall.Kill(-pid, syscall.SIGTERM)
+	}
+
+	deadline := time.Now().Add(STOP_TIMEOUT)
+	for _, pid := range pids {
+		for syscall.Kill(-pid, 0) == nil {
+			if time.Now().After(deadline) {
+				syscall.Kill(-pid, syscall.SIGKILL)
+				break
+			}
+			time.Sleep(50 * time.Millisecond)
+		}
+	}
+}
+
+func inRunlevel(proc *Process, runlevel string) bool {
+	if disabled[proc.ID] || proc.Action == "off" {
+		return false
+	}
+	return proc.Runlevels == "" || strings.Contains(proc.Runlevels, runlevel)
+}
+
+// LSB (does not work)
+func executeLSB(script string, action string) error {
+	cmd := exec.Command("/bin/gsh", script, action)
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Setsid: true,
+	}
+	return cmd.Run()
+}
+
+func manageServices(runlevel string) {
+	Printf("Managing services for runlevel %s\n", runlevel)
+	dir := filepath.Join(RC_DIR, "rc"+runlevel+".d")
+	files, _ := ioutil.ReadDir(dir)
+
+	for _, f := range files {
+		script := filepath.Join(dir, f.Name())
+		if disabled[strings.TrimLeft(f.Name(), "SK0123456789")] {
+			Debugf("skipping disabled service %s", f.Name())
+			continue
+		}
+		switch {
+		case strings.HasPrefix(f.Name(), "S"):
+			executeLSB(script, "start")
+		case strings.HasPrefix(f.Name(), "K"):
+			executeLSB(script, "stop")
+		}
+	}
+}
+
+// claimProcess marks the entry as starting unless it is running or being
+// started already, so that nothing else starts it at the same time. The
+// caller holds stateMu.
+func claimProcess(proc *Process) bool {
+	if proc.PID != 0 || proc.Status == "starting" {
+		return false
+	}
+	proc.Status = "starting"
+	return true
+}
+
+// startProcess starts an entry claimed with claimProcess and records its
+// PID. Reading the service config, the fork and the logging all happen
+// without stateMu.
+func startProcess(proc *Process) *exec.Cmd {
+	stateMu.Lock()
+	id, command, cloneFlags := proc.ID, proc.Command, getCloneFlags(proc)
+	stateMu.Unlock()
+
+	PrintLn("Starting process", id)
+	cmd, err := serviceCommand(id, []string{"/bin/gsh", "-c", command})
+	if err == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{
+			Setsid:     true,
+			Cloneflags: cloneFlags,
+		}
+		applyCgroup(os.Getpid())
+		err = cmd.Start()
+	}
+	if err != nil {
+		stateMu.Lock()
+		proc.Status = "exited"
+		stateMu.Unlock()
+		logError(id, err)
+		return nil
+	}
+
+	stateMu.Lock()
+	proc.PID = cmd.Process.Pid
+	proc.Status = "running"
+	stateMu.Unlock()
+	return cmd
+}
+
+// Respawn
+
+// respawnPolicy throttles respawn entries the way sysvinit does. An entry
+// that dies within MinUptime waits Delay before it is started again, and
+// one started more than Limit times within Window is suspended for
+// Suspend.
+type respawnPolicy struct {
+	MinUptime time.Duration
+	Delay     time.Duration
+	Limit     int
+	Window    time.Duration
+	Suspend   time.Duration
+}
+
+var respawn = respawnPolicy{
+	MinUptime: time.Second,
+	Delay:     time.Second,
+	Limit:     10,
+	Window:    2 * time.Minute,
+	Suspend:   5 * time.Minute,
+}
+
+// next returns how long to wait before restarting an entry that exited at
+// exited, given its recent start times, oldest first. It also returns the
+// start times still inside the window, to be passed back next time.
+func (p respawnPolicy) next(starts []time.Time, exited time.Time) (time.Duration, []time.Time) {
+	var recent []time.Time
+	for _, t := range starts {
+		if exited.Sub(t) < p.Window {
+			recent = append(recent, t)
+		}
+	}
+
+	if len(recent) >= p.Limit {
+		return p.Suspend, nil
+	}
+	if len(recent) > 0 && exited.Sub(recent[len(recent)-1]) < p.MinUptime {
+		return p.Delay, recent
+	}
+	return 0, recent
+}
+
+// monitorProcess reaps the entry when it exits and restarts it while it is
+// a respawn entry of the current runlevel.
+func monitorProcess(proc *Process, cmd *exec.Cmd) {
+	go func() {
+		var starts []time.Time
+		for cmd != nil {
+			starts = append(starts, time.Now())
+			cmd.Wait()
+			stateMu.Lock()
+			proc.PID = 0
+			proc.Status = "exited"
+			again := shouldRespawn(proc)
+			stateMu.Unlock()
+			Debugf("process %s exited with status %d", proc.ID, cmd.ProcessState.ExitCode())
+			if !again {
+				return
+			}
+
+			var delay time.Duration
+			delay, starts = respawn.next(starts, time.Now())
+			if delay >= respawn.Suspend {
+				Printf("Process %s respawning too fast: disabled for %v\n", proc.ID, delay)
+			}
+			time.Sleep(delay)
+			stateMu.Lock()
+			claimed := shouldRespawn(proc) && claimProcess(proc)
+			stateMu.Unlock()
+			if !claimed {
+				return
+			}
+			if cmd = startProcess(proc); cmd == nil {
+				return
+			}
+
+			// A transition that ran while the entry was starting saw no PID
+			// to stop, so the entry is stopped here instead.
+			stateMu.Lock()
+			again = shouldRespawn(proc)
+			stateMu.Unlock()
+			if !again {
+				go stopProcesses([]*Process{proc})
+			}
+		}
+	}()
+}
+
+// shouldRespawn is false once the entry has left the runlevel, and for
+// everything while init is shutting down or in recovery mode. The caller
+// holds stateMu.
+func shouldRespawn(proc *Process) bool {
+	if recoveryMode.Load() || currentRunlevel == "0" {
+		return false
+	}
+	return proc.Action == "respawn" && inRunlevel(proc, currentRunlevel)
+}
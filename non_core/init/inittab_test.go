@@ -0,0 +1,163 @@
+package init
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseInittab(t *testing.T) {
+	tab := `# comment
+
+si::sysinit:/bin/mount -a
+sl:2345:respawn:/bin/syslogd -n
+tt:2:once:/bin/echo a:b:c
+bad line
+xx:2:frobnicate:/bin/true
+:2:once:/bin/true
+sl:3:respawn:/bin/syslogd
+nc:2:wait:
+`
+	entries, errs := parseInittab(strings.NewReader(tab))
+
+	want := []Process{
+		{ID: "si", Runlevels: "", Action: "sysinit", Command: "/bin/mount -a"},
+		{ID: "sl", Runlevels: "2345", Action: "respawn", Command: "/bin/syslogd -n"},
+		{ID: "tt", Runlevels: "2", Action: "once", Command: "/bin/echo a:b:c"},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("got %d entries, want %d", len(entries), len(want))
+	}
+	for i, proc := range entries {
+		if *proc != want[i] {
+			t.Errorf("entry %d = %+v, want %+v", i, *proc, want[i])
+		}
+	}
+
+	wantErrs := []string{"line 6:", "line 7:", "line 8:", "line 9: duplicate", "line 10:"}
+	if len(errs) != len(wantErrs) {
+		t.Fatalf("got errors %v, want %d", errs, len(wantErrs))
+	}
+	for i, err := range errs {
+		if !strings.HasPrefix(err.Error(), wantErrs[i]) {
+			t.Errorf("error %d = %q, want prefix %q", i, err, wantErrs[i])
+		}
+	}
+}
+
+func TestMergeInittabKeepsRunning(t *testing.T) {
+	running := &Process{ID: "sl", Runlevels: "2", Action: "respawn", Command: "old", PID: 42}
+	gone := &Process{ID: "gone", Action: "respawn", PID: 43}
+
+	merged := mergeInittab([]*Process{running, gone}, []*Process{
+		{ID: "sl", Runlevels: "23", Action: "respawn", Command: "new"},
+		{ID: "nw", Runlevels: "2", Action: "once", Command: "x"},
+	})
+
+	if len(merged) != 2 {
+		t.Fatalf("got %d entries, want 2", len(merged))
+	}
+	if merged[0] != running {
+		t.Errorf("running entry was replaced instead of updated")
+	}
+	if running.PID != 42 || running.Command != "new" || running.Runlevels != "23" {
+		t.Errorf("running entry = %+v", *running)
+	}
+	if merged[1].ID != "nw" {
+		t.Errorf("second entry = %+v", *merged[1])
+	}
+}
+
+func ids(procs []*Process) string {
+	var s []string
+	for _, proc := range procs {
+		s = append(s, proc.ID)
+	}
+	return strings.Join(s, ",")
+}
+
+func TestRunlevelChanges(t *testing.T) {
+	newProcs := func() []*Process {
+		return []*Process{
+			{ID: "si", Action: "sysinit"},
+			{ID: "g1", Runlevels: "2345", Action: "respawn"},
+			{ID: "x2", Runlevels: "2", Action: "respawn"},
+			{ID: "x3", Runlevels: "3", Action: "respawn"},
+			{ID: "o2", Runlevels: "23", Action: "once"},
+			{ID: "w3", Runlevels: "3", Action: "wait"},
+			{ID: "of", Runlevels: "3", Action: "off"},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		running  string
+		disabled string
+		from, to string
+		stop     string
+		start    string
+	}{
+		{name: "boot", from: BOOT_RUNLEVEL, to: "2", start: "g1,x2,o2"},
+		{name: "2 to 3", running: "g1,x2", from: "2", to: "3", stop: "x2", start: "w3,x3"},
+		{name: "respawn restarted", running: "x2", from: "2", to: "2", start: "g1"},
+		{name: "to single user", running: "g1,x2,o2", from: "2", to: "1", stop: "g1,x2,o2"},
+		{name: "disabled", disabled: "x3", running: "g1", from: "2", to: "3", start: "w3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			procs := newProcs()
+			for _, proc := range procs {
+				if strings.Contains(","+tt.running+",", ","+proc.ID+",") {
+					proc.PID = 100
+				}
+			}
+			disabled = map[string]bool{tt.disabled: tt.disabled != ""}
+			defer func() { disabled = make(map[string]bool) }()
+
+			stop, start := runlevelChanges(procs, tt.from, tt.to)
+			if got := ids(stop); got != tt.stop {
+				t.Errorf("stop = %q, want %q", got, tt.stop)
+			}
+			if got := ids(start); got != tt.start {
+				t.Errorf("start = %q, want %q", got, tt.start)
+			}
+		})
+	}
+}
+
+func TestRespawnPolicy(t *testing.T) {
+	p := respawnPolicy{
+		MinUptime: time.Second,
+		Delay:     2 * time.Second,
+		Limit:     3,
+		Window:    time.Minute,
+		Suspend:   5 * time.Minute,
+	}
+	t0 := time.Unix(1000, 0)
+
+	// Ran for a while: restart at once.
+	delay, starts := p.next([]time.Time{t0}, t0.Add(10*time.Second))
+	if delay != 0 || len(starts) != 1 {
+		t.Errorf("long run: delay %v, %d starts", delay, len(starts))
+	}
+
+	// Died right away: back off.
+	delay, _ = p.next([]time.Time{t0}, t0.Add(100*time.Millisecond))
+	if delay != p.Delay {
+		t.Errorf("fast exit: delay %v, want %v", delay, p.Delay)
+	}
+
+	// Too many starts within the window: suspend and forget the history.
+	recent := []time.Time{t0, t0.Add(10 * time.Second), t0.Add(20 * time.Second)}
+	delay, starts = p.next(recent, t0.Add(30*time.Second))
+	if delay != p.Suspend || starts != nil {
+		t.Errorf("too fast: delay %v, starts %v", delay, starts)
+	}
+
+	// Old starts fall out of the window.
+	delay, starts = p.next(recent, t0.Add(65*time.Second))
+	if delay != 0 || len(starts) != 2 {
+		t.Errorf("window: delay %v, %d starts", delay, len(starts))
+	}
+}
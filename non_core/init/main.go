@@ -0,0 +1,423 @@
+package init
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// Config
+const (
+	INITTAB_PATH  = "/etc/inittab"
+	SYSLOG_PATH   = "/var/log/syslog"
+	SYSLOG_SOCKET = "/dev/log"
+	RC_DIR        = "/etc/rc.d"
+	INITCTL_FIFO  = "/run/initctl"
+	CGROUP_ROOT   = "/sys/fs/cgroup"
+	RECOVERY_MODE = "recovery"
+	FSTAB_PATH    = "/etc/fstab"
+	HOSTNAME_PATH = "/etc/hostname"
+	SOCKETS_PATH  = "/etc/sockets"
+	SERVICE_DIR   = "/etc/init"
+	SERVICE_EXEC  = "--exec-service"
+	PF_KTHREAD    = 0x00200000 // in /proc/<pid>/stat flags
+)
+
+// Structs
+type Process struct {
+	ID        string
+	Runlevels string
+	Action    string
+	Command   string
+	PID       int
+	Status    string
+	Cgroup    string
+	Namespace string
+}
+
+type Runlevel struct {
+	Level     string
+	Services []string
+}
+
+var (
+	currentRunlevel  = "2"
+	processes       []*Process
+	namespaces      = make(map[string]bool)
+	cgroups         = make(map[string]string)
+	recoveryMode    atomic.Bool
+	syslogConn      net.Conn
+	logMu           sync.Mutex
+
+	// Set from the kernel command line
+	quiet       = false
+	debugMode   = false
+	shellPath   = "/bin/gsh"
+	waitTimeout = 30 * time.Second
+	disabled    = make(map[string]bool)
+)
+
+func Main() {
+	if len(os.Args) > 1 && os.Args[1] == SERVICE_EXEC {
+		serviceExec(os.Args[2:])
+	}
+	if os.Getpid() != 1 {
+		PrintLn("Must run as PID 1")
+		os.Exit(1)
+	}
+	loadCmdline()
+	if !quiet {
+		fmt.Printf("\033[2J\033[1;1H")
+	}
+	PrintLn("Starting initialization...")
+
+	initSystem()
+	startBootSequence()
+	if !supervising() {
+		go consoleShell()
+	}
+	superviseLoop()
+}
+
+// supervising reports whether any respawn entry (normally the gettys)
+// is running, in which case the terminals belong to them and init must
+// not start its own shell on the console.
+func supervising() bool {
+	stateMu.Lock()
+	defer stateMu.Unlock()
+	for _, proc := range processes {
+		if proc.Action == "respawn" && proc.PID != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// consoleShell keeps a shell on the console when nothing else owns it;
+// init itself must never exit.
+func consoleShell() {
+	for {
+		startEmergencyShell()
+		PrintLn("Emergency shell exited, restarting it")
+		time.Sleep(time.Second)
+	}
+}
+
+// superviseLoop is init's main loop once booted. Each pass takes the state
+// lock, so a deadlock anywhere else in init stalls it; it then records a
+// heartbeat for the hang detector and feeds the hardware watchdog.
+func superviseLoop() {
+	startWatchdog()
+	go hangDetector()
+
+	ticker := time.NewTicker(superviseInterval())
+	defer ticker.Stop()
+	for {
+		stateMu.Lock()
+		heartbeat.Store(time.Now().UnixNano())
+		stateMu.Unlock()
+
+		keepaliveWatchdog()
+		<-ticker.C
+	}
+}
+
+// System init
+func initSystem() {
+	setupFilesystems()
+	if root, ok := kernelParam("root"); ok && inInitramfs() {
+		switchRoot(root)
+	}
+	checkRecoveryMode()
+	loadInittab()
+	initCgroups()
+	createNamespaces()
+	setupTTY()
+	setupSignals()
+	startInitctlServer()
+	startSocketActivation()
+}
+
+func startBootSequence() {
+	PrintLn("Starting system boot sequence")
+	runInittab("sysinit", "")
+	manageServices(currentRunlevel)
+	stateMu.Lock()
+	_, start := runlevelChanges(processes, BOOT_RUNLEVEL, currentRunlevel)
+	stateMu.Unlock()
+	startProcesses(start)
+}
+
+func setupTTY() {
+	PrintLn("Initializing TTY")
+	syscall.Setsid()
+	syscall.Syscall(syscall.SYS_IOCTL, uintptr(0), uintptr(syscall.TIOCSCTTY), 1)
+
+	os.Setenv("PATH", os.Getenv("PATH") + ":/bin:/sbin")
+
+	f, _ := os.OpenFile("/proc/sys/kernel/printk", os.O_WRONLY, 0) // only critical
+	defer f.Close()
+	f.WriteString("2 0 0 0")
+}
+
+
+func checkRecoveryMode() {
+	PrintLn("Checking for recovery mode")
+	if kernelFlag(RECOVERY_MODE) {
+		PrintLn("Entering recovery mode...")
+		recoveryMode.Store(true)
+		currentRunlevel = "1"
+		enableSingleUserMode()
+	}
+}
+
+func enableSingleUserMode() {
+	PrintLn("Entering single user mode")
+	startEmergencyShell()
+}
+
+func initCgroups() {
+	PrintLn("Initializing cgroups")
+	for _, subsys := range []string{"cpu", "memory", "devices"} {
+		path := filepath.Join(CGROUP_ROOT, subsys, "init.scope")
+		os.MkdirAll(path, 0755)
+		cgroups[subsys] = path
+	}
+}
+
+func applyCgroup(pid int) {
+	for _, path := range cgroups {
+		tasks := filepath.Join(path, "tasks")
+		ioutil.WriteFile(tasks, []byte(fmt.Sprint(pid)), 0644)
+	}
+}
+
+func createNamespaces() {
+	PrintLn("Creating namespaces")
+	namespaces["pid"] = true
+	namespaces["mount"] = true
+}
+
+func setupSignals() {
+	PrintLn("Setting up signals")
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh,
+		syscall.SIGHUP,
+		syscall.SIGINT,
+		syscall.SIGTERM,
+		syscall.SIGCHLD,
+		syscall.SIGUSR1,
+	)
+
+	go handleSignals(sigCh)
+}
+
+func handleSignals(ch <-chan os.Signal) {
+	for sig := range ch {
+		switch sig {
+		case syscall.SIGHUP:
+			reloadConfig()
+		case syscall.SIGUSR1:
+			enterRecoveryMode()
+		}
+	}
+}
+
+// Helpers
+func getCloneFlags(proc *Process) uintptr {
+	if proc.Namespace == "" {
+		return 0
+	}
+	flags := syscall.CLONE_NEWPID
+	if namespaces["mount"] {
+		flags |= syscall.CLONE_NEWNS
+	}
+	if proc.Namespace == "full" {
+		flags |= syscall.CLONE_NEWUTS | syscall.CLONE_NEWIPC
+	}
+	return uintptr(flags)
+}
+
+func logError(id string, err error) {
+	Printf("Error in process %s: %v\n", id, err)
+}
+
+func enterRecoveryMode() {
+	PrintLn("Entering recovery mode")
+	recoveryMode.Store(true)
+	killAllProcesses()
+	startEmergencyShell()
+}
+
+// killAllProcesses sends SIGTERM to every process and SIGKILL to whatever
+// is left after STOP_TIMEOUT.
+func killAllProcesses() {
+	PrintLn("Terminating all processes")
+	syscall.Kill(-1, syscall.SIGTERM)
+
+	deadline := time.Now().Add(STOP_TIMEOUT)
+	for processesLeft() && time.Now().Before(deadline) {
+		time.Sleep(50 * time.Millisecond)
+	}
+	syscall.Kill(-1, syscall.SIGKILL)
+}
+
+// processesLeft reports whether anything but init is still running.
+// kill(-1, 0) cannot tell: it counts kernel threads, and the zombies of
+// orphans that init never waits for.
+func processesLeft() bool {
+	entries, _ := os.ReadDir("/proc")
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil || pid == os.Getpid() {
+			continue
+		}
+		data, err := os.ReadFile("/proc/" + entry.Name() + "/stat")
+		if err != nil {
+			continue
+		}
+		// The fields after the command name start with the state; the
+		// flags are the seventh.
+		fields := strings.Fields(string(data[strings.LastIndexByte(string(data), ')')+1:]))
+		if len(fields) < 7 || fields[0] == "Z" {
+			continue
+		}
+		if flags, err := strconv.ParseUint(fields[6], 10, 32); err == nil && flags&PF_KTHREAD != 0 {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+func startEmergencyShell() {
+	PrintLn("Starting emergency shell")
+	cmd := exec.Command(shellPath)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Run()
+}
+
+// reloadConfig rereads inittab on SIGHUP. Entries that were removed are
+// stopped and new respawn entries of the current runlevel are started.
+func reloadConfig() {
+	transitionMu.Lock()
+	defer transitionMu.Unlock()
+
+	PrintLn("Reloading configuration")
+	entries, ok := readInittab()
+	stateMu.Lock()
+	old := processes
+	if ok {
+		processes = mergeInittab(old, entries)
+	}
+
+	kept := make(map[*Process]bool)
+	for _, proc := range processes {
+		kept[proc] = true
+	}
+	var removed []*Process
+	for _, proc := range old {
+		if !kept[proc] {
+			proc.Action = "off"
+			removed = append(removed, proc)
+		}
+	}
+	stateMu.Unlock()
+	stopProcesses(removed)
+
+	stateMu.Lock()
+	_, start := runlevelChanges(processes, currentRunlevel, currentRunlevel)
+	stateMu.Unlock()
+	startProcesses(start)
+}
+
+// shutdown stops every entry, runs the rc scripts of runlevel 0 and the
+// shutdown entries, then powers off.
+func shutdown() {
+	transitionMu.Lock()
+	defer transitionMu.Unlock()
+
+	PrintLn("Shutting down")
+	stateMu.Lock()
+	currentRunlevel = "0"
+	var running []*Process
+	for _, proc := range processes {
+		if proc.PID != 0 {
+			running = append(running, proc)
+		}
+	}
+	stateMu.Unlock()
+	stopProcesses(running)
+	manageServices("0")
+	runInittab("shutdown", "")
+
+	syscall.Sync()
+	stopWatchdog()
+	syscall.Reboot(syscall.LINUX_REBOOT_CMD_POWER_OFF)
+}
+
+// debug hands the message to syslogd over /dev/log as daemon.info and
+// falls back to appending to SYSLOG_PATH while syslogd is not running.
+func debug(format string, a ...interface{}) {
+	msg := strings.TrimSuffix(fmt.Sprintf(format, a...), "\n")
+
+	logMu.Lock()
+	defer logMu.Unlock()
+
+	if syslogConn == nil {
+		syslogConn, _ = net.Dial("unixgram", SYSLOG_SOCKET)
+	}
+	if syslogConn != nil {
+		line := fmt.Sprintf("<%d>%s init: %s", 3<<3|6, time.Now().Format(time.Stamp), msg)
+		if _, err := syslogConn.Write([]byte(line)); err == nil {
+			return
+		}
+		syslogConn.Close()
+		syslogConn = nil
+	}
+
+	f, err := os.OpenFile(SYSLOG_PATH, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	f.WriteString(fmt.Sprintf("%s %s\n", time.Now().Format(time.RFC3339), msg))
+}
+
+// Printf and PrintLn log the message and, unless "quiet" was given,
+// echo it on the console.
+func Printf(format string, a ...interface{}) (n int, err error) {
+	debug(format, a...)
+	if quiet {
+		return 0, nil
+	}
+	return fmt.Printf("[\033[32m*\033[0m] " + format, a...)
+}
+
+func PrintLn(a ...interface{}) (n int, err error) {
+	debug("%s", fmt.Sprintln(a...))
+	if quiet {
+		return 0, nil
+	}
+	return fmt.Println(append([]interface{}{"[\033[32m*\033[0m] "}, a...)...)
+}
+
+// Debugf always logs, but only shows on the console with init.debug.
+func Debugf(format string, a ...interface{}) {
+	debug(format, a...)
+	if debugMode {
+		fmt.Printf("[\033[33mdebug\033[0m] "+format+"\n", a...)
+	}
+}
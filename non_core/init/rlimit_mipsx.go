@@ -0,0 +1,7 @@
+//go:build mips || mipsle || mips64 || mips64le
+
+package init
+
+// RLIMIT_NPROC is missing from the syscall package; MIPS numbers it apart
+// from the other architectures.
+const RLIMIT_NPROC = 8
@@ -0,0 +1,6 @@
+//go:build !mips && !mipsle && !mips64 && !mips64le
+
+package init
+
+// RLIMIT_NPROC is missing from the syscall package.
+const RLIMIT_NPROC = 6
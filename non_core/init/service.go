@@ -0,0 +1,370 @@
+package init
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/ilnarildarovuch/CoreUtils-On-GO/internal/users"
+)
+
+// Service execution environment
+
+// ServiceConfig is read from SERVICE_DIR/<id>.conf, one "key=value" per
+// line, and applies to the inittab entry or socket with that id:
+//
+//	user=nobody          run as this user (name or uid)
+//	group=nogroup        primary group, default the user's
+//	groups=tty,audio     supplementary groups, default from /etc/group
+//	umask=022
+//	chroot=/srv/jail
+//	workdir=/var/lib/foo default the user's home directory
+//	nofile=1024          rlimits; a number or "unlimited"
+//	nproc=64
+//	core=0
+//	nice=5
+//	ionice=best-effort:4 realtime|best-effort[:0-7] or idle
+//	env=NAME=value       repeatable
+//	capabilities=net_bind_service,sys_time   bounding set to keep
+type ServiceConfig struct {
+	User         string
+	Group        string
+	Groups       []string
+	Umask        int
+	Chroot       string
+	WorkDir      string
+	Rlimits      map[int]uint64
+	Nice         int
+	HasNice      bool
+	IOPrio       int
+	HasIOPrio    bool
+	Env          []string
+	Capabilities []string
+	HasCaps      bool
+}
+
+var rlimitNames = map[string]int{
+	"nofile": syscall.RLIMIT_NOFILE,
+	"nproc":  RLIMIT_NPROC,
+	"core":   syscall.RLIMIT_CORE,
+}
+
+var ioClasses = map[string]int{"realtime": 1, "best-effort": 2, "idle": 3}
+
+var capNames = []string{
+	"chown", "dac_override", "dac_read_search", "fowner", "fsetid", "kill",
+	"setgid", "setuid", "setpcap", "linux_immutable", "net_bind_service",
+	"net_broadcast", "net_admin", "net_raw", "ipc_lock", "ipc_owner",
+	"sys_module", "sys_rawio", "sys_chroot", "sys_ptrace", "sys_pacct",
+	"sys_admin", "sys_boot", "sys_nice", "sys_resource", "sys_time",
+	"sys_tty_config", "mknod", "lease", "audit_write", "audit_control",
+	"setfcap", "mac_override", "mac_admin", "syslog", "wake_alarm",
+	"block_suspend", "audit_read", "perfmon", "bpf", "checkpoint_restore",
+}
+
+const (
+	PR_CAPBSET_DROP    = 24
+	IOPRIO_WHO_PROCESS = 1
+	IOPRIO_CLASS_SHIFT = 13
+)
+
+func loadServiceConfig(id string) (*ServiceConfig, error) {
+	cfg := &ServiceConfig{Umask: -1, Rlimits: make(map[int]uint64)}
+
+	data, err := ioutil.ReadFile(filepath.Join(SERVICE_DIR, id+".conf"))
+	if os.IsNotExist(err) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for n, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("%s.conf:%d: expected key=value", id, n+1)
+		}
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if err := cfg.set(key, value); err != nil {
+			return nil, fmt.Errorf("%s.conf:%d: %s: %v", id, n+1, key, err)
+		}
+	}
+	return cfg, nil
+}
+
+func (cfg *ServiceConfig) set(key, value string) error {
+	switch key {
+	case "user":
+		cfg.User = value
+	case "group":
+		cfg.Group = value
+	case "groups":
+		cfg.Groups = strings.Split(value, ",")
+	case "umask":
+		mask, err := strconv.ParseUint(value, 8, 32)
+		if err != nil || mask > 0777 {
+			return fmt.Errorf("invalid umask %s", value)
+		}
+		cfg.Umask = int(mask)
+	case "chroot":
+		cfg.Chroot = value
+	case "workdir":
+		cfg.WorkDir = value
+	case "nofile", "nproc", "core":
+		limit := ^uint64(0) // RLIM_INFINITY
+		if value != "unlimited" {
+			n, err := strconv.ParseUint(value, 10, 64)
+			if err != nil {
+				return err
+			}
+			limit = n
+		}
+		cfg.Rlimits[rlimitNames[key]] = limit
+	case "nice":
+		n, err := strconv.Atoi(value)
+		if err != nil || n < -20 || n > 19 {
+			return fmt.Errorf("invalid nice value %s", value)
+		}
+		cfg.Nice, cfg.HasNice = n, true
+	case "ionice":
+		name, levelStr, hasLevel := strings.Cut(value, ":")
+		class, ok := ioClasses[name]
+		if !ok {
+			return fmt.Errorf("unknown class %s", name)
+		}
+		level := 4
+		if hasLevel {
+			n, err := strconv.Atoi(levelStr)
+			if err != nil || n < 0 || n > 7 {
+				return fmt.Errorf("invalid level %s", levelStr)
+			}
+			level = n
+		}
+		if class == 3 {
+			level = 0
+		}
+		cfg.IOPrio, cfg.HasIOPrio = class<<IOPRIO_CLASS_SHIFT|level, true
+	case "env":
+		if !strings.Contains(value, "=") {
+			return fmt.Errorf("expected NAME=value")
+		}
+		cfg.Env = append(cfg.Env, value)
+	case "capabilities":
+		cfg.HasCaps = true
+		for _, name := range strings.Split(value, ",") {
+			name = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(name)), "cap_")
+			if name == "" {
+				continue
+			}
+			if capIndex(name) < 0 {
+				return fmt.Errorf("unknown capability %s", name)
+			}
+			cfg.Capabilities = append(cfg.Capabilities, name)
+		}
+	default:
+		return fmt.Errorf("unknown key")
+	}
+	return nil
+}
+
+func capIndex(name string) int {
+	for i, n := range capNames {
+		if n == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// serviceCommand builds the command for service id: argv runs through the
+// SERVICE_EXEC helper, which applies the service config between fork and
+// exec. The environment is built from scratch rather than inherited.
+func serviceCommand(id string, argv []string) (*exec.Cmd, error) {
+	cfg, err := loadServiceConfig(id)
+	if err != nil {
+		return nil, err
+	}
+
+	user := cfg.User
+	if user == "" {
+		user = "root"
+	}
+	home, shell := "/", shellPath
+	if acct, err := users.LookupAccount(user); err == nil {
+		user, home, shell = acct.Name, acct.Home, acct.Shell
+	}
+
+	path := "/bin:/sbin:/usr/bin:/usr/sbin"
+	env := []string{"PATH=" + path, "HOME=" + home, "USER=" + user, "LOGNAME=" + user, "SHELL=" + shell}
+	if term := os.Getenv("TERM"); term != "" {
+		env = append(env, "TERM="+term)
+	}
+	env = append(env, cfg.Env...)
+
+	cmd := exec.Command("/proc/self/exe", append([]string{SERVICE_EXEC, id}, argv...)...)
+	// /proc/self/exe may be the multi-call binary, which picks the applet
+	// by argv[0].
+	cmd.Args[0] = "init"
+	cmd.Env = env
+	return cmd, nil
+}
+
+// serviceExec is the SERVICE_EXEC helper, running in the freshly forked
+// child: it applies the service config of args[0] and execs args[1:].
+func serviceExec(args []string) {
+	// nice, ionice and the capability bounding set only change the calling
+	// thread, so everything up to the exec must happen on the same one.
+	runtime.LockOSThread()
+
+	if len(args) < 2 {
+		os.Exit(1)
+	}
+	id, argv := args[0], args[1:]
+
+	fail := func(format string, a ...interface{}) {
+		fmt.Fprintf(os.Stderr, "init: %s: %s\n", id, fmt.Sprintf(format, a...))
+		os.Exit(126)
+	}
+
+	cfg, err := loadServiceConfig(id)
+	if err != nil {
+		fail("%v", err)
+	}
+	if err := applyServiceConfig(cfg); err != nil {
+		fail("%v", err)
+	}
+
+	if os.Getenv("LISTEN_FDS") != "" {
+		os.Setenv("LISTEN_PID", strconv.Itoa(os.Getpid()))
+	}
+
+	path, err := exec.LookPath(argv[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "init: %s: %v\n", argv[0], err)
+		os.Exit(127)
+	}
+	err = syscall.Exec(path, argv, os.Environ())
+	fail("%s: %v", path, err)
+}
+
+// applyServiceConfig changes the current process. The order matters:
+// accounts are resolved before chroot hides /etc, and everything needing
+// privileges happens before the uid switch.
+func applyServiceConfig(cfg *ServiceConfig) error {
+	uid, gid := 0, 0
+	home, name := "/", ""
+	var groups []int
+	if cfg.User != "" {
+		acct, err := users.LookupAccount(cfg.User)
+		if err != nil {
+			return err
+		}
+		uid, gid, home = acct.UID, acct.GID, acct.Home
+		name = acct.Name
+	}
+	if cfg.Group != "" {
+		g, _, err := users.LookupGroup(cfg.Group)
+		if err != nil {
+			return err
+		}
+		gid = g
+	}
+	if cfg.Groups != nil {
+		for _, group := range cfg.Groups {
+			g, _, err := users.LookupGroup(group)
+			if err != nil {
+				return err
+			}
+			groups = append(groups, g)
+		}
+	} else if cfg.User != "" {
+		groups = users.GroupIDs(name, gid)
+	}
+
+	if cfg.Chroot != "" {
+		if err := syscall.Chroot(cfg.Chroot); err != nil {
+			return fmt.Errorf("chroot %s: %v", cfg.Chroot, err)
+		}
+		home = "/"
+	}
+	if cfg.WorkDir != "" {
+		if err := os.Chdir(cfg.WorkDir); err != nil {
+			return fmt.Errorf("chdir %s: %v", cfg.WorkDir, err)
+		}
+	} else if os.Chdir(home) != nil {
+		// System accounts often have a home that doesn't exist
+		os.Chdir("/")
+	}
+
+	for resource, limit := range cfg.Rlimits {
+		rlim := syscall.Rlimit{Cur: limit, Max: limit}
+		if err := syscall.Setrlimit(resource, &rlim); err != nil {
+			return fmt.Errorf("setrlimit %d: %v", resource, err)
+		}
+	}
+	if cfg.HasNice {
+		if err := syscall.Setpriority(syscall.PRIO_PROCESS, 0, cfg.Nice); err != nil {
+			return fmt.Errorf("nice: %v", err)
+		}
+	}
+	if cfg.HasIOPrio {
+		if _, _, errno := syscall.Syscall(syscall.SYS_IOPRIO_SET, IOPRIO_WHO_PROCESS, 0, uintptr(cfg.IOPrio)); errno != 0 {
+			return fmt.Errorf("ionice: %v", errno)
+		}
+	}
+	if cfg.HasCaps {
+		if err := dropCapabilities(cfg.Capabilities); err != nil {
+			return err
+		}
+	}
+
+	if cfg.User != "" || cfg.Group != "" || cfg.Groups != nil {
+		if err := syscall.Setgroups(groups); err != nil {
+			return fmt.Errorf("setgroups: %v", err)
+		}
+		if err := syscall.Setgid(gid); err != nil {
+			return fmt.Errorf("setgid: %v", err)
+		}
+		if err := syscall.Setuid(uid); err != nil {
+			return fmt.Errorf("setuid: %v", err)
+		}
+	}
+	if cfg.Umask >= 0 {
+		syscall.Umask(cfg.Umask)
+	}
+	return nil
+}
+
+// dropCapabilities removes every capability not in keep from the bounding set.
+func dropCapabilities(keep []string) error {
+	last := len(capNames) - 1
+	if data, err := ioutil.ReadFile("/proc/sys/kernel/cap_last_cap"); err == nil {
+		if n, err := strconv.Atoi(strings.TrimSpace(string(data))); err == nil {
+			last = n
+		}
+	}
+
+	kept := make(map[int]bool)
+	for _, name := range keep {
+		kept[capIndex(name)] = true
+	}
+	for c := 0; c <= last; c++ {
+		if kept[c] {
+			continue
+		}
+		if _, _, errno := syscall.Syscall(syscall.SYS_PRCTL, PR_CAPBSET_DROP, uintptr(c), 0); errno != 0 && errno != syscall.EINVAL {
+			return fmt.Errorf("drop capability %d: %v", c, errno)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,252 @@
+package init
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+// Socket activation
+
+// Socket is one /etc/sockets entry. init owns the listening socket and
+// starts Command on demand, handing it over as fd 3 with the
+// LISTEN_FDS/LISTEN_PID environment that sd_listen_fds(3) expects.
+//
+// In "accept" mode init accepts each connection and runs one instance per
+// connection with the connection as stdin, stdout and fd 3, like inetd.
+// In "nowait" mode the first connection starts a single instance that is
+// given the listening socket itself; init watches again once it exits.
+type Socket struct {
+	Name    string
+	Network string
+	Address string
+	Mode    string
+	Command []string
+	file    *os.File
+}
+
+var sockets []*Socket
+
+func startSocketActivation() {
+	if err := loadSockets(); err != nil {
+		if !os.IsNotExist(err) {
+			Printf("Error loading %s: %v\n", SOCKETS_PATH, err)
+		}
+		return
+	}
+
+	for _, sock := range sockets {
+		if disabled[sock.Name] {
+			continue
+		}
+		if err := sock.listen(); err != nil {
+			Printf("Error listening on %s %s for %s: %v\n", sock.Network, sock.Address, sock.Name, err)
+			continue
+		}
+		Printf("Listening on %s %s for %s\n", sock.Network, sock.Address, sock.Name)
+		if sock.Mode == "accept" {
+			go sock.acceptLoop()
+		} else {
+			go sock.nowaitLoop()
+		}
+	}
+}
+
+// loadSockets reads lines of the form "NAME NETWORK ADDRESS MODE COMMAND...".
+func loadSockets() error {
+	data, err := ioutil.ReadFile(SOCKETS_PATH)
+	if err != nil {
+		return err
+	}
+
+	sockets = nil
+	for n, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) < 5 {
+			Printf("%s:%d: expected NAME NETWORK ADDRESS MODE COMMAND\n", SOCKETS_PATH, n+1)
+			continue
+		}
+
+		sock := &Socket{Name: fields[0], Network: fields[1], Address: fields[2], Mode: fields[3], Command: fields[4:]}
+		switch {
+		case sock.Mode != "accept" && sock.Mode != "nowait":
+			Printf("%s:%d: unknown mode %s\n", SOCKETS_PATH, n+1, sock.Mode)
+			continue
+		case sock.Mode == "accept" && !sock.isStream():
+			Printf("%s:%d: %s sockets cannot use accept mode\n", SOCKETS_PATH, n+1, sock.Network)
+			continue
+		}
+		sockets = append(sockets, sock)
+	}
+	return nil
+}
+
+func (s *Socket) isStream() bool {
+	switch s.Network {
+	case "tcp", "tcp4", "tcp6", "unix":
+		return true
+	}
+	return false
+}
+
+// listen creates the socket and keeps only a dup of its descriptor, in
+// blocking mode, which is what gets passed to services.
+func (s *Socket) listen() error {
+	if s.Network == "unix" || s.Network == "unixgram" {
+		os.Remove(s.Address)
+		os.MkdirAll(filepath.Dir(s.Address), 0755)
+	}
+
+	var err error
+	switch s.Network {
+	case "tcp", "tcp4", "tcp6":
+		var ln net.Listener
+		if ln, err = net.Listen(s.Network, s.Address); err == nil {
+			s.file, err = ln.(*net.TCPListener).File()
+			ln.Close()
+		}
+	case "unix":
+		var ln *net.UnixListener
+		if ln, err = net.ListenUnix("unix", &net.UnixAddr{Name: s.Address, Net: "unix"}); err == nil {
+			ln.SetUnlinkOnClose(false)
+			s.file, err = ln.File()
+			ln.Close()
+		}
+	case "udp", "udp4", "udp6":
+		var pc net.PacketConn
+		if pc, err = net.ListenPacket(s.Network, s.Address); err == nil {
+			s.file, err = pc.(*net.UDPConn).File()
+			pc.Close()
+		}
+	case "unixgram":
+		var pc *net.UnixConn
+		if pc, err = net.ListenUnixgram("unixgram", &net.UnixAddr{Name: s.Address, Net: "unixgram"}); err == nil {
+			s.file, err = pc.File()
+			pc.Close()
+		}
+	default:
+		err = fmt.Errorf("unknown network %s", s.Network)
+	}
+	if err == nil {
+		s.file.Fd() // switches the descriptor to blocking mode
+	}
+	return err
+}
+
+func (s *Socket) acceptLoop() {
+	for {
+		fd, _, err := syscall.Accept4(int(s.file.Fd()), syscall.SOCK_CLOEXEC)
+		if err != nil {
+			if err == syscall.EINTR || err == syscall.ECONNABORTED {
+				continue
+			}
+			Printf("Error accepting on %s: %v\n", s.Name, err)
+			time.Sleep(time.Second)
+			continue
+		}
+
+		conn := os.NewFile(uintptr(fd), s.Name+"-conn")
+		cmd, err := s.command(conn)
+		if err == nil {
+			cmd.Stdin = conn
+			cmd.Stdout = conn
+			err = cmd.Start()
+		}
+		if err != nil {
+			logError(s.Name, err)
+			conn.Close()
+			continue
+		}
+		conn.Close()
+		Debugf("socket %s: started instance %d", s.Name, cmd.Process.Pid)
+		go cmd.Wait()
+	}
+}
+
+func (s *Socket) nowaitLoop() {
+	fastExits := 0
+	for {
+		if err := waitReadable(int(s.file.Fd())); err != nil {
+			Printf("Error watching %s: %v\n", s.Name, err)
+			return
+		}
+
+		cmd, err := s.command(s.file)
+		if err == nil {
+			err = cmd.Start()
+		}
+		if err != nil {
+			logError(s.Name, err)
+			time.Sleep(time.Second)
+			continue
+		}
+		Printf("Activated %s (pid %d)\n", s.Name, cmd.Process.Pid)
+		started := time.Now()
+		cmd.Wait()
+		Debugf("socket %s: service exited with status %d", s.Name, cmd.ProcessState.ExitCode())
+
+		// A service that dies without draining its socket would be
+		// reactivated forever; back off, then give up on it.
+		if time.Since(started) >= time.Second {
+			fastExits = 0
+			continue
+		}
+		if fastExits++; fastExits >= 5 {
+			Printf("%s keeps exiting without serving its socket, deactivating it\n", s.Name)
+			return
+		}
+		time.Sleep(time.Second)
+	}
+}
+
+// command runs the service through the SERVICE_EXEC helper, which also
+// fills in LISTEN_PID: it must hold the service's PID, only known after fork.
+func (s *Socket) command(fd3 *os.File) (*exec.Cmd, error) {
+	cmd, err := serviceCommand(s.Name, s.Command)
+	if err != nil {
+		return nil, err
+	}
+	cmd.Env = append(cmd.Env, "LISTEN_FDS=1", "LISTEN_FDNAMES="+s.Name)
+	cmd.ExtraFiles = []*os.File{fd3}
+	cmd.Stderr = os.Stderr
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	return cmd, nil
+}
+
+// pollFd is struct pollfd from poll(2).
+type pollFd struct {
+	fd      int32
+	events  int16
+	revents int16
+}
+
+const POLLIN = 0x1
+
+// waitReadable blocks until fd has a pending connection or datagram
+// without consuming it. It polls rather than selects, since an FdSet only
+// holds descriptors below 1024.
+func waitReadable(fd int) error {
+	for {
+		p := pollFd{fd: int32(fd), events: POLLIN}
+		_, _, errno := syscall.Syscall6(syscall.SYS_PPOLL, uintptr(unsafe.Pointer(&p)), 1, 0, 0, 0, 0)
+		if errno == syscall.EINTR {
+			continue
+		}
+		if errno != 0 {
+			return errno
+		}
+		return nil
+	}
+}
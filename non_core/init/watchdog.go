@@ -0,0 +1,142 @@
+package init
+
+import (
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+// Watchdog
+
+const (
+	WATCHDOG_DEVICE  = "/dev/watchdog"
+	WATCHDOG_TIMEOUT = 60 // seconds, unless init.watchdog= says otherwise
+
+	WDIOC_KEEPALIVE  = 0x80045705 // _IOR('W', 5, int)
+	WDIOC_SETTIMEOUT = 0xc0045706 // _IOWR('W', 6, int)
+	WDIOC_GETTIMEOUT = 0x80045707 // _IOR('W', 7, int)
+)
+
+var (
+	watchdog        *os.File
+	watchdogTimeout = WATCHDOG_TIMEOUT
+	heartbeat       atomic.Int64
+
+	// stateMu guards the runlevel, the entry list and the entries' PIDs.
+	// It is only ever held briefly, so superviseLoop failing to take it
+	// means init is deadlocked.
+	stateMu sync.Mutex
+	// transitionMu serializes runlevel changes, reloads and shutdown,
+	// which wait on processes and scripts and so cannot hold stateMu.
+	transitionMu sync.Mutex
+)
+
+// superviseInterval pings well within the watchdog timeout.
+func superviseInterval() time.Duration {
+	interval := time.Duration(watchdogTimeout) * time.Second / 4
+	if interval <= 0 || interval > 10*time.Second {
+		interval = 10 * time.Second
+	}
+	return interval
+}
+
+// startWatchdog arms /dev/watchdog if the kernel has one. init.watchdog=N
+// sets the timeout in seconds and init.watchdog=0 leaves it alone.
+func startWatchdog() {
+	if value, ok := kernelParam("init.watchdog"); ok {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			Printf("Ignoring invalid init.watchdog=%s\n", value)
+		} else {
+			watchdogTimeout = n
+		}
+	}
+	if watchdogTimeout == 0 {
+		return
+	}
+
+	f, err := os.OpenFile(WATCHDOG_DEVICE, os.O_WRONLY, 0)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			Printf("Error opening %s: %v\n", WATCHDOG_DEVICE, err)
+		}
+		return
+	}
+	watchdog = f
+
+	timeout := int32(watchdogTimeout)
+	if err := watchdogIoctl(WDIOC_SETTIMEOUT, &timeout); err != nil {
+		// Not every driver can change its timeout; use what it has.
+		watchdogIoctl(WDIOC_GETTIMEOUT, &timeout)
+		Debugf("watchdog: cannot set timeout: %v", err)
+	}
+	if timeout > 0 {
+		watchdogTimeout = int(timeout)
+	}
+	Printf("Watchdog armed with a %ds timeout\n", watchdogTimeout)
+}
+
+func keepaliveWatchdog() {
+	if watchdog == nil {
+		return
+	}
+	var dummy int32
+	if err := watchdogIoctl(WDIOC_KEEPALIVE, &dummy); err != nil {
+		// Fall back to the write interface, which every driver supports
+		watchdog.Write([]byte{0})
+	}
+}
+
+// stopWatchdog disarms the watchdog with the magic close: writing 'V'
+// right before closing tells the driver the close is deliberate.
+func stopWatchdog() {
+	if watchdog == nil {
+		return
+	}
+	watchdog.Write([]byte("V"))
+	watchdog.Close()
+	watchdog = nil
+	PrintLn("Watchdog disarmed")
+}
+
+func watchdogIoctl(req uintptr, arg *int32) error {
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, watchdog.Fd(), req, uintptr(unsafe.Pointer(arg)))
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+// hangDetector notices when superviseLoop has not run for several
+// intervals, logs where every goroutine is stuck and falls back to
+// recovery mode. The watchdog is disarmed first so that the hardware does
+// not reset the machine under the user's recovery shell.
+func hangDetector() {
+	interval := superviseInterval()
+	limit := 3 * interval
+	for {
+		time.Sleep(interval)
+		last := time.Unix(0, heartbeat.Load())
+		stalled := time.Since(last)
+		if stalled < limit {
+			continue
+		}
+
+		Printf("\033[31mSupervisor loop stalled for %v\033[0m\n", stalled.Round(time.Second))
+		buf := make([]byte, 1<<20)
+		n := runtime.Stack(buf, true)
+		for _, line := range strings.Split(string(buf[:n]), "\n") {
+			debug("hang: %s", line)
+		}
+
+		stopWatchdog()
+		enterRecoveryMode()
+		return
+	}
+}
@@ -0,0 +1,426 @@
+package login
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"fmt"
+	"hash"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+	"unsafe"
+
+	"github.com/ilnarildarovuch/CoreUtils-On-GO/internal/cli"
+	"github.com/ilnarildarovuch/CoreUtils-On-GO/internal/users"
+)
+
+const (
+	version     = "1.0.0"
+	motdPath    = "/etc/motd"
+	maxAttempts = 3
+	failDelay   = 3 * time.Second
+)
+
+var flags = cli.NewFlagSet(version)
+
+var (
+	preAuth     = flags.String('f', "", "", "do not authenticate `USER`, already authenticated")
+	preserveEnv = flags.Bool('p', "", "preserve the environment")
+)
+
+func Main() {
+	flags.Usage = usage
+	flags.Parse(os.Args[1:])
+
+	if os.Geteuid() != 0 {
+		cli.Fatalf("cannot possibly work without effective root")
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	name := flags.Arg(0)
+	if *preAuth != "" {
+		name = *preAuth
+	}
+
+	var acct *users.Account
+	for attempt := 1; ; attempt++ {
+		if name == "" {
+			name = prompt(reader, "login: ")
+		}
+
+		var ok bool
+		acct, ok = authenticate(reader, name)
+		if ok {
+			break
+		}
+
+		logAuth(4, "FAILED LOGIN %d FOR '%s' ON '%s'", attempt, name, ttyName())
+		time.Sleep(failDelay)
+		fmt.Println("Login incorrect")
+		if attempt >= maxAttempts {
+			os.Exit(1)
+		}
+		name = ""
+	}
+
+	if err := startSession(acct); err != nil {
+		cli.Fatalf("%v", err)
+	}
+}
+
+func usage() {
+	w := flags.Output()
+	fmt.Fprintf(w, "Usage: %s [-p] [USERNAME]\n", cli.Name)
+	fmt.Fprintf(w, "  or:  %s [-p] -f USERNAME\n", cli.Name)
+	fmt.Fprintln(w, "\nBegin a session on the system.")
+	fmt.Fprintln(w, "\nOptions:")
+	flags.PrintDefaults()
+	fmt.Fprintf(w, "\nExamples:\n  %s root\n", cli.Name)
+}
+
+func prompt(reader *bufio.Reader, text string) string {
+	fmt.Print(text)
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		os.Exit(1)
+	}
+	return strings.TrimSpace(line)
+}
+
+// authenticate looks the user up and, unless -f was given, asks for and
+// checks the password. The password is asked for even when the user does
+// not exist so that the prompt does not reveal valid names.
+func authenticate(reader *bufio.Reader, name string) (*users.Account, bool) {
+	acct, err := lookupAccount(name)
+
+	needPassword := *preAuth == "" && (err != nil || acct.Password != "")
+	var password string
+	if needPassword {
+		password = readPassword(reader, "Password: ")
+	}
+	if err != nil {
+		return nil, false
+	}
+	if !needPassword {
+		return acct, true
+	}
+	return acct, checkPassword(password, acct.Password)
+}
+
+// lookupAccount finds name in the passwd file, taking the hash from the
+// shadow file when the passwd entry defers to it.
+func lookupAccount(name string) (*users.Account, error) {
+	acct, err := users.LookupAccount(name)
+	if err != nil {
+		return nil, err
+	}
+	if acct.Name != name {
+		// A uid is no login name.
+		return nil, fmt.Errorf("no account %s", name)
+	}
+
+	if acct.Password == "x" {
+		hash, err := users.ShadowPassword(name)
+		if err != nil {
+			// No shadow entry: treat the account as locked.
+			hash = "!"
+		}
+		acct.Password = hash
+	}
+	if acct.Shell == "" {
+		acct.Shell = "/bin/gsh"
+	}
+	if acct.Home == "" {
+		acct.Home = "/"
+	}
+	return acct, nil
+}
+
+func readPassword(reader *bufio.Reader, text string) string {
+	var t syscall.Termios
+	echoOff := ioctl(0, syscall.TCGETS, unsafe.Pointer(&t)) == nil
+	if echoOff {
+		noEcho := t
+		noEcho.Lflag &^= syscall.ECHO
+		ioctl(0, syscall.TCSETS, unsafe.Pointer(&noEcho))
+		defer ioctl(0, syscall.TCSETS, unsafe.Pointer(&t))
+	}
+
+	fmt.Print(text)
+	line, err := reader.ReadString('\n')
+	if echoOff {
+		fmt.Println()
+	}
+	if err != nil {
+		os.Exit(1)
+	}
+	return strings.TrimRight(line, "\r\n")
+}
+
+func ioctl(fd int, req uint, arg unsafe.Pointer) error {
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), uintptr(req), uintptr(arg)); errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+// checkPassword verifies password against a crypt(3) hash. Only the
+// SHA-256 ($5$) and SHA-512 ($6$) schemes are supported; locked ("!", "*")
+// and unknown hashes never match.
+func checkPassword(password, hashed string) bool {
+	var newHash func() hash.Hash
+	switch {
+	case strings.HasPrefix(hashed, "$5$"):
+		newHash = sha256.New
+	case strings.HasPrefix(hashed, "$6$"):
+		newHash = sha512.New
+	default:
+		return false
+	}
+
+	computed, ok := shaCrypt(newHash, password, hashed)
+	if !ok {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(computed), []byte(hashed)) == 1
+}
+
+// shaCrypt implements Ulrich Drepper's SHA-crypt. setting is a full or
+// partial hash string ("$6$[rounds=N$]salt[$...]") from which the scheme,
+// rounds and salt are taken.
+func shaCrypt(newHash func() hash.Hash, password, setting string) (string, bool) {
+	parts := strings.Split(setting, "$")
+	if len(parts) < 3 {
+		return "", false
+	}
+	id := parts[1]
+	rest := parts[2:]
+
+	rounds := 5000
+	customRounds := false
+	if strings.HasPrefix(rest[0], "rounds=") {
+		n, err := strconv.Atoi(strings.TrimPrefix(rest[0], "rounds="))
+		if err != nil || len(rest) < 2 {
+			return "", false
+		}
+		rounds = min(max(n, 1000), 999999999)
+		customRounds = true
+		rest = rest[1:]
+	}
+
+	salt := rest[0]
+	if len(salt) > 16 {
+		salt = salt[:16]
+	}
+
+	pw := []byte(password)
+	s := []byte(salt)
+
+	b := newHash()
+	b.Write(pw)
+	b.Write(s)
+	b.Write(pw)
+	digestB := b.Sum(nil)
+	size := len(digestB)
+
+	a := newHash()
+	a.Write(pw)
+	a.Write(s)
+	a.Write(repeatBytes(digestB, len(pw)))
+	for n := len(pw); n > 0; n >>= 1 {
+		if n&1 != 0 {
+			a.Write(digestB)
+		} else {
+			a.Write(pw)
+		}
+	}
+	digestA := a.Sum(nil)
+
+	dp := newHash()
+	for i := 0; i < len(pw); i++ {
+		dp.Write(pw)
+	}
+	seqP := repeatBytes(dp.Sum(nil), len(pw))
+
+	ds := newHash()
+	for i := 0; i < 16+int(digestA[0]); i++ {
+		ds.Write(s)
+	}
+	seqS := repeatBytes(ds.Sum(nil), len(s))
+
+	digest := digestA
+	for i := 0; i < rounds; i++ {
+		c := newHash()
+		if i&1 != 0 {
+			c.Write(seqP)
+		} else {
+			c.Write(digest)
+		}
+		if i%3 != 0 {
+			c.Write(seqS)
+		}
+		if i%7 != 0 {
+			c.Write(seqP)
+		}
+		if i&1 != 0 {
+			c.Write(digest)
+		} else {
+			c.Write(seqP)
+		}
+		digest = c.Sum(nil)
+	}
+
+	var out strings.Builder
+	out.WriteString("$" + id + "$")
+	if customRounds {
+		fmt.Fprintf(&out, "rounds=%d$", rounds)
+	}
+	out.WriteString(salt + "$")
+
+	if size == sha512.Size {
+		for i := 0; i < 21; i++ {
+			encode24(&out, digest[i], digest[i+21], digest[i+42], i%3)
+		}
+		b64From24(&out, 0, 0, digest[63], 2)
+	} else {
+		for i := 0; i < 10; i++ {
+			encode24(&out, digest[i], digest[i+10], digest[i+20], (3-i%3)%3)
+		}
+		b64From24(&out, 0, digest[31], digest[30], 3)
+	}
+	return out.String(), true
+}
+
+// encode24 emits one group of the SHA-crypt output permutation. The
+// byte order within a group is rotated left by shift positions, which is
+// what the reference implementation's hand-written tables amount to.
+func encode24(out *strings.Builder, first, second, third byte, shift int) {
+	switch shift {
+	case 0:
+		b64From24(out, first, second, third, 4)
+	case 1:
+		b64From24(out, second, third, first, 4)
+	case 2:
+		b64From24(out, third, first, second, 4)
+	}
+}
+
+func b64From24(out *strings.Builder, b2, b1, b0 byte, n int) {
+	const alphabet = "./0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+	w := uint(b2)<<16 | uint(b1)<<8 | uint(b0)
+	for ; n > 0; n-- {
+		out.WriteByte(alphabet[w&0x3f])
+		w >>= 6
+	}
+}
+
+func repeatBytes(src []byte, length int) []byte {
+	out := make([]byte, 0, length)
+	for len(out) < length {
+		out = append(out, src[:min(len(src), length-len(out))]...)
+	}
+	return out
+}
+
+// Session
+
+func startSession(acct *users.Account) error {
+	tty := ttyName()
+	if tty != "" {
+		os.Chown(tty, acct.UID, ttyGroup(acct.GID))
+		os.Chmod(tty, 0620)
+	}
+
+	groups := users.GroupIDs(acct.Name, acct.GID)
+	if err := syscall.Setgroups(groups); err != nil {
+		return fmt.Errorf("setgroups: %v", err)
+	}
+	if err := syscall.Setgid(acct.GID); err != nil {
+		return fmt.Errorf("setgid: %v", err)
+	}
+	if err := syscall.Setuid(acct.UID); err != nil {
+		return fmt.Errorf("setuid: %v", err)
+	}
+
+	if err := os.Chdir(acct.Home); err != nil {
+		cli.Warnf("no home directory %s, logging in with HOME=/", acct.Home)
+		acct.Home = "/"
+		os.Chdir("/")
+	}
+
+	env := sessionEnv(acct)
+	logAuth(6, "LOGIN ON '%s' BY '%s'", tty, acct.Name)
+	printMotd()
+
+	// A leading dash in argv[0] marks a login shell.
+	shellName := acct.Shell[strings.LastIndex(acct.Shell, "/")+1:]
+	return syscall.Exec(acct.Shell, []string{"-" + shellName}, env)
+}
+
+func sessionEnv(acct *users.Account) []string {
+	vars := map[string]string{}
+	if *preserveEnv {
+		for _, kv := range os.Environ() {
+			if k, v, ok := strings.Cut(kv, "="); ok {
+				vars[k] = v
+			}
+		}
+	} else if term := os.Getenv("TERM"); term != "" {
+		vars["TERM"] = term
+	}
+
+	path := "/bin:/usr/bin"
+	if acct.UID == 0 {
+		path = "/sbin:/bin:/usr/sbin:/usr/bin"
+	}
+	vars["HOME"] = acct.Home
+	vars["SHELL"] = acct.Shell
+	vars["USER"] = acct.Name
+	vars["LOGNAME"] = acct.Name
+	vars["PATH"] = path
+
+	env := make([]string, 0, len(vars))
+	for k, v := range vars {
+		env = append(env, k+"="+v)
+	}
+	return env
+}
+
+func ttyGroup(fallback int) int {
+	if gid, _, err := users.LookupGroup("tty"); err == nil {
+		return gid
+	}
+	return fallback
+}
+
+func ttyName() string {
+	name, err := os.Readlink("/proc/self/fd/0")
+	if err != nil || !strings.HasPrefix(name, "/dev/") {
+		return ""
+	}
+	return name
+}
+
+func printMotd() {
+	if data, err := os.ReadFile(motdPath); err == nil {
+		fmt.Print(string(data))
+	}
+}
+
+// logAuth sends an authpriv message to syslogd; it is silently dropped
+// when syslogd is not running.
+func logAuth(severity int, format string, a ...interface{}) {
+	conn, err := net.Dial("unixgram", "/dev/log")
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	msg := fmt.Sprintf(format, a...)
+	fmt.Fprintf(conn, "<%d>%s login[%d]: %s", 10<<3|severity, time.Now().Format(time.Stamp), os.Getpid(), msg)
+}
@@ -0,0 +1,70 @@
+package login
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"testing"
+)
+
+// The test vectors of Ulrich Drepper's SHA-crypt specification. Rounds
+// below 1000 are raised to 1000, and salts cut to 16 characters.
+var shaCryptTests = []struct {
+	setting, password, want string
+}{
+	{"$5$saltstring", "Hello world!",
+		"$5$saltstring$5B8vYYiY.CVt1RlTTf8KbXBH3hsxY/GNooZaBBGWEc5"},
+	{"$5$rounds=10000$saltstringsaltstring", "Hello world!",
+		"$5$rounds=10000$saltstringsaltst$3xv.VbSHBb41AL9AvLeujZkZRBAwqFMz2.opqey6IcA"},
+	{"$5$rounds=5000$toolongsaltstring", "This is just a test",
+		"$5$rounds=5000$toolongsaltstrin$Un/5jzAHMgOGZ5.mWJpuVolil07guHPvOW8mGRcvxa5"},
+	{"$5$rounds=1400$anotherlongsaltstring", "a very much longer text to encrypt.  This one even stretches over morethan one line.",
+		"$5$rounds=1400$anotherlongsalts$Rx.j8H.h8HjEDGomFU8bDkXm3XIUnzyxf12oP84Bnq1"},
+	{"$5$rounds=77777$short", "we have a short salt string but not a short password",
+		"$5$rounds=77777$short$JiO1O3ZpDAxGJeaDIuqCoEFysAe1mZNJRs3pw0KQRd/"},
+	{"$5$rounds=123456$asaltof16chars..", "a short string",
+		"$5$rounds=123456$asaltof16chars..$gP3VQ/6X7UUEW3HkBn2w1/Ptq2jxPyzV/cZKmF/wJvD"},
+	{"$5$rounds=10$roundstoolow", "the minimum number is still observed",
+		"$5$rounds=1000$roundstoolow$yfvwcWrQ8l/K0DAWyuPMDNHpIVlTQebY9l/gL972bIC"},
+	{"$6$saltstring", "Hello world!",
+		"$6$saltstring$svn8UoSVapNtMuq1ukKS4tPQd8iKwSMHWjl/O817G3uBnIFNjnQJuesI68u4OTLiBFdcbYEdFCoEOfaS35inz1"},
+	{"$6$rounds=10000$saltstringsaltstring", "Hello world!",
+		"$6$rounds=10000$saltstringsaltst$OW1/O6BYHV6BcXZu8QVeXbDWra3Oeqh0sbHbbMCVNSnCM/UrjmM0Dp8vOuZeHBy/YTBmSK6H9qs/y3RnOaw5v."},
+	{"$6$rounds=5000$toolongsaltstring", "This is just a test",
+		"$6$rounds=5000$toolongsaltstrin$lQ8jolhgVRVhY4b5pZKaysCLi0QBxGoNeKQzQ3glMhwllF7oGDZxUhx1yxdYcz/e1JSbq3y6JMxxl8audkUEm0"},
+	{"$6$rounds=1400$anotherlongsaltstring", "a very much longer text to encrypt.  This one even stretches over morethan one line.",
+		"$6$rounds=1400$anotherlongsalts$POfYwTEok97VWcjxIiSOjiykti.o/pQs.wPvMxQ6Fm7I6IoYN3CmLs66x9t0oSwbtEW7o7UmJEiDwGqd8p4ur1"},
+	{"$6$rounds=77777$short", "we have a short salt string but not a short password",
+		"$6$rounds=77777$short$WuQyW2YR.hBNpjjRhpYD/ifIw05xdfeEyQoMxIXbkvr0gge1a1x3yRULJ5CCaUeOxFmtlcGZelFl5CxtgfiAc0"},
+	{"$6$rounds=123456$asaltof16chars..", "a short string",
+		"$6$rounds=123456$asaltof16chars..$BtCwjqMJGx5hrJhZywWvt0RLE8uZ4oPwcelCjmw2kSYu.Ec6ycULevoBK25fs2xXgMNrCzIMVcgEJAstJeonj1"},
+	{"$6$rounds=10$roundstoolow", "the minimum number is still observed",
+		"$6$rounds=1000$roundstoolow$kUMsbe306n21p9R.FRkW3IGn.S9NPN0x50YhH1xhLsPuWGsUSklZt58jaTfF4ZEQpyUNGc0dqbpBYYBaHHrsX."},
+}
+
+func TestShaCrypt(t *testing.T) {
+	for _, tt := range shaCryptTests {
+		newHash := sha256.New
+		if tt.setting[1] == '6' {
+			newHash = sha512.New
+		}
+		if got, ok := shaCrypt(newHash, tt.password, tt.setting); !ok || got != tt.want {
+			t.Errorf("shaCrypt(%q, %q) = %q, %v, want %q", tt.password, tt.setting, got, ok, tt.want)
+		}
+	}
+}
+
+func TestCheckPassword(t *testing.T) {
+	for _, tt := range shaCryptTests {
+		if !checkPassword(tt.password, tt.want) {
+			t.Errorf("checkPassword(%q, %q) = false", tt.password, tt.want)
+		}
+		if checkPassword(tt.password+"x", tt.want) {
+			t.Errorf("checkPassword(%q, %q) = true", tt.password+"x", tt.want)
+		}
+	}
+	for _, hashed := range []string{"", "!", "*", "x", "$1$salt$hash", "$5$", "$6$rounds=x$salt$hash"} {
+		if checkPassword("", hashed) {
+			t.Errorf("checkPassword(%q, %q) = true", "", hashed)
+		}
+	}
+}
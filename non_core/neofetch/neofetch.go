@@ -1,4 +1,4 @@
-package main
+package neofetch
 
 import (
 	"fmt"
@@ -51,7 +51,7 @@ var (
 	}
 )
 
-func main() {
+func Main() {
 	initConfig()
 	gatherInfo()
 	info.Title = fmt.Sprintf("%s@%s", info.User, info.Host)
@@ -0,0 +1,501 @@
+package syslogd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/ilnarildarovuch/CoreUtils-On-GO/internal/cli"
+)
+
+const (
+	version = "1.0.0"
+)
+
+var flags = cli.NewFlagSet(version)
+
+var (
+	socketPath  = flags.String('S', "", "/dev/log", "listen for datagrams on the unix socket `PATH`")
+	configPath  = flags.String('f', "", "/etc/syslog.conf", "read rules from `FILE`")
+	defaultLog  = flags.String('O', "", "/var/log/syslog", "log to `FILE` when no rules are configured")
+	rotateSize  = flags.Int('s', "", 200, "rotate log files when they exceed `SIZE` kilobytes (0 disables)")
+	rotateCount = flags.Int('b', "", 1, "keep `N` rotated log files")
+	noKernel    = flags.Bool('K', "", "do not read kernel messages from /dev/kmsg")
+	foreground  = flags.Bool('n', "", "run in foreground (accepted for compatibility)")
+)
+
+var facilityNames = map[string]int{
+	"kern": 0, "user": 1, "mail": 2, "daemon": 3,
+	"auth": 4, "syslog": 5, "lpr": 6, "news": 7,
+	"uucp": 8, "cron": 9, "authpriv": 10, "ftp": 11,
+	"local0": 16, "local1": 17, "local2": 18, "local3": 19,
+	"local4": 20, "local5": 21, "local6": 22, "local7": 23,
+}
+
+var severityNames = map[string]int{
+	"emerg": 0, "panic": 0, "alert": 1, "crit": 2,
+	"err": 3, "error": 3, "warning": 4, "warn": 4,
+	"notice": 5, "info": 6, "debug": 7,
+}
+
+type Message struct {
+	Facility int
+	Severity int
+	Time     time.Time
+	Host     string
+	Tag      string
+	Text     string
+}
+
+// Selector matches one "facility.severity" pair of a rule.
+// Severity comparison follows sysklogd: ".info" means info or higher,
+// ".=info" exactly info, ".!info" lower than info and ".none" nothing.
+type Selector struct {
+	Facilities map[int]bool // nil means every facility
+	Severity   int
+	Compare    byte // '>' at least, '=' exactly, '!' below
+	None       bool
+}
+
+type Rule struct {
+	Selectors []Selector
+	Path      string
+}
+
+type LogFile struct {
+	Path string
+	file *os.File
+	size int64
+}
+
+var (
+	rules    []Rule
+	logFiles = make(map[string]*LogFile)
+	mu       sync.Mutex
+	hostname string
+)
+
+func Main() {
+	flags.Usage = usage
+	flags.Parse(os.Args[1:])
+
+	hostname, _ = os.Hostname()
+	loadConfig()
+
+	conn, err := listen(*socketPath)
+	if err != nil {
+		cli.Fatalf("%s: %v", *socketPath, err)
+	}
+	defer os.Remove(*socketPath)
+
+	setupSignals(conn)
+
+	if !*noKernel {
+		go readKernelLog("/dev/kmsg")
+	}
+
+	logMessage(Message{Facility: 5, Severity: 6, Time: time.Now(), Host: hostname,
+		Tag: "syslogd", Text: "syslogd " + version + " started"})
+	readSocket(conn)
+}
+
+func usage() {
+	w := flags.Output()
+	fmt.Fprintf(w, "Usage: %s [OPTION]...\n", cli.Name)
+	fmt.Fprintln(w, "\nCollect messages from /dev/log and /dev/kmsg into log files.")
+	fmt.Fprintln(w, "\nOptions:")
+	flags.PrintDefaults()
+	fmt.Fprintf(w, "\nExamples:\n  %s -n\n  %s -s 1024 -b 3\n", cli.Name, cli.Name)
+}
+
+func listen(path string) (*net.UnixConn, error) {
+	os.Remove(path)
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		return nil, err
+	}
+	os.Chmod(path, 0666)
+	return conn, nil
+}
+
+func setupSignals(conn *net.UnixConn) {
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGHUP, syscall.SIGTERM, syscall.SIGINT)
+
+	go func() {
+		for sig := range sigCh {
+			switch sig {
+			case syscall.SIGHUP:
+				loadConfig()
+			default:
+				conn.Close()
+				closeLogFiles()
+				os.Remove(*socketPath)
+				os.Exit(0)
+			}
+		}
+	}()
+}
+
+// Config
+
+func loadConfig() {
+	mu.Lock()
+	defer mu.Unlock()
+
+	closeLogFilesLocked()
+	rules = nil
+
+	file, err := os.Open(*configPath)
+	if err != nil {
+		rules = []Rule{{Selectors: []Selector{{Severity: 7, Compare: '>'}}, Path: *defaultLog}}
+		return
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		rule, ok, err := parseRule(scanner.Text())
+		if err != nil {
+			cli.Warnf("%s:%d: %v", *configPath, lineNo, err)
+			continue
+		}
+		if ok {
+			rules = append(rules, rule)
+		}
+	}
+}
+
+func parseRule(line string) (Rule, bool, error) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return Rule{}, false, nil
+	}
+
+	fields := strings.Fields(line)
+	if len(fields) != 2 {
+		return Rule{}, false, fmt.Errorf("expected SELECTOR ACTION")
+	}
+
+	var rule Rule
+	for _, part := range strings.Split(fields[0], ";") {
+		sel, err := parseSelector(part)
+		if err != nil {
+			return Rule{}, false, err
+		}
+		rule.Selectors = append(rule.Selectors, sel)
+	}
+	rule.Path = strings.TrimPrefix(fields[1], "-")
+	return rule, true, nil
+}
+
+func parseSelector(s string) (Selector, error) {
+	dot := strings.LastIndex(s, ".")
+	if dot < 0 {
+		return Selector{}, fmt.Errorf("invalid selector: %s", s)
+	}
+	facPart, sevPart := s[:dot], s[dot+1:]
+
+	var sel Selector
+	if facPart != "*" {
+		sel.Facilities = make(map[int]bool)
+		for _, name := range strings.Split(facPart, ",") {
+			fac, ok := facilityNames[name]
+			if !ok {
+				return Selector{}, fmt.Errorf("unknown facility: %s", name)
+			}
+			sel.Facilities[fac] = true
+		}
+	}
+
+	sel.Compare = '>'
+	if strings.HasPrefix(sevPart, "=") || strings.HasPrefix(sevPart, "!") {
+		sel.Compare = sevPart[0]
+		sevPart = sevPart[1:]
+	}
+
+	switch sevPart {
+	case "*":
+		sel.Severity = 7
+	case "none":
+		sel.None = true
+	default:
+		sev, ok := severityNames[sevPart]
+		if !ok {
+			return Selector{}, fmt.Errorf("unknown severity: %s", sevPart)
+		}
+		sel.Severity = sev
+	}
+	return sel, nil
+}
+
+// Matches reports whether the rule logs msg. Later selectors override
+// earlier ones for the facilities they name, so "*.info;mail.none" works.
+func (r Rule) Matches(msg Message) bool {
+	matched := false
+	for _, sel := range r.Selectors {
+		if sel.Facilities != nil && !sel.Facilities[msg.Facility] {
+			continue
+		}
+		switch {
+		case sel.None:
+			matched = false
+		case sel.Compare == '=':
+			matched = msg.Severity == sel.Severity
+		case sel.Compare == '!':
+			matched = msg.Severity > sel.Severity
+		default:
+			matched = msg.Severity <= sel.Severity
+		}
+	}
+	return matched
+}
+
+// Input
+
+func readSocket(conn *net.UnixConn) {
+	buf := make([]byte, 64*1024)
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			return
+		}
+		logMessage(parseMessage(string(buf[:n])))
+	}
+}
+
+func readKernelLog(path string) {
+	f, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+
+	buf := make([]byte, 8192)
+	for {
+		n, err := f.Read(buf)
+		if errors.Is(err, syscall.EPIPE) {
+			// Records were overwritten before we read them; keep going.
+			continue
+		}
+		if err != nil {
+			if err != io.EOF {
+				cli.Warnf("%s: %v", path, err)
+			}
+			return
+		}
+		if msg, ok := parseKmsg(string(buf[:n])); ok {
+			logMessage(msg)
+		}
+	}
+}
+
+// parseKmsg decodes one /dev/kmsg record: "PRI,SEQ,USEC,FLAGS;TEXT"
+// followed by optional " KEY=VALUE" continuation lines.
+func parseKmsg(record string) (Message, bool) {
+	semi := strings.Index(record, ";")
+	if semi < 0 {
+		return Message{}, false
+	}
+	header := strings.Split(record[:semi], ",")
+	pri, err := strconv.Atoi(header[0])
+	if err != nil {
+		return Message{}, false
+	}
+
+	text := record[semi+1:]
+	if nl := strings.Index(text, "\n"); nl >= 0 {
+		text = text[:nl]
+	}
+
+	return Message{
+		Facility: pri >> 3,
+		Severity: pri & 7,
+		Time:     time.Now(),
+		Host:     hostname,
+		Tag:      "kernel",
+		Text:     text,
+	}, true
+}
+
+// parseMessage accepts RFC 5424 ("<PRI>1 TIMESTAMP HOST APP PROCID MSGID SD MSG")
+// and RFC 3164 ("<PRI>Mmm dd hh:mm:ss TAG: MSG") datagrams. Anything else
+// is logged as user.notice with the raw text.
+func parseMessage(raw string) Message {
+	raw = strings.TrimRight(raw, "\x00\n")
+	msg := Message{Facility: 1, Severity: 5, Time: time.Now(), Host: hostname}
+
+	if strings.HasPrefix(raw, "<") {
+		if end := strings.Index(raw, ">"); end > 1 && end <= 4 {
+			if pri, err := strconv.Atoi(raw[1:end]); err == nil {
+				msg.Facility = pri >> 3
+				msg.Severity = pri & 7
+				raw = raw[end+1:]
+			}
+		}
+	}
+
+	if strings.HasPrefix(raw, "1 ") {
+		parseRFC5424(raw[2:], &msg)
+	} else {
+		parseRFC3164(raw, &msg)
+	}
+	return msg
+}
+
+func parseRFC5424(s string, msg *Message) {
+	fields := strings.SplitN(s, " ", 6)
+	if len(fields) < 6 {
+		msg.Text = s
+		return
+	}
+	if t, err := time.Parse(time.RFC3339Nano, fields[0]); err == nil {
+		msg.Time = t.Local()
+	}
+	if fields[1] != "-" {
+		msg.Host = fields[1]
+	}
+	msg.Tag = fields[2]
+	if fields[3] != "-" {
+		msg.Tag += "[" + fields[3] + "]"
+	}
+
+	// fields[4] is MSGID; fields[5] holds structured data and the message.
+	rest := fields[5]
+	if strings.HasPrefix(rest, "-") {
+		rest = strings.TrimPrefix(rest[1:], " ")
+	} else if strings.HasPrefix(rest, "[") {
+		if end := strings.Index(rest, "] "); end >= 0 {
+			rest = rest[end+2:]
+		}
+	}
+	msg.Text = strings.TrimPrefix(rest, "\ufeff")
+}
+
+func parseRFC3164(s string, msg *Message) {
+	if len(s) >= 16 && s[15] == ' ' {
+		if t, err := time.ParseInLocation(time.Stamp, s[:15], time.Local); err == nil {
+			msg.Time = t.AddDate(time.Now().Year(), 0, 0)
+			s = s[16:]
+		}
+	}
+
+	if colon := strings.Index(s, ": "); colon > 0 && !strings.ContainsAny(s[:colon], " \t") {
+		msg.Tag = s[:colon]
+		s = s[colon+2:]
+	}
+	msg.Text = s
+}
+
+// Output
+
+func logMessage(msg Message) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	line := formatMessage(msg)
+	for _, rule := range rules {
+		if !rule.Matches(msg) {
+			continue
+		}
+		if err := writeLog(rule.Path, line); err != nil {
+			cli.Warnf("%s: %v", rule.Path, err)
+		}
+	}
+}
+
+func formatMessage(msg Message) string {
+	tag := msg.Tag
+	if tag != "" {
+		tag += ": "
+	}
+	return fmt.Sprintf("%s %s %s.%s %s%s\n",
+		msg.Time.Format(time.Stamp), msg.Host,
+		facilityName(msg.Facility), severityName(msg.Severity), tag, msg.Text)
+}
+
+func facilityName(fac int) string {
+	for name, n := range facilityNames {
+		if n == fac {
+			return name
+		}
+	}
+	return strconv.Itoa(fac)
+}
+
+func severityName(sev int) string {
+	names := []string{"emerg", "alert", "crit", "err", "warning", "notice", "info", "debug"}
+	if sev >= 0 && sev < len(names) {
+		return names[sev]
+	}
+	return strconv.Itoa(sev)
+}
+
+func writeLog(path, line string) error {
+	lf, ok := logFiles[path]
+	if !ok {
+		f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0640)
+		if err != nil {
+			return err
+		}
+		info, _ := f.Stat()
+		lf = &LogFile{Path: path, file: f, size: info.Size()}
+		logFiles[path] = lf
+	}
+
+	n, err := lf.file.WriteString(line)
+	lf.size += int64(n)
+	if err != nil {
+		return err
+	}
+
+	if *rotateSize > 0 && lf.size > int64(*rotateSize)*1024 {
+		return rotate(lf)
+	}
+	return nil
+}
+
+// rotate renames path.N-1 to path.N, ..., path to path.0 and reopens path.
+func rotate(lf *LogFile) error {
+	lf.file.Close()
+
+	if *rotateCount > 0 {
+		for i := *rotateCount - 1; i > 0; i-- {
+			os.Rename(fmt.Sprintf("%s.%d", lf.Path, i-1), fmt.Sprintf("%s.%d", lf.Path, i))
+		}
+		os.Rename(lf.Path, lf.Path+".0")
+	}
+
+	f, err := os.OpenFile(lf.Path, os.O_TRUNC|os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0640)
+	if err != nil {
+		delete(logFiles, lf.Path)
+		return err
+	}
+	lf.file = f
+	lf.size = 0
+	return nil
+}
+
+func closeLogFiles() {
+	mu.Lock()
+	defer mu.Unlock()
+	closeLogFilesLocked()
+}
+
+func closeLogFilesLocked() {
+	for path, lf := range logFiles {
+		lf.file.Close()
+		delete(logFiles, path)
+	}
+}
@@ -0,0 +1,292 @@
+package qemu
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// The QEMU suite boots bootable/boot/vmlinuz-1 with an initramfs built
+// from the current tree under qemu-system-x86_64 -nographic, logs in as
+// root on the serial console and runs every transcript in testdata
+// through gsh. It is skipped with -short and when QEMU or the kernel is
+// missing.
+//
+// The environment can override the defaults:
+//
+//	QEMU          emulator binary (qemu-system-x86_64)
+//	QEMU_ACCEL    accelerator (tcg, which needs no KVM)
+//	QEMU_KERNEL   kernel image (bootable/boot/vmlinuz-1)
+//	QEMU_INITRD   initramfs to boot instead of building one
+//	QEMU_TIMEOUT  time allowed to reach the login prompt (3m)
+
+const root = "../.."
+
+var (
+	loginPrompt = regexp.MustCompile(`login: `)
+	// gsh's coloured "user@host:cwd$ " prompt.
+	shellPrompt = regexp.MustCompile(`\x1b\[32m[^\x1b]*\x1b\[0m:\x1b\[34m[^\x1b]*\x1b\[0m\$ `)
+)
+
+// commandTimeout bounds one transcript command, emulation included.
+const commandTimeout = time.Minute
+
+func getenv(key, value string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return value
+}
+
+func TestBoot(t *testing.T) {
+	if testing.Short() {
+		t.Skip("QEMU suite skipped in short mode")
+	}
+	qemu, err := exec.LookPath(getenv("QEMU", "qemu-system-x86_64"))
+	if err != nil {
+		t.Skipf("QEMU suite needs qemu-system-x86_64: %v", err)
+	}
+	kernel := getenv("QEMU_KERNEL", filepath.Join(root, "bootable/boot/vmlinuz-1"))
+	if _, err := os.Stat(kernel); err != nil {
+		t.Skipf("QEMU suite needs a kernel: %v", err)
+	}
+	bootTimeout, err := time.ParseDuration(getenv("QEMU_TIMEOUT", "3m"))
+	if err != nil {
+		t.Fatalf("QEMU_TIMEOUT: %v", err)
+	}
+
+	transcripts, _ := filepath.Glob("testdata/*.txt")
+	sort.Strings(transcripts)
+	if len(transcripts) == 0 {
+		t.Fatal("no transcripts in testdata")
+	}
+
+	initrd := os.Getenv("QEMU_INITRD")
+	if initrd == "" {
+		initrd = buildInitrd(t)
+	}
+
+	c := boot(t, qemu, kernel, initrd)
+	defer func() {
+		if t.Failed() {
+			t.Logf("console log:\n%s", clean(c.log()))
+		}
+	}()
+
+	if _, err := c.expect(loginPrompt, bootTimeout); err != nil {
+		t.Fatalf("boot: %v", err)
+	}
+	c.send("root")
+	if _, err := c.expect(shellPrompt, commandTimeout); err != nil {
+		t.Fatalf("login: %v", err)
+	}
+
+	for _, path := range transcripts {
+		name := strings.TrimSuffix(filepath.Base(path), ".txt")
+		t.Run(name, func(t *testing.T) {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			steps, err := parseTranscript(string(data))
+			if err != nil {
+				t.Fatalf("%s: %v", path, err)
+			}
+			for _, s := range steps {
+				c.send(s.cmd)
+				out, err := c.expect(shellPrompt, commandTimeout)
+				if err != nil {
+					t.Fatalf("%s:%d: $ %s: %v", path, s.line, s.cmd, err)
+				}
+				got := outputLines(out, s.cmd)
+				if !match(s.want, got) {
+					t.Errorf("%s:%d: $ %s\ngot:\n%s\nwant:\n%s", path, s.line, s.cmd,
+						strings.Join(got, "\n"), strings.Join(s.want, "\n"))
+				}
+			}
+		})
+	}
+}
+
+// buildInitrd builds the multi-call binary for x86-64 and an initramfs of
+// the linux/ tree with it installed in /bin, as make does.
+func buildInitrd(t *testing.T) string {
+	dir := t.TempDir()
+	coreutils := filepath.Join(dir, "coreutils")
+	mkinitramfs := filepath.Join(dir, "mkinitramfs")
+	goBuild(t, coreutils, "linux", "amd64")
+	goBuild(t, mkinitramfs, "", "")
+
+	out, err := exec.Command(coreutils, "--list").Output()
+	if err != nil {
+		t.Fatalf("coreutils --list: %v", err)
+	}
+	applets := strings.Fields(string(out))
+
+	possibilities := filepath.Join(dir, "possibilities")
+	if err := os.WriteFile(possibilities, []byte(strings.Join(applets, " ")+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	var list strings.Builder
+	fmt.Fprintf(&list, "file /bin/coreutils %s 0755 0 0\n", coreutils)
+	for _, name := range applets {
+		fmt.Fprintf(&list, "slink /bin/%s coreutils 0777 0 0\n", name)
+	}
+	fmt.Fprintf(&list, "file /usr/possibilities %s 0644 0 0\n", possibilities)
+	manifest := filepath.Join(dir, "bin.list")
+	if err := os.WriteFile(manifest, []byte(list.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	initrd := filepath.Join(dir, "initrd.img")
+	cmd := exec.Command(mkinitramfs, "-m", filepath.Join(root, "initramfs.list"), "-m", manifest,
+		"-o", initrd, filepath.Join(root, "linux"))
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("mkinitramfs: %v\n%s", err, out)
+	}
+	return initrd
+}
+
+// goBuild builds a command of this module; the multi-call binary when
+// goos is set, for that target, and mkinitramfs for the host otherwise.
+func goBuild(t *testing.T, out, goos, goarch string) {
+	pkg := "./cmd/mkinitramfs"
+	env := os.Environ()
+	if goos != "" {
+		pkg = "./cmd/coreutils"
+		env = append(env, "CGO_ENABLED=0", "GOOS="+goos, "GOARCH="+goarch)
+	}
+	cmd := exec.Command("go", "build", "-o", out, pkg)
+	cmd.Dir = root
+	cmd.Env = env
+	if msg, err := cmd.CombinedOutput(); err != nil {
+		if _, lerr := exec.LookPath("go"); lerr != nil {
+			t.Skip("QEMU suite needs the go tool")
+		}
+		t.Fatalf("go build %s: %v\n%s", pkg, err, msg)
+	}
+}
+
+// boot starts QEMU with the serial console on its standard input and
+// output, and kills it when the test ends.
+func boot(t *testing.T, qemu, kernel, initrd string) *console {
+	cmd := exec.Command(qemu, "-nographic", "-no-reboot", "-m", "512",
+		"-accel", getenv("QEMU_ACCEL", "tcg"),
+		"-kernel", kernel, "-initrd", initrd,
+		"-append", "console=ttyS0 panic=-1 quiet loglevel=3")
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmd.Stderr = cmd.Stdout
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("%s: %v", qemu, err)
+	}
+	t.Cleanup(func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	})
+
+	c := &console{in: stdin, more: make(chan struct{}, 1), done: make(chan struct{})}
+	go c.read(stdout)
+	return c
+}
+
+// console collects everything the serial line prints and hands it out a
+// prompt at a time.
+type console struct {
+	in   io.Writer
+	mu   sync.Mutex
+	buf  []byte
+	pos  int // start of the output not yet returned by expect
+	more chan struct{}
+	done chan struct{}
+}
+
+func (c *console) read(r io.Reader) {
+	b := make([]byte, 4096)
+	for {
+		n, err := r.Read(b)
+		c.mu.Lock()
+		c.buf = append(c.buf, b[:n]...)
+		c.mu.Unlock()
+		select {
+		case c.more <- struct{}{}:
+		default:
+		}
+		if err != nil {
+			close(c.done)
+			return
+		}
+	}
+}
+
+// send types line and presses return.
+func (c *console) send(line string) {
+	io.WriteString(c.in, line+"\r")
+}
+
+// expect waits until re matches the output not yet returned and returns
+// the output before the match.
+func (c *console) expect(re *regexp.Regexp, timeout time.Duration) (string, error) {
+	deadline := time.After(timeout)
+	for closed := false; ; {
+		c.mu.Lock()
+		if loc := re.FindIndex(c.buf[c.pos:]); loc != nil {
+			out := string(c.buf[c.pos : c.pos+loc[0]])
+			c.pos += loc[1]
+			c.mu.Unlock()
+			return out, nil
+		}
+		c.mu.Unlock()
+		if closed {
+			return "", fmt.Errorf("QEMU exited before %q appeared", re)
+		}
+
+		select {
+		case <-c.more:
+		case <-c.done:
+			closed = true
+		case <-deadline:
+			return "", fmt.Errorf("timed out waiting for %q", re)
+		}
+	}
+}
+
+func (c *console) log() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return string(c.buf)
+}
+
+var escapes = regexp.MustCompile(`\x1b(\[[0-9;?]*[ -/]*[@-~]|[()][0-9A-Za-z]|[=>78cDEHM])`)
+
+// clean strips terminal escape sequences and carriage returns.
+func clean(s string) string {
+	return strings.ReplaceAll(escapes.ReplaceAllString(s, ""), "\r", "")
+}
+
+// outputLines splits what a command printed into lines, without the
+// terminal's echo of the command itself.
+func outputLines(out, cmd string) []string {
+	lines := strings.Split(clean(out), "\n")
+	if len(lines) > 0 && strings.TrimSpace(lines[0]) == cmd {
+		lines = lines[1:]
+	}
+	if len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
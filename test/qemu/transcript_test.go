@@ -0,0 +1,119 @@
+package qemu
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// A transcript is a shell session as it should appear on the console:
+//
+//	# comment
+//	$ command
+//	expected output line
+//	~ regular expression matching one whole line
+//	...
+//
+// Every "$ " line is sent to gsh and the lines after it, up to the next
+// command, must be exactly what the command prints. A "..." line matches
+// any number of lines. Lines starting with # are comments.
+type step struct {
+	cmd  string
+	want []string
+	line int
+}
+
+func parseTranscript(data string) ([]step, error) {
+	var steps []step
+	for i, line := range strings.Split(strings.TrimSuffix(data, "\n"), "\n") {
+		switch {
+		case strings.HasPrefix(line, "#"):
+		case strings.HasPrefix(line, "$ "):
+			steps = append(steps, step{cmd: line[2:], line: i + 1})
+		case len(steps) == 0:
+			return nil, fmt.Errorf("line %d: output before the first command", i+1)
+		default:
+			if strings.HasPrefix(line, "~ ") {
+				if _, err := regexp.Compile(line[2:]); err != nil {
+					return nil, fmt.Errorf("line %d: %v", i+1, err)
+				}
+			}
+			s := &steps[len(steps)-1]
+			s.want = append(s.want, line)
+		}
+	}
+	return steps, nil
+}
+
+// match reports whether got is the output want describes.
+func match(want, got []string) bool {
+	if len(want) == 0 {
+		return len(got) == 0
+	}
+	if want[0] == "..." {
+		for i := 0; i <= len(got); i++ {
+			if match(want[1:], got[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(got) == 0 || !matchLine(want[0], got[0]) {
+		return false
+	}
+	return match(want[1:], got[1:])
+}
+
+func matchLine(want, got string) bool {
+	if strings.HasPrefix(want, "~ ") {
+		return regexp.MustCompile("^(?:" + want[2:] + ")$").MatchString(got)
+	}
+	return want == got
+}
+
+func TestTranscriptMatch(t *testing.T) {
+	steps, err := parseTranscript("# demo\n$ echo a\na\n$ ls\n...\n~ b.*\n$ true\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(steps) != 3 || steps[0].cmd != "echo a" || steps[1].line != 4 || len(steps[2].want) != 0 {
+		t.Fatalf("parsed %+v", steps)
+	}
+
+	tests := []struct {
+		want, got string
+		ok        bool
+	}{
+		{"a", "a", true},
+		{"a", "a|b", false},
+		{"...|~ b.*", "x|y|bin", true},
+		{"...|~ b.*", "bin|x", false},
+		{"...", "", true},
+		{"a|...|c", "a|c", true},
+		{"~ [0-9]+", "12x", false},
+	}
+	split := func(s string) []string {
+		if s == "" {
+			return nil
+		}
+		return strings.Split(s, "|")
+	}
+	for _, tt := range tests {
+		if ok := match(split(tt.want), split(tt.got)); ok != tt.ok {
+			t.Errorf("match(%q, %q) = %v", tt.want, tt.got, ok)
+		}
+	}
+
+	if _, err := parseTranscript("stray\n$ echo\n"); err == nil {
+		t.Error("output before the first command accepted")
+	}
+}
+
+func TestOutputLines(t *testing.T) {
+	out := "ls /\r\n\x1b[34mbin\x1b[0m  etc\r\n\r\n"
+	got := outputLines(out, "ls /")
+	if strings.Join(got, "|") != "bin  etc|" {
+		t.Errorf("got %q", got)
+	}
+}